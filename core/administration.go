@@ -19,7 +19,9 @@ package core
 
 import (
 	"errors"
+	"fmt"
 	"log"
+	"strings"
 	"talent-chooser/core/model"
 )
 
@@ -53,11 +55,15 @@ func (app *Application) getContentItem(dataVersion string, ID int) (*model.Conte
 	return contentItem, nil
 }
 
-func (app *Application) createContentItem(dataVersion string, name string) (*model.ContentItem, error) {
+func (app *Application) createContentItem(dataVersion string, name string, payload interface{}, payloadSchema map[string]interface{}) (*model.ContentItem, error) {
 	if len(name) == 0 {
 		return nil, errors.New("Name cannot be empty")
 	}
-	contentItem, err := app.storage.CreateContentItem(dataVersion, name)
+	err := model.ValidatePayloadSchema(payloadSchema)
+	if err != nil {
+		return nil, err
+	}
+	contentItem, err := app.storage.CreateContentItem(dataVersion, name, payload, payloadSchema)
 	if err != nil {
 		return nil, err
 	}
@@ -65,14 +71,27 @@ func (app *Application) createContentItem(dataVersion string, name string) (*mod
 	return contentItem, nil
 }
 
-func (app *Application) updateContentItem(dataVersion string, ID int, name string) (*model.ContentItem, error) {
+func (app *Application) updateContentItem(dataVersion string, ID int, name string, payload interface{}, payloadSchema map[string]interface{}) (*model.ContentItem, error) {
 	if ID <= 0 {
 		return nil, errors.New("The ID must be positive")
 	}
 	if len(name) == 0 {
 		return nil, errors.New("Name cannot be empty")
 	}
-	contentItem, err := app.storage.UpdateContentItem(dataVersion, ID, name)
+	err := model.ValidatePayloadSchema(payloadSchema)
+	if err != nil {
+		return nil, err
+	}
+	//the current ui items payloads must satisfy the new schema
+	current, err := app.storage.ReadContentItem(dataVersion, ID)
+	if err != nil {
+		return nil, err
+	}
+	err = model.ValidateUIItemsPayloads(payloadSchema, current.UIItems)
+	if err != nil {
+		return nil, err
+	}
+	contentItem, err := app.storage.UpdateContentItem(dataVersion, ID, name, payload, payloadSchema)
 	if err != nil {
 		return nil, err
 	}
@@ -102,11 +121,28 @@ func (app *Application) getUIItem(dataVersion string, contentItemID int, ID int)
 	return uiItem, nil
 }
 
-func (app *Application) createUIItem(dataVersion string, contentItemID int, name string, order int) (*model.UIItem, error) {
+func (app *Application) createUIItem(dataVersion string, contentItemID int, name string, order int, dataCategories []string, matchMode string, payload interface{}) (*model.UIItem, error) {
 	if contentItemID == 0 || len(name) == 0 || order == 0 {
 		return nil, errors.New("Bad params")
 	}
-	uiItem, err := app.storage.CreateUIItem(dataVersion, contentItemID, name, order)
+	err := app.privacyDataCategories.Validate(dataCategories)
+	if err != nil {
+		return nil, err
+	}
+	err = model.ValidateMatchMode(matchMode)
+	if err != nil {
+		return nil, err
+	}
+	//the payload must satisfy the schema of the content item which contains the ui item
+	contentItem, err := app.storage.ReadContentItem(dataVersion, contentItemID)
+	if err != nil {
+		return nil, err
+	}
+	err = model.ValidatePayload(contentItem.PayloadSchema, payload)
+	if err != nil {
+		return nil, err
+	}
+	uiItem, err := app.storage.CreateUIItem(dataVersion, contentItemID, name, order, dataCategories, matchMode, payload)
 	if err != nil {
 		return nil, err
 	}
@@ -114,14 +150,26 @@ func (app *Application) createUIItem(dataVersion string, contentItemID int, name
 	return uiItem, nil
 }
 
-func (app *Application) updateUIItem(dataVersion string, contentItemID int, ID int, name string, order int) (*model.UIItem, error) {
+func (app *Application) updateUIItem(dataVersion string, contentItemID int, ID int, name string, order int, dataCategories []string, matchMode string, payload interface{}) (*model.UIItem, error) {
 	if ID <= 0 {
 		return nil, errors.New("The ID must be positive")
 	}
 	if len(name) == 0 {
 		return nil, errors.New("Name cannot be empty")
 	}
-	uiItem, err := app.storage.UpdateUIItem(dataVersion, contentItemID, ID, name, order)
+	err := app.privacyDataCategories.Validate(dataCategories)
+	if err != nil {
+		return nil, err
+	}
+	err = model.ValidateMatchMode(matchMode)
+	if err != nil {
+		return nil, err
+	}
+	err = app.validateUIItemPayload(dataVersion, ID, payload)
+	if err != nil {
+		return nil, err
+	}
+	uiItem, err := app.storage.UpdateUIItem(dataVersion, contentItemID, ID, name, order, dataCategories, matchMode, payload)
 	if err != nil {
 		return nil, err
 	}
@@ -141,6 +189,124 @@ func (app *Application) deleteUIItem(dataVersion string, contentItemID int, ID i
 	return nil
 }
 
+func (app *Application) linkUIItem(dataVersion string, contentItemID int, ID int, order int) (*model.UIItem, error) {
+	if ID <= 0 || contentItemID <= 0 {
+		return nil, errors.New("The IDs must be positive")
+	}
+	if order == 0 {
+		return nil, errors.New("Bad params")
+	}
+	//the ui item and its children payloads must satisfy the schema of the content item it is linked to
+	contentItem, err := app.storage.ReadContentItem(dataVersion, contentItemID)
+	if err != nil {
+		return nil, err
+	}
+	uiItem, err := app.storage.ReadUIItem(dataVersion, contentItemID, ID)
+	if err != nil {
+		return nil, err
+	}
+	err = model.ValidateUIItemsPayloads(contentItem.PayloadSchema, []model.UIItem{*uiItem})
+	if err != nil {
+		return nil, err
+	}
+	return app.storage.LinkUIItem(dataVersion, contentItemID, ID, order)
+}
+
+func (app *Application) unlinkUIItem(dataVersion string, contentItemID int, ID int) error {
+	if ID <= 0 || contentItemID <= 0 {
+		return errors.New("The IDs must be positive")
+	}
+	return app.storage.UnlinkUIItem(dataVersion, contentItemID, ID)
+}
+
+//validateUIItemPayload validates the payload against the schemas of all content items which contain the ui item
+func (app *Application) validateUIItemPayload(dataVersion string, uiItemID int, payload interface{}) error {
+	contentItems, err := app.storage.ReadUIItemContentItems(dataVersion, uiItemID)
+	if err != nil {
+		return err
+	}
+	for _, contentItem := range contentItems {
+		err = model.ValidatePayload(contentItem.PayloadSchema, payload)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (app *Application) getChildUIItem(dataVersion string, parentID int, ID int) (*model.UIItem, error) {
+	//read it from the storage
+	uiItem, err := app.storage.ReadChildUIItem(dataVersion, parentID, ID)
+	if err != nil {
+		log.Printf("getChildUIItem -> Error reading a child ui item from the storage %s\n", err.Error())
+		return nil, err
+	}
+	return uiItem, nil
+}
+
+func (app *Application) createChildUIItem(dataVersion string, parentID int, name string, order int, dataCategories []string, matchMode string, payload interface{}) (*model.UIItem, error) {
+	if parentID == 0 || len(name) == 0 || order == 0 {
+		return nil, errors.New("Bad params")
+	}
+	err := app.privacyDataCategories.Validate(dataCategories)
+	if err != nil {
+		return nil, err
+	}
+	err = model.ValidateMatchMode(matchMode)
+	if err != nil {
+		return nil, err
+	}
+	err = app.validateUIItemPayload(dataVersion, parentID, payload)
+	if err != nil {
+		return nil, err
+	}
+	uiItem, err := app.storage.CreateChildUIItem(dataVersion, parentID, name, order, dataCategories, matchMode, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return uiItem, nil
+}
+
+func (app *Application) updateChildUIItem(dataVersion string, parentID int, ID int, name string, order int, dataCategories []string, matchMode string, payload interface{}) (*model.UIItem, error) {
+	if ID <= 0 {
+		return nil, errors.New("The ID must be positive")
+	}
+	if len(name) == 0 {
+		return nil, errors.New("Name cannot be empty")
+	}
+	err := app.privacyDataCategories.Validate(dataCategories)
+	if err != nil {
+		return nil, err
+	}
+	err = model.ValidateMatchMode(matchMode)
+	if err != nil {
+		return nil, err
+	}
+	err = app.validateUIItemPayload(dataVersion, parentID, payload)
+	if err != nil {
+		return nil, err
+	}
+	uiItem, err := app.storage.UpdateChildUIItem(dataVersion, parentID, ID, name, order, dataCategories, matchMode, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return uiItem, nil
+}
+
+func (app *Application) deleteChildUIItem(dataVersion string, parentID int, ID int) error {
+	if ID <= 0 || parentID <= 0 {
+		return errors.New("The IDs must be positive")
+	}
+	err := app.storage.DeleteChildUIItem(dataVersion, parentID, ID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (app *Application) getRule(dataVersion string, uiItemID int, ID int) (*model.Rule, error) {
 	//read it from the storage
 	rule, err := app.storage.ReadRule(dataVersion, uiItemID, ID)
@@ -151,15 +317,23 @@ func (app *Application) getRule(dataVersion string, uiItemID int, ID int) (*mode
 	return rule, nil
 }
 
-func (app *Application) createRule(dataVersion string, uiItemID int, ruleTypeID int, value interface{}) (*model.Rule, error) {
+func (app *Application) createRule(dataVersion string, uiItemID int, ruleTypeID int, value interface{}, onMissing string, negate bool) (*model.Rule, error) {
 	if uiItemID <= 0 {
 		return nil, errors.New("UI item id should be possitive")
 	}
 	if ruleTypeID <= 0 {
 		return nil, errors.New("Rule type id should be possitive")
 	}
+	err := app.validateRuleOnMissing(dataVersion, ruleTypeID, value, onMissing)
+	if err != nil {
+		return nil, err
+	}
+	err = app.validateRuleUUIDLists(dataVersion, ruleTypeID, value)
+	if err != nil {
+		return nil, err
+	}
 
-	rule, err := app.storage.CreateRule(dataVersion, uiItemID, ruleTypeID, value)
+	rule, err := app.storage.CreateRule(dataVersion, uiItemID, ruleTypeID, value, onMissing, negate)
 	if err != nil {
 		return nil, err
 	}
@@ -167,12 +341,20 @@ func (app *Application) createRule(dataVersion string, uiItemID int, ruleTypeID
 	return rule, nil
 }
 
-func (app *Application) updateRule(dataVersion string, ID int, uiItemID int, ruleTypeID int, value interface{}) (*model.Rule, error) {
+func (app *Application) updateRule(dataVersion string, ID int, uiItemID int, ruleTypeID int, value interface{}, onMissing string, negate bool) (*model.Rule, error) {
 	if ID <= 0 {
 		return nil, errors.New("The ID must be positive")
 	}
+	err := app.validateRuleOnMissing(dataVersion, ruleTypeID, value, onMissing)
+	if err != nil {
+		return nil, err
+	}
+	err = app.validateRuleUUIDLists(dataVersion, ruleTypeID, value)
+	if err != nil {
+		return nil, err
+	}
 
-	rule, err := app.storage.UpdateRule(dataVersion, ID, uiItemID, ruleTypeID, value)
+	rule, err := app.storage.UpdateRule(dataVersion, ID, uiItemID, ruleTypeID, value, onMissing, negate)
 	if err != nil {
 		return nil, err
 	}
@@ -192,6 +374,153 @@ func (app *Application) deleteRule(dataVersion string, uiItemD int, ID int) erro
 	return nil
 }
 
+func (app *Application) getNamedRules(dataVersion string) ([]model.Rule, error) {
+	//read them from the storage
+	rules, err := app.storage.ReadNamedRules(dataVersion)
+	if err != nil {
+		log.Printf("getNamedRules -> Error reading the named rules from the storage %s\n", err.Error())
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (app *Application) getNamedRule(dataVersion string, ID int) (*model.Rule, error) {
+	//read it from the storage
+	rule, err := app.storage.ReadNamedRule(dataVersion, ID)
+	if err != nil {
+		log.Printf("getNamedRule -> Error reading a named rule from the storage %s\n", err.Error())
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (app *Application) createNamedRule(dataVersion string, name string, ruleTypeID int, value interface{}, onMissing string, negate bool) (*model.Rule, error) {
+	if len(strings.TrimSpace(name)) == 0 {
+		return nil, errors.New("Name is required")
+	}
+	if ruleTypeID <= 0 {
+		return nil, errors.New("Rule type id should be possitive")
+	}
+	err := app.validateRuleOnMissing(dataVersion, ruleTypeID, value, onMissing)
+	if err != nil {
+		return nil, err
+	}
+	err = app.validateRuleUUIDLists(dataVersion, ruleTypeID, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return app.storage.CreateNamedRule(dataVersion, strings.TrimSpace(name), ruleTypeID, value, onMissing, negate)
+}
+
+func (app *Application) updateNamedRule(dataVersion string, ID int, name string, ruleTypeID int, value interface{}, onMissing string, negate bool) (*model.Rule, error) {
+	if ID <= 0 {
+		return nil, errors.New("The ID must be positive")
+	}
+	if len(strings.TrimSpace(name)) == 0 {
+		return nil, errors.New("Name is required")
+	}
+	if ruleTypeID <= 0 {
+		return nil, errors.New("Rule type id should be possitive")
+	}
+	err := app.validateRuleOnMissing(dataVersion, ruleTypeID, value, onMissing)
+	if err != nil {
+		return nil, err
+	}
+	err = app.validateRuleUUIDLists(dataVersion, ruleTypeID, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return app.storage.UpdateNamedRule(dataVersion, ID, strings.TrimSpace(name), ruleTypeID, value, onMissing, negate)
+}
+
+func (app *Application) deleteNamedRule(dataVersion string, ID int) error {
+	if ID <= 0 {
+		return errors.New("The ID must be positive")
+	}
+	return app.storage.DeleteNamedRule(dataVersion, ID)
+}
+
+func (app *Application) getRuleUsage(dataVersion string, ID int) ([]model.UIItem, error) {
+	if ID <= 0 {
+		return nil, errors.New("The ID must be positive")
+	}
+	return app.storage.ReadRuleUsage(dataVersion, ID)
+}
+
+func (app *Application) attachRule(dataVersion string, uiItemID int, ID int) (*model.Rule, error) {
+	if ID <= 0 || uiItemID <= 0 {
+		return nil, errors.New("The IDs must be positive")
+	}
+	return app.storage.AttachRule(dataVersion, uiItemID, ID)
+}
+
+func (app *Application) detachRule(dataVersion string, uiItemID int, ID int) error {
+	if ID <= 0 || uiItemID <= 0 {
+		return errors.New("The IDs must be positive")
+	}
+	return app.storage.DetachRule(dataVersion, uiItemID, ID)
+}
+
+//validateRuleOnMissing checks the on missing policy of the rule and that it is not combined with a value field which
+//gives the result for the missing input
+func (app *Application) validateRuleOnMissing(dataVersion string, ruleTypeID int, value interface{}, onMissing string) error {
+	err := model.ValidateOnMissing(onMissing)
+	if err != nil || onMissing == model.OnMissingDefault {
+		return err
+	}
+
+	ruleTypes, err := app.storage.ReadRuleTypes(dataVersion)
+	if err != nil {
+		return err
+	}
+	for _, ruleType := range ruleTypes {
+		if ruleType.GetID() == ruleTypeID {
+			return model.ValidateOnMissingFallback(ruleType.GetName(), value, onMissing)
+		}
+	}
+	return nil
+}
+
+//validateRuleUUIDLists checks that the uuid lists which the rule value references exist in the data version
+//as a rule with a not existing list never matches
+func (app *Application) validateRuleUUIDLists(dataVersion string, ruleTypeID int, value interface{}) error {
+	ruleTypes, err := app.storage.ReadRuleTypes(dataVersion)
+	if err != nil {
+		return err
+	}
+	var listNames []string
+	for _, ruleType := range ruleTypes {
+		if ruleType.GetID() == ruleTypeID {
+			listNames = model.ReferencedUUIDLists(ruleType.GetName(), value)
+			break
+		}
+	}
+	if len(listNames) == 0 {
+		return nil
+	}
+
+	uuidLists, err := app.storage.ReadUUIDLists(dataVersion)
+	if err != nil {
+		return err
+	}
+	existing := map[string]bool{}
+	for _, uuidList := range uuidLists {
+		existing[uuidList.Name] = true
+	}
+	var errs model.ValidationErrors
+	for _, name := range listNames {
+		if !existing[name] {
+			errs = append(errs, model.ValidationError{Field: "value", Message: fmt.Sprintf("there is no uuid list %s", name)})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
 func (app *Application) getRuleTypes(dataVersion string) ([]model.RuleType, error) {
 	//read it from the storage
 	ruleTypes, err := app.storage.ReadRuleTypes(dataVersion)
@@ -201,3 +530,112 @@ func (app *Application) getRuleTypes(dataVersion string) ([]model.RuleType, erro
 	}
 	return ruleTypes, nil
 }
+
+func (app *Application) getUUIDLists(dataVersion string) ([]model.UUIDList, error) {
+	//read it from the storage
+	uuidLists, err := app.storage.ReadUUIDLists(dataVersion)
+	if err != nil {
+		log.Printf("getUUIDLists -> Error reading the uuid lists from the storage %s\n", err.Error())
+		return nil, err
+	}
+	return uuidLists, nil
+}
+
+func (app *Application) getUUIDList(dataVersion string, ID int) (*model.UUIDList, error) {
+	//read it from the storage
+	uuidList, err := app.storage.ReadUUIDList(dataVersion, ID)
+	if err != nil {
+		log.Printf("getUUIDList -> Error reading an uuid list from the storage %s\n", err.Error())
+		return nil, err
+	}
+	return uuidList, nil
+}
+
+func (app *Application) createUUIDList(dataVersion string, name string, uuids []string) (*model.UUIDList, error) {
+	uuidList := model.UUIDList{Name: name, UUIDs: uuids}
+	err := uuidList.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return app.storage.CreateUUIDList(dataVersion, name, app.uniqueUUIDs(uuids))
+}
+
+func (app *Application) updateUUIDList(dataVersion string, ID int, name string, uuids []string) (*model.UUIDList, error) {
+	if ID <= 0 {
+		return nil, errors.New("The ID must be positive")
+	}
+	uuidList := model.UUIDList{ID: ID, Name: name, UUIDs: uuids}
+	err := uuidList.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return app.storage.UpdateUUIDList(dataVersion, ID, name, app.uniqueUUIDs(uuids))
+}
+
+func (app *Application) deleteUUIDList(dataVersion string, ID int) error {
+	if ID <= 0 {
+		return errors.New("The ID must be positive")
+	}
+	return app.storage.DeleteUUIDList(dataVersion, ID)
+}
+
+//uniqueUUIDs removes the duplicated uuids and keeps the order
+func (app *Application) uniqueUUIDs(uuids []string) []string {
+	result := make([]string, 0, len(uuids))
+	added := make(map[string]bool, len(uuids))
+	for _, uuid := range uuids {
+		if !added[uuid] {
+			added[uuid] = true
+			result = append(result, uuid)
+		}
+	}
+	return result
+}
+
+func (app *Application) getUIItemsStrings(dataVersion string) ([]model.UIItemStrings, error) {
+	//read it from the storage
+	tables, err := app.storage.ReadUIItemsStrings(dataVersion)
+	if err != nil {
+		log.Printf("getUIItemsStrings -> Error reading the ui items strings from the storage %s\n", err.Error())
+		return nil, err
+	}
+	return tables, nil
+}
+
+func (app *Application) updateUIItemsStrings(dataVersion string, tables []model.UIItemStrings) error {
+	var errs model.ValidationErrors
+	for index := range tables {
+		tables[index].Locale = model.NormalizeLocale(tables[index].Locale)
+		err := tables[index].Validate()
+		if err == nil {
+			continue
+		}
+		validationErrors, ok := err.(model.ValidationErrors)
+		if !ok {
+			return err
+		}
+		for _, item := range validationErrors {
+			errs = append(errs, model.ValidationError{Field: fmt.Sprintf("[%d].%s", index, item.Field), Message: item.Message})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return app.storage.UpdateUIItemsStrings(dataVersion, tables)
+}
+
+func (app *Application) getMissingUIItemsStrings(dataVersion string, locales []string) (map[string][]model.MissingStrings, error) {
+	tables, err := app.storage.ReadUIItemsStrings(dataVersion)
+	if err != nil {
+		log.Printf("getMissingUIItemsStrings -> Error reading the ui items strings from the storage %s\n", err.Error())
+		return nil, err
+	}
+	if len(locales) == 0 {
+		//the default locale is always reported
+		locales = []string{app.defaultLocale}
+		for _, table := range tables {
+			locales = append(locales, table.Locale)
+		}
+	}
+	return model.FindMissingStrings(tables, locales), nil
+}
@@ -0,0 +1,131 @@
+/*
+ *   Copyright (c) 2020 Board of Trustees of the University of Illinois.
+ *   All rights reserved.
+
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+
+ *   http://www.apache.org/licenses/LICENSE-2.0
+
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"testing"
+
+	"talent-chooser/core/model"
+)
+
+//testStorage implements only the storage operations which the tests use
+type testStorage struct {
+	Storage
+
+	ruleTypes    []model.RuleType
+	uuidLists    []model.UUIDList
+	createdRules int
+}
+
+func (s *testStorage) ReadRuleTypes(dataVersion string) ([]model.RuleType, error) {
+	return s.ruleTypes, nil
+}
+
+func (s *testStorage) ReadUUIDLists(dataVersion string) ([]model.UUIDList, error) {
+	return s.uuidLists, nil
+}
+
+func (s *testStorage) CreateRule(dataVersion string, uiItemID int, ruleTypeID int, value interface{}, onMissing string, negate bool) (*model.Rule, error) {
+	s.createdRules++
+	return &model.Rule{ID: s.createdRules, Value: value}, nil
+}
+
+func (s *testStorage) CreateNamedRule(dataVersion string, name string, ruleTypeID int, value interface{}, onMissing string, negate bool) (*model.Rule, error) {
+	s.createdRules++
+	return &model.Rule{ID: s.createdRules, Name: name, Value: value}, nil
+}
+
+func newTestStorage() *testStorage {
+	uuidListType, _ := model.NewRuleType(1, "uuid_list")
+	compositeType, _ := model.NewRuleType(2, "composite")
+	authType, _ := model.NewRuleType(3, "auth")
+	return &testStorage{ruleTypes: []model.RuleType{uuidListType, compositeType, authType},
+		uuidLists: []model.UUIDList{{ID: 1, Name: "pilot", UUIDs: []string{"uuid-1"}}}}
+}
+
+func TestCreateRuleReferencingUUIDLists(t *testing.T) {
+	storage := newTestStorage()
+	app := &Application{storage: storage}
+
+	cases := []struct {
+		ruleTypeID int
+		value      interface{}
+		valid      bool
+	}{
+		{1, map[string]interface{}{"list": "pilot"}, true},
+		{1, map[string]interface{}{"list": "missing"}, false},
+		{2, map[string]interface{}{"or": []interface{}{
+			map[string]interface{}{"uuid_list": map[string]interface{}{"list": "pilot"}},
+			map[string]interface{}{"uuid_list": map[string]interface{}{"list": "missing"}}}}, false},
+		{3, map[string]interface{}{"shibbolethLoggedIn": true}, true},
+	}
+	for _, item := range cases {
+		_, err := app.createRule("1.0", 1, item.ruleTypeID, item.value, "", false)
+		if item.valid && err != nil {
+			t.Errorf("%v should be valid - %s", item.value, err.Error())
+		}
+		if !item.valid {
+			validationErrors, ok := err.(model.ValidationErrors)
+			if !ok || len(validationErrors) != 1 || validationErrors[0].Message != "there is no uuid list missing" {
+				t.Errorf("%v should not be valid - %v", item.value, err)
+			}
+		}
+
+		_, err = app.createNamedRule("1.0", "named", item.ruleTypeID, item.value, "", false)
+		if item.valid != (err == nil) {
+			t.Errorf("Wrong named rule validation for %v - %v", item.value, err)
+		}
+	}
+	if storage.createdRules != 4 {
+		t.Errorf("Only the valid rules should be created but %d were created", storage.createdRules)
+	}
+}
+
+func TestCreateRuleOnMissingFallback(t *testing.T) {
+	storage := newTestStorage()
+	rolloutType, _ := model.NewRuleType(4, "rollout")
+	storage.ruleTypes = append(storage.ruleTypes, rolloutType)
+	app := &Application{storage: storage}
+
+	rollout := map[string]interface{}{"percentage": 25, "salt": "pilot", "fallback": true}
+	composite := map[string]interface{}{"not": map[string]interface{}{"rollout": rollout}}
+
+	cases := []struct {
+		ruleTypeID int
+		value      interface{}
+		onMissing  string
+		valid      bool
+	}{
+		{4, rollout, "", true},
+		{4, rollout, model.OnMissingMatch, false},
+		{4, map[string]interface{}{"percentage": 25, "salt": "pilot"}, model.OnMissingMatch, true},
+		{2, composite, "", true},
+		{2, composite, model.OnMissingError, false},
+		{3, map[string]interface{}{"shibbolethLoggedIn": true}, model.OnMissingNoMatch, true},
+	}
+	for _, item := range cases {
+		_, err := app.createRule("1.0", 1, item.ruleTypeID, item.value, item.onMissing, false)
+		if item.valid != (err == nil) {
+			t.Errorf("Wrong validation for %v with %s - %v", item.value, item.onMissing, err)
+		}
+		_, err = app.createNamedRule("1.0", "named", item.ruleTypeID, item.value, item.onMissing, false)
+		if item.valid != (err == nil) {
+			t.Errorf("Wrong named rule validation for %v with %s - %v", item.value, item.onMissing, err)
+		}
+	}
+}
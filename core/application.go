@@ -33,19 +33,27 @@ type Application struct {
 
 	storage Storage
 
+	groupAliases          model.GroupAliases
+	privacyDataCategories model.PrivacyDataCategories
+	localeFallbacks       model.LocaleFallbacks
+	defaultLocale         string
+
 	//data cache
 	dataLock       *sync.RWMutex
-	data           map[string]*model.UIContent // version - data
-	dataStatusLock *sync.RWMutex
+	data           map[string]*model.UIContent         // version - data
+	compiledData   map[string]*model.CompiledUIContent // version - compiled data, used for evaluating the rules
+	dataStatusLock *sync.Mutex
+	dataStatusCond *sync.Cond //signaled when the data becomes ready
 	dataStatus     bool
 }
 
-//Start starts the core part of the application
-func (app *Application) Start() {
+//Start starts the core part of the application. It fails if the stored data cannot be loaded,
+//for example when it references a rule type which is not registered.
+func (app *Application) Start() error {
 	storageListener := storageListenerImpl{app: app}
 	app.storage.SetStorageListener(&storageListener)
 
-	app.loadData()
+	return app.loadData()
 }
 
 func (app *Application) loadData() error {
@@ -60,7 +68,11 @@ func (app *Application) loadData() error {
 		app.setDataStatus(true)
 		return err
 	}
-	app.setData(data)
+	compiledData := make(map[string]*model.CompiledUIContent, len(data))
+	for version, uiContent := range data {
+		compiledData[version] = model.CompileUIContent(uiContent, app.privacyDataCategories)
+	}
+	app.setData(data, compiledData)
 	app.setDataStatus(true)
 
 	log.Println("Successfully loaded data")
@@ -68,19 +80,18 @@ func (app *Application) loadData() error {
 	return nil
 }
 
-func (app *Application) setData(data map[string]*model.UIContent) {
-	app.dataLock.RLock()
+func (app *Application) setData(data map[string]*model.UIContent, compiledData map[string]*model.CompiledUIContent) {
+	app.dataLock.Lock()
 	app.data = data
+	app.compiledData = compiledData
 
 	log.Println("Set data...")
 
-	app.dataLock.RUnlock()
+	app.dataLock.Unlock()
 }
 
 func (app *Application) getData() map[string]*model.UIContent {
-	//wait until the data is ready
-	for !app.getDataStatus() {
-	}
+	app.waitDataStatus()
 
 	app.dataLock.RLock()
 	defer app.dataLock.RUnlock()
@@ -88,26 +99,47 @@ func (app *Application) getData() map[string]*model.UIContent {
 	return app.data
 }
 
+func (app *Application) getCompiledData(dataVersion string) *model.CompiledUIContent {
+	app.waitDataStatus()
+
+	app.dataLock.RLock()
+	defer app.dataLock.RUnlock()
+
+	return app.compiledData[dataVersion]
+}
+
 func (app *Application) setDataStatus(status bool) {
-	app.dataStatusLock.RLock()
+	app.dataStatusLock.Lock()
 	app.dataStatus = status
-	app.dataStatusLock.RUnlock()
+	app.dataStatusLock.Unlock()
+
+	if status {
+		app.dataStatusCond.Broadcast()
+	}
 }
 
-func (app *Application) getDataStatus() bool {
-	app.dataStatusLock.RLock()
-	defer app.dataStatusLock.RUnlock()
+//waitDataStatus blocks until the data is ready
+func (app *Application) waitDataStatus() {
+	app.dataStatusLock.Lock()
+	defer app.dataStatusLock.Unlock()
 
-	return app.dataStatus
+	for !app.dataStatus {
+		app.dataStatusCond.Wait()
+	}
 }
 
 //NewApplication creates new Application
-func NewApplication(version string, build string, storage Storage) *Application {
+func NewApplication(version string, build string, storage Storage, groupAliases model.GroupAliases,
+	privacyDataCategories model.PrivacyDataCategories, localeFallbacks model.LocaleFallbacks, defaultLocale string) *Application {
 	dataLock := &sync.RWMutex{}
-	dataStatusLock := &sync.RWMutex{}
+	dataStatusLock := &sync.Mutex{}
+	dataStatusCond := sync.NewCond(dataStatusLock)
 	data := map[string]*model.UIContent{}
+	compiledData := map[string]*model.CompiledUIContent{}
 	application := Application{version: version, build: build, storage: storage,
-		dataLock: dataLock, dataStatusLock: dataStatusLock, data: data}
+		groupAliases: groupAliases, privacyDataCategories: privacyDataCategories,
+		localeFallbacks: localeFallbacks, defaultLocale: defaultLocale,
+		dataLock: dataLock, dataStatusLock: dataStatusLock, dataStatusCond: dataStatusCond, data: data, compiledData: compiledData}
 
 	//add the drivers ports/interfaces
 	application.Services = &servicesImpl{app: &application}
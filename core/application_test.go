@@ -0,0 +1,53 @@
+/*
+ *   Copyright (c) 2020 Board of Trustees of the University of Illinois.
+ *   All rights reserved.
+
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+
+ *   http://www.apache.org/licenses/LICENSE-2.0
+
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"talent-chooser/core/model"
+)
+
+func TestGetCompiledDataWaitsForData(t *testing.T) {
+	app := NewApplication("1.0", "1", newTestStorage(), nil, nil, nil, "en")
+
+	result := make(chan *model.CompiledUIContent)
+	go func() {
+		result <- app.getCompiledData("1.0")
+	}()
+
+	select {
+	case <-result:
+		t.Fatal("The compiled data must not be given before the data is ready")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	compiledData := &model.CompiledUIContent{}
+	app.setData(nil, map[string]*model.CompiledUIContent{"1.0": compiledData})
+	app.setDataStatus(true)
+
+	select {
+	case value := <-result:
+		if value != compiledData {
+			t.Errorf("Wrong compiled data %v", value)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("The compiled data was not given after the data became ready")
+	}
+}
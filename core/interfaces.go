@@ -19,6 +19,7 @@ package core
 
 import (
 	"log"
+	"net"
 	"talent-chooser/core/model"
 )
 
@@ -27,7 +28,10 @@ type Services interface {
 	GetVersion() string
 	GetUIContent(user *model.User, dataVersion string, auth *model.Auth, illiniCash *model.IlliniCash) map[string][]string
 	GetUIContentV2(user *model.User, dataVersion string, auth *model.AuthV2, illiniCash *model.IlliniCash) map[string][]string
-	GetUIContentV3(user *model.User, dataVersion string, auth *model.AuthV3, illiniCash *model.IlliniCash, platform *model.Platform) map[string][]string
+	GetUIContentV3(user *model.User, dataVersion string, auth *model.AuthV3, illiniCash *model.IlliniCash, platform *model.Platform, attributes model.Attributes, clientIP net.IP, headers model.Headers, location *model.Location) map[string][]string
+	GetUIContentV4(user *model.User, dataVersion string, auth *model.AuthV3, illiniCash *model.IlliniCash, platform *model.Platform, attributes model.Attributes, clientIP net.IP, headers model.Headers, location *model.Location) map[string][]model.UIContentNode
+	GetUIContentV5(user *model.User, dataVersion string, auth *model.AuthV3, illiniCash *model.IlliniCash, platform *model.Platform, attributes model.Attributes, clientIP net.IP, headers model.Headers, location *model.Location, locales []string) map[string]model.ContentItemPayloadNode
+	ExplainUIContentV3(user *model.User, dataVersion string, auth *model.AuthV3, illiniCash *model.IlliniCash, platform *model.Platform, attributes model.Attributes, clientIP net.IP, headers model.Headers, location *model.Location) map[string][]model.UIItemTrace
 }
 
 type servicesImpl struct {
@@ -46,8 +50,20 @@ func (s *servicesImpl) GetUIContentV2(user *model.User, dataVersion string, auth
 	return s.app.getUIContentV2(user, dataVersion, auth, illiniCash)
 }
 
-func (s *servicesImpl) GetUIContentV3(user *model.User, dataVersion string, auth *model.AuthV3, illiniCash *model.IlliniCash, platform *model.Platform) map[string][]string {
-	return s.app.getUIContentV3(user, dataVersion, auth, illiniCash, platform)
+func (s *servicesImpl) GetUIContentV3(user *model.User, dataVersion string, auth *model.AuthV3, illiniCash *model.IlliniCash, platform *model.Platform, attributes model.Attributes, clientIP net.IP, headers model.Headers, location *model.Location) map[string][]string {
+	return s.app.getUIContentV3(user, dataVersion, auth, illiniCash, platform, attributes, clientIP, headers, location)
+}
+
+func (s *servicesImpl) GetUIContentV4(user *model.User, dataVersion string, auth *model.AuthV3, illiniCash *model.IlliniCash, platform *model.Platform, attributes model.Attributes, clientIP net.IP, headers model.Headers, location *model.Location) map[string][]model.UIContentNode {
+	return s.app.getUIContentV4(user, dataVersion, auth, illiniCash, platform, attributes, clientIP, headers, location)
+}
+
+func (s *servicesImpl) GetUIContentV5(user *model.User, dataVersion string, auth *model.AuthV3, illiniCash *model.IlliniCash, platform *model.Platform, attributes model.Attributes, clientIP net.IP, headers model.Headers, location *model.Location, locales []string) map[string]model.ContentItemPayloadNode {
+	return s.app.getUIContentV5(user, dataVersion, auth, illiniCash, platform, attributes, clientIP, headers, location, locales)
+}
+
+func (s *servicesImpl) ExplainUIContentV3(user *model.User, dataVersion string, auth *model.AuthV3, illiniCash *model.IlliniCash, platform *model.Platform, attributes model.Attributes, clientIP net.IP, headers model.Headers, location *model.Location) map[string][]model.UIItemTrace {
+	return s.app.explainUIContentV3(user, dataVersion, auth, illiniCash, platform, attributes, clientIP, headers, location)
 }
 
 //Administration exposes administration APIs for the driver adapters
@@ -58,21 +74,47 @@ type Administration interface {
 
 	GetContentItems(dataVersion string) ([]model.ContentItem, error)
 	GetContentItem(dataVersion string, ID int) (*model.ContentItem, error)
-	CreateContentItem(dataVersion string, name string) (*model.ContentItem, error)
-	UpdateContentItem(dataVersion string, ID int, name string) (*model.ContentItem, error)
+	CreateContentItem(dataVersion string, name string, payload interface{}, payloadSchema map[string]interface{}) (*model.ContentItem, error)
+	UpdateContentItem(dataVersion string, ID int, name string, payload interface{}, payloadSchema map[string]interface{}) (*model.ContentItem, error)
 	DeleteContentItem(dataVersion string, ID int) error
 
 	GetUIItem(dataVersion string, contentItemID int, ID int) (*model.UIItem, error)
-	CreateUIItem(dataVersion string, contentItemID int, name string, order int) (*model.UIItem, error)
-	UpdateUIItem(dataVersion string, contentItemID int, ID int, name string, order int) (*model.UIItem, error)
+	CreateUIItem(dataVersion string, contentItemID int, name string, order int, dataCategories []string, matchMode string, payload interface{}) (*model.UIItem, error)
+	UpdateUIItem(dataVersion string, contentItemID int, ID int, name string, order int, dataCategories []string, matchMode string, payload interface{}) (*model.UIItem, error)
 	DeleteUIItem(dataVersion string, contentItemID int, ID int) error
+	LinkUIItem(dataVersion string, contentItemID int, ID int, order int) (*model.UIItem, error)
+	UnlinkUIItem(dataVersion string, contentItemID int, ID int) error
+
+	GetChildUIItem(dataVersion string, parentID int, ID int) (*model.UIItem, error)
+	CreateChildUIItem(dataVersion string, parentID int, name string, order int, dataCategories []string, matchMode string, payload interface{}) (*model.UIItem, error)
+	UpdateChildUIItem(dataVersion string, parentID int, ID int, name string, order int, dataCategories []string, matchMode string, payload interface{}) (*model.UIItem, error)
+	DeleteChildUIItem(dataVersion string, parentID int, ID int) error
 
 	GetRule(dataVersion string, uiItemID int, ID int) (*model.Rule, error)
-	CreateRule(dataVersion string, uiItemID int, ruleTypeID int, value interface{}) (*model.Rule, error)
-	UpdateRule(dataVersion string, ID int, uiItemID int, ruleTypeID int, value interface{}) (*model.Rule, error)
+	CreateRule(dataVersion string, uiItemID int, ruleTypeID int, value interface{}, onMissing string, negate bool) (*model.Rule, error)
+	UpdateRule(dataVersion string, ID int, uiItemID int, ruleTypeID int, value interface{}, onMissing string, negate bool) (*model.Rule, error)
 	DeleteRule(dataVersion string, uiItemID int, ID int) error
 
+	GetNamedRules(dataVersion string) ([]model.Rule, error)
+	GetNamedRule(dataVersion string, ID int) (*model.Rule, error)
+	CreateNamedRule(dataVersion string, name string, ruleTypeID int, value interface{}, onMissing string, negate bool) (*model.Rule, error)
+	UpdateNamedRule(dataVersion string, ID int, name string, ruleTypeID int, value interface{}, onMissing string, negate bool) (*model.Rule, error)
+	DeleteNamedRule(dataVersion string, ID int) error
+	GetRuleUsage(dataVersion string, ID int) ([]model.UIItem, error)
+	AttachRule(dataVersion string, uiItemID int, ID int) (*model.Rule, error)
+	DetachRule(dataVersion string, uiItemID int, ID int) error
+
 	GetRuleTypes(dataVersion string) ([]model.RuleType, error)
+
+	GetUUIDLists(dataVersion string) ([]model.UUIDList, error)
+	GetUUIDList(dataVersion string, ID int) (*model.UUIDList, error)
+	CreateUUIDList(dataVersion string, name string, uuids []string) (*model.UUIDList, error)
+	UpdateUUIDList(dataVersion string, ID int, name string, uuids []string) (*model.UUIDList, error)
+	DeleteUUIDList(dataVersion string, ID int) error
+
+	GetUIItemsStrings(dataVersion string) ([]model.UIItemStrings, error)
+	UpdateUIItemsStrings(dataVersion string, tables []model.UIItemStrings) error
+	GetMissingUIItemsStrings(dataVersion string, locales []string) (map[string][]model.MissingStrings, error)
 }
 
 type administrationImpl struct {
@@ -99,12 +141,12 @@ func (a *administrationImpl) GetContentItem(dataVersion string, ID int) (*model.
 	return a.app.getContentItem(dataVersion, ID)
 }
 
-func (a *administrationImpl) CreateContentItem(dataVersion string, name string) (*model.ContentItem, error) {
-	return a.app.createContentItem(dataVersion, name)
+func (a *administrationImpl) CreateContentItem(dataVersion string, name string, payload interface{}, payloadSchema map[string]interface{}) (*model.ContentItem, error) {
+	return a.app.createContentItem(dataVersion, name, payload, payloadSchema)
 }
 
-func (a *administrationImpl) UpdateContentItem(dataVersion string, ID int, name string) (*model.ContentItem, error) {
-	return a.app.updateContentItem(dataVersion, ID, name)
+func (a *administrationImpl) UpdateContentItem(dataVersion string, ID int, name string, payload interface{}, payloadSchema map[string]interface{}) (*model.ContentItem, error) {
+	return a.app.updateContentItem(dataVersion, ID, name, payload, payloadSchema)
 }
 
 func (a *administrationImpl) DeleteContentItem(dataVersion string, ID int) error {
@@ -115,38 +157,126 @@ func (a *administrationImpl) GetUIItem(dataVersion string, contentItemID int, ID
 	return a.app.getUIItem(dataVersion, contentItemID, ID)
 }
 
-func (a *administrationImpl) CreateUIItem(dataVersion string, contentItemID int, name string, order int) (*model.UIItem, error) {
-	return a.app.createUIItem(dataVersion, contentItemID, name, order)
+func (a *administrationImpl) CreateUIItem(dataVersion string, contentItemID int, name string, order int, dataCategories []string, matchMode string, payload interface{}) (*model.UIItem, error) {
+	return a.app.createUIItem(dataVersion, contentItemID, name, order, dataCategories, matchMode, payload)
 }
 
-func (a *administrationImpl) UpdateUIItem(dataVersion string, contentItemID int, ID int, name string, order int) (*model.UIItem, error) {
-	return a.app.updateUIItem(dataVersion, contentItemID, ID, name, order)
+func (a *administrationImpl) UpdateUIItem(dataVersion string, contentItemID int, ID int, name string, order int, dataCategories []string, matchMode string, payload interface{}) (*model.UIItem, error) {
+	return a.app.updateUIItem(dataVersion, contentItemID, ID, name, order, dataCategories, matchMode, payload)
 }
 
 func (a *administrationImpl) DeleteUIItem(dataVersion string, contentItemID int, ID int) error {
 	return a.app.deleteUIItem(dataVersion, contentItemID, ID)
 }
 
+func (a *administrationImpl) LinkUIItem(dataVersion string, contentItemID int, ID int, order int) (*model.UIItem, error) {
+	return a.app.linkUIItem(dataVersion, contentItemID, ID, order)
+}
+
+func (a *administrationImpl) UnlinkUIItem(dataVersion string, contentItemID int, ID int) error {
+	return a.app.unlinkUIItem(dataVersion, contentItemID, ID)
+}
+
+func (a *administrationImpl) GetChildUIItem(dataVersion string, parentID int, ID int) (*model.UIItem, error) {
+	return a.app.getChildUIItem(dataVersion, parentID, ID)
+}
+
+func (a *administrationImpl) CreateChildUIItem(dataVersion string, parentID int, name string, order int, dataCategories []string, matchMode string, payload interface{}) (*model.UIItem, error) {
+	return a.app.createChildUIItem(dataVersion, parentID, name, order, dataCategories, matchMode, payload)
+}
+
+func (a *administrationImpl) UpdateChildUIItem(dataVersion string, parentID int, ID int, name string, order int, dataCategories []string, matchMode string, payload interface{}) (*model.UIItem, error) {
+	return a.app.updateChildUIItem(dataVersion, parentID, ID, name, order, dataCategories, matchMode, payload)
+}
+
+func (a *administrationImpl) DeleteChildUIItem(dataVersion string, parentID int, ID int) error {
+	return a.app.deleteChildUIItem(dataVersion, parentID, ID)
+}
+
 func (a *administrationImpl) GetRule(dataVersion string, uiItemID int, ID int) (*model.Rule, error) {
 	return a.app.getRule(dataVersion, uiItemID, ID)
 }
 
-func (a *administrationImpl) CreateRule(dataVersion string, uiItemID int, ruleTypeID int, value interface{}) (*model.Rule, error) {
-	return a.app.createRule(dataVersion, uiItemID, ruleTypeID, value)
+func (a *administrationImpl) CreateRule(dataVersion string, uiItemID int, ruleTypeID int, value interface{}, onMissing string, negate bool) (*model.Rule, error) {
+	return a.app.createRule(dataVersion, uiItemID, ruleTypeID, value, onMissing, negate)
 }
 
-func (a *administrationImpl) UpdateRule(dataVersion string, ID int, uiItemID int, ruleTypeID int, value interface{}) (*model.Rule, error) {
-	return a.app.updateRule(dataVersion, ID, uiItemID, ruleTypeID, value)
+func (a *administrationImpl) UpdateRule(dataVersion string, ID int, uiItemID int, ruleTypeID int, value interface{}, onMissing string, negate bool) (*model.Rule, error) {
+	return a.app.updateRule(dataVersion, ID, uiItemID, ruleTypeID, value, onMissing, negate)
 }
 
 func (a *administrationImpl) DeleteRule(dataVersion string, uiItemID int, ID int) error {
 	return a.app.deleteRule(dataVersion, uiItemID, ID)
 }
 
+func (a *administrationImpl) GetNamedRules(dataVersion string) ([]model.Rule, error) {
+	return a.app.getNamedRules(dataVersion)
+}
+
+func (a *administrationImpl) GetNamedRule(dataVersion string, ID int) (*model.Rule, error) {
+	return a.app.getNamedRule(dataVersion, ID)
+}
+
+func (a *administrationImpl) CreateNamedRule(dataVersion string, name string, ruleTypeID int, value interface{}, onMissing string, negate bool) (*model.Rule, error) {
+	return a.app.createNamedRule(dataVersion, name, ruleTypeID, value, onMissing, negate)
+}
+
+func (a *administrationImpl) UpdateNamedRule(dataVersion string, ID int, name string, ruleTypeID int, value interface{}, onMissing string, negate bool) (*model.Rule, error) {
+	return a.app.updateNamedRule(dataVersion, ID, name, ruleTypeID, value, onMissing, negate)
+}
+
+func (a *administrationImpl) DeleteNamedRule(dataVersion string, ID int) error {
+	return a.app.deleteNamedRule(dataVersion, ID)
+}
+
+func (a *administrationImpl) GetRuleUsage(dataVersion string, ID int) ([]model.UIItem, error) {
+	return a.app.getRuleUsage(dataVersion, ID)
+}
+
+func (a *administrationImpl) AttachRule(dataVersion string, uiItemID int, ID int) (*model.Rule, error) {
+	return a.app.attachRule(dataVersion, uiItemID, ID)
+}
+
+func (a *administrationImpl) DetachRule(dataVersion string, uiItemID int, ID int) error {
+	return a.app.detachRule(dataVersion, uiItemID, ID)
+}
+
 func (a *administrationImpl) GetRuleTypes(dataVersion string) ([]model.RuleType, error) {
 	return a.app.getRuleTypes(dataVersion)
 }
 
+func (a *administrationImpl) GetUUIDLists(dataVersion string) ([]model.UUIDList, error) {
+	return a.app.getUUIDLists(dataVersion)
+}
+
+func (a *administrationImpl) GetUUIDList(dataVersion string, ID int) (*model.UUIDList, error) {
+	return a.app.getUUIDList(dataVersion, ID)
+}
+
+func (a *administrationImpl) CreateUUIDList(dataVersion string, name string, uuids []string) (*model.UUIDList, error) {
+	return a.app.createUUIDList(dataVersion, name, uuids)
+}
+
+func (a *administrationImpl) UpdateUUIDList(dataVersion string, ID int, name string, uuids []string) (*model.UUIDList, error) {
+	return a.app.updateUUIDList(dataVersion, ID, name, uuids)
+}
+
+func (a *administrationImpl) DeleteUUIDList(dataVersion string, ID int) error {
+	return a.app.deleteUUIDList(dataVersion, ID)
+}
+
+func (a *administrationImpl) GetUIItemsStrings(dataVersion string) ([]model.UIItemStrings, error) {
+	return a.app.getUIItemsStrings(dataVersion)
+}
+
+func (a *administrationImpl) UpdateUIItemsStrings(dataVersion string, tables []model.UIItemStrings) error {
+	return a.app.updateUIItemsStrings(dataVersion, tables)
+}
+
+func (a *administrationImpl) GetMissingUIItemsStrings(dataVersion string, locales []string) (map[string][]model.MissingStrings, error) {
+	return a.app.getMissingUIItemsStrings(dataVersion, locales)
+}
+
 //Storage is used by core to storage data - DB storage adapter, file storage adapter etc
 type Storage interface {
 	Start() error
@@ -157,21 +287,47 @@ type Storage interface {
 
 	ReadContentItems(dataVersion string) ([]model.ContentItem, error)
 	ReadContentItem(dataVersion string, ID int) (*model.ContentItem, error)
-	CreateContentItem(dataVersion string, name string) (*model.ContentItem, error)
-	UpdateContentItem(dataVersion string, ID int, name string) (*model.ContentItem, error)
+	ReadUIItemContentItems(dataVersion string, uiItemID int) ([]model.ContentItem, error)
+	CreateContentItem(dataVersion string, name string, payload interface{}, payloadSchema map[string]interface{}) (*model.ContentItem, error)
+	UpdateContentItem(dataVersion string, ID int, name string, payload interface{}, payloadSchema map[string]interface{}) (*model.ContentItem, error)
 	DeleteContentItem(dataVersion string, ID int) error
 
 	ReadUIItem(dataVersion string, contentItemID int, ID int) (*model.UIItem, error)
-	CreateUIItem(dataVersion string, contentItemID int, name string, order int) (*model.UIItem, error)
-	UpdateUIItem(dataVersion string, contentItemID int, ID int, name string, order int) (*model.UIItem, error)
+	CreateUIItem(dataVersion string, contentItemID int, name string, order int, dataCategories []string, matchMode string, payload interface{}) (*model.UIItem, error)
+	UpdateUIItem(dataVersion string, contentItemID int, ID int, name string, order int, dataCategories []string, matchMode string, payload interface{}) (*model.UIItem, error)
 	DeleteUIItem(dataVersion string, contentItemID int, ID int) error
+	LinkUIItem(dataVersion string, contentItemID int, ID int, order int) (*model.UIItem, error)
+	UnlinkUIItem(dataVersion string, contentItemID int, ID int) error
+
+	ReadChildUIItem(dataVersion string, parentID int, ID int) (*model.UIItem, error)
+	CreateChildUIItem(dataVersion string, parentID int, name string, order int, dataCategories []string, matchMode string, payload interface{}) (*model.UIItem, error)
+	UpdateChildUIItem(dataVersion string, parentID int, ID int, name string, order int, dataCategories []string, matchMode string, payload interface{}) (*model.UIItem, error)
+	DeleteChildUIItem(dataVersion string, parentID int, ID int) error
 
 	ReadRule(dataVersion string, uiItemID int, ID int) (*model.Rule, error)
-	CreateRule(dataVersion string, uiItemID int, ruleTypeID int, value interface{}) (*model.Rule, error)
-	UpdateRule(dataVersion string, ID int, uiItemID int, ruleTypeID int, value interface{}) (*model.Rule, error)
+	CreateRule(dataVersion string, uiItemID int, ruleTypeID int, value interface{}, onMissing string, negate bool) (*model.Rule, error)
+	UpdateRule(dataVersion string, ID int, uiItemID int, ruleTypeID int, value interface{}, onMissing string, negate bool) (*model.Rule, error)
 	DeleteRule(dataVersion string, uiItemID int, ID int) error
 
+	ReadNamedRules(dataVersion string) ([]model.Rule, error)
+	ReadNamedRule(dataVersion string, ID int) (*model.Rule, error)
+	CreateNamedRule(dataVersion string, name string, ruleTypeID int, value interface{}, onMissing string, negate bool) (*model.Rule, error)
+	UpdateNamedRule(dataVersion string, ID int, name string, ruleTypeID int, value interface{}, onMissing string, negate bool) (*model.Rule, error)
+	DeleteNamedRule(dataVersion string, ID int) error
+	ReadRuleUsage(dataVersion string, ID int) ([]model.UIItem, error)
+	AttachRule(dataVersion string, uiItemID int, ID int) (*model.Rule, error)
+	DetachRule(dataVersion string, uiItemID int, ID int) error
+
 	ReadRuleTypes(dataVersion string) ([]model.RuleType, error)
+
+	ReadUUIDLists(dataVersion string) ([]model.UUIDList, error)
+	ReadUUIDList(dataVersion string, ID int) (*model.UUIDList, error)
+	CreateUUIDList(dataVersion string, name string, uuids []string) (*model.UUIDList, error)
+	UpdateUUIDList(dataVersion string, ID int, name string, uuids []string) (*model.UUIDList, error)
+	DeleteUUIDList(dataVersion string, ID int) error
+
+	ReadUIItemsStrings(dataVersion string) ([]model.UIItemStrings, error)
+	UpdateUIItemsStrings(dataVersion string, tables []model.UIItemStrings) error
 }
 
 //StorageListener listenes for change data storage events
@@ -0,0 +1,213 @@
+/*
+ *   Copyright (c) 2020 Board of Trustees of the University of Illinois.
+ *   All rights reserved.
+
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+
+ *   http://www.apache.org/licenses/LICENSE-2.0
+
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package model
+
+import (
+	"log"
+	"math"
+	"sort"
+)
+
+//CompiledUIContent is an immutable snapshot of a data version which is ready for evaluation.
+//It must not be modified after it is created as it is shared between all requests.
+type CompiledUIContent struct {
+	Data      []CompiledContentItem
+	UUIDLists UUIDLists
+}
+
+//CompiledContentItem represents a compiled content item, the ui items are sorted by order
+type CompiledContentItem struct {
+	ID      int
+	Name    string
+	UIItems []CompiledUIItem
+	Payload interface{}
+}
+
+//CompiledUIItem represents a compiled ui item
+type CompiledUIItem struct {
+	ID    int
+	Name  string
+	Order int
+	Rules []CompiledRule
+
+	DataCategories []string
+	//RequiredPrivacyLevel is the minimum user privacy level which permits the ui item data categories
+	RequiredPrivacyLevel int
+	MatchMode            string
+	//Children are the compiled nested ui items sorted by order
+	Children []CompiledUIItem
+	Payload  interface{}
+	Strings  LocalizedStrings
+}
+
+//PermitsDataCategories checks if the user privacy level permits the ui item data categories, no user has level 0
+func (uiItem CompiledUIItem) PermitsDataCategories(inputData InputRulesParameters) bool {
+	if uiItem.RequiredPrivacyLevel == 0 {
+		return true
+	}
+	user := inputData.User
+	return user != nil && user.PrivacySettings.Level >= uiItem.RequiredPrivacyLevel
+}
+
+//Match matches if the ui item data categories are permitted and the ui item rules match according to the match mode
+func (uiItem CompiledUIItem) Match(inputData InputRulesParameters) bool {
+	if !uiItem.PermitsDataCategories(inputData) {
+		return false
+	}
+	if len(uiItem.Rules) == 0 {
+		return true //if no rules it matches
+	}
+
+	//stop on the first rule which decides the result
+	decisive := uiItem.MatchMode == MatchModeAny || uiItem.MatchMode == MatchModeNone
+	for _, rule := range uiItem.Rules {
+		result := rule.Evaluate(inputData)
+		if result.Err != nil {
+			log.Printf("Ui item %s is not shown - %s\n", uiItem.Name, result.Err.Error())
+			return false
+		}
+		if result.Matched == decisive {
+			return uiItem.MatchMode == MatchModeAny
+		}
+	}
+	return uiItem.MatchMode != MatchModeAny
+}
+
+//CombineResults combines the results of all the ui item rules according to the match mode
+func (uiItem CompiledUIItem) CombineResults(results []RuleResult) bool {
+	if len(results) == 0 {
+		return true //if no rules it matches
+	}
+	matchedCount := 0
+	for _, result := range results {
+		if result.Err != nil {
+			return false
+		}
+		if result.Matched {
+			matchedCount++
+		}
+	}
+	switch uiItem.MatchMode {
+	case MatchModeAny:
+		return matchedCount > 0
+	case MatchModeNone:
+		return matchedCount == 0
+	}
+	return matchedCount == len(results)
+}
+
+//MatchChildren gives the nested ui items tree which matches, it must be called only if the ui item matches
+func (uiItem CompiledUIItem) MatchChildren(inputData InputRulesParameters) []UIContentNode {
+	result := []UIContentNode{}
+	for _, child := range uiItem.Children {
+		if child.Match(inputData) {
+			result = append(result, UIContentNode{Name: child.Name, Children: child.MatchChildren(inputData)})
+		}
+	}
+	return result
+}
+
+//MatchChildrenPayloads gives the nested ui items tree with the payloads and the strings resolved for the locale chain
+//which matches, it must be called only if the ui item matches
+func (uiItem CompiledUIItem) MatchChildrenPayloads(inputData InputRulesParameters, localeChain []string) []UIItemPayloadNode {
+	result := []UIItemPayloadNode{}
+	for _, child := range uiItem.Children {
+		if child.Match(inputData) {
+			result = append(result, child.PayloadNode(inputData, localeChain))
+		}
+	}
+	return result
+}
+
+//PayloadNode gives the ui item with its payload, its strings resolved for the locale chain and its matched children
+func (uiItem CompiledUIItem) PayloadNode(inputData InputRulesParameters, localeChain []string) UIItemPayloadNode {
+	return UIItemPayloadNode{Name: uiItem.Name, Payload: uiItem.Payload, Strings: uiItem.Strings.Resolve(localeChain),
+		Children: uiItem.MatchChildrenPayloads(inputData, localeChain)}
+}
+
+//CompiledRule represents a rule with its compiled predicate
+type CompiledRule struct {
+	Rule      Rule
+	Predicate Predicate
+	Inputs    []string
+	OnMissing string
+}
+
+//CompileUIContent compiles a data version. The created and updated rule values are checked that they can be compiled
+//so only values stored before this check can fail - such a rule is logged and never matches as this is how the rule
+//types have always treated malformed values, the snapshot is given in all cases. An ui item with a not configured
+//data category is never permitted.
+func CompileUIContent(uiContent *UIContent, privacyDataCategories PrivacyDataCategories) *CompiledUIContent {
+	result := CompiledUIContent{Data: make([]CompiledContentItem, len(uiContent.Data)), UUIDLists: NewUUIDLists(uiContent.UUIDLists)}
+	localizedStrings := NewLocalizedStrings(uiContent.Strings)
+	for index, contentItem := range uiContent.Data {
+		result.Data[index] = compileContentItem(contentItem, privacyDataCategories, localizedStrings)
+	}
+	return &result
+}
+
+func compileContentItem(contentItem ContentItem, privacyDataCategories PrivacyDataCategories, localizedStrings map[int]LocalizedStrings) CompiledContentItem {
+	return CompiledContentItem{ID: contentItem.ID, Name: contentItem.Name, UIItems: compileUIItems(contentItem.UIItems, privacyDataCategories, localizedStrings),
+		Payload: contentItem.Payload}
+}
+
+func compileUIItems(uiItems []UIItem, privacyDataCategories PrivacyDataCategories, localizedStrings map[int]LocalizedStrings) []CompiledUIItem {
+	result := make([]CompiledUIItem, len(uiItems))
+	for index, uiItem := range uiItems {
+		result[index] = compileUIItem(uiItem, privacyDataCategories, localizedStrings)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Order < result[j].Order
+	})
+	return result
+}
+
+func compileUIItem(uiItem UIItem, privacyDataCategories PrivacyDataCategories, localizedStrings map[int]LocalizedStrings) CompiledUIItem {
+	result := CompiledUIItem{ID: uiItem.ID, Name: uiItem.Name, Order: uiItem.Order, DataCategories: uiItem.DataCategories, MatchMode: uiItem.MatchMode,
+		Payload: uiItem.Payload, Strings: localizedStrings[uiItem.ID]}
+	requiredLevel, err := privacyDataCategories.RequiredLevel(uiItem.DataCategories)
+	if err != nil {
+		log.Printf("Ui item %s will never be permitted - %s\n", uiItem.Name, err.Error())
+		requiredLevel = math.MaxInt32
+	}
+	result.RequiredPrivacyLevel = requiredLevel
+	result.Children = compileUIItems(uiItem.Children, privacyDataCategories, localizedStrings)
+
+	if uiItem.Rules == nil {
+		return result //if no rules it matches
+	}
+
+	result.Rules = make([]CompiledRule, len(*uiItem.Rules))
+	for index, rule := range *uiItem.Rules {
+		predicate, err := rule.Compile()
+		if err != nil {
+			log.Printf("Rule %d of ui item %s cannot be compiled, it will never match - %s\n", rule.ID, uiItem.Name, err.Error())
+			predicate = neverMatch
+		}
+		var inputs []string
+		if rule.RuleType != nil {
+			inputs = rule.RuleType.Inputs(rule.Value)
+		}
+		result.Rules[index] = CompiledRule{Rule: rule, Predicate: predicate, Inputs: inputs, OnMissing: rule.OnMissing}
+	}
+	return result
+}
+
+func neverMatch(inputData InputRulesParameters) bool {
+	return false
+}
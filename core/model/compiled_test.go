@@ -0,0 +1,398 @@
+/*
+ *   Copyright (c) 2020 Board of Trustees of the University of Illinois.
+ *   All rights reserved.
+
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+
+ *   http://www.apache.org/licenses/LICENSE-2.0
+
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package model
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCompileUIContent(t *testing.T) {
+	enable := NewEnableRuleType(1, "enable")
+	platform := NewPlatformRuleType(2, "platform")
+	uiItems := []UIItem{
+		{ID: 1, Name: "third", Order: 3, Rules: &[]Rule{{ID: 1, RuleType: enable, Value: true}}},
+		{ID: 2, Name: "first", Order: 1},
+		{ID: 3, Name: "broken", Order: 2, Rules: &[]Rule{{ID: 2, RuleType: platform, Value: "ios"}}},
+		{ID: 4, Name: "disabled", Order: 0, Rules: &[]Rule{{ID: 3, RuleType: enable, Value: false}}},
+	}
+	uiContent := UIContent{Data: []ContentItem{{ID: 1, Name: "browse", UIItems: uiItems}}}
+
+	compiled := CompileUIContent(&uiContent, PrivacyDataCategories{})
+	if len(compiled.Data) != 1 || compiled.Data[0].Name != "browse" {
+		t.Fatalf("Wrong compiled content items %v", compiled.Data)
+	}
+
+	var names []string
+	for _, uiItem := range compiled.Data[0].UIItems {
+		if uiItem.Match(InputRulesParameters{}) {
+			names = append(names, uiItem.Name)
+		}
+	}
+	if len(names) != 2 || names[0] != "first" || names[1] != "third" {
+		t.Errorf("Wrong matched ui items %v", names)
+	}
+
+	//the source data must not be reordered
+	if uiItems[0].Name != "third" || uiItems[3].Name != "disabled" {
+		t.Error("The source ui items were modified")
+	}
+}
+
+func TestCompileUIContentDataCategories(t *testing.T) {
+	uiItems := []UIItem{
+		{ID: 1, Name: "events", Order: 1},
+		{ID: 2, Name: "nearby", Order: 2, DataCategories: []string{"location"}},
+		{ID: 3, Name: "wallet", Order: 3, DataCategories: []string{"location", "personal"}},
+		{ID: 4, Name: "unknown", Order: 4, DataCategories: []string{"health"}},
+	}
+	uiContent := UIContent{Data: []ContentItem{{ID: 1, Name: "browse", UIItems: uiItems}}}
+	privacyDataCategories := PrivacyDataCategories{"location": 2, "personal": 4}
+	compiled := CompileUIContent(&uiContent, privacyDataCategories)
+
+	matched := func(inputData InputRulesParameters) []string {
+		var names []string
+		for _, uiItem := range compiled.Data[0].UIItems {
+			if uiItem.Match(inputData) {
+				names = append(names, uiItem.Name)
+			}
+		}
+		return names
+	}
+	levels := map[int]string{0: "[events]", 2: "[events nearby]", 4: "[events nearby wallet]", 5: "[events nearby wallet]"}
+	for level, expected := range levels {
+		names := matched(InputRulesParameters{User: &User{PrivacySettings: PrivacySettings{Level: level}}})
+		if fmt.Sprint(names) != expected {
+			t.Errorf("Level %d should give %s but got %v", level, expected, names)
+		}
+	}
+	if fmt.Sprint(matched(InputRulesParameters{})) != "[events]" {
+		t.Error("Anonymous user should not be permitted any data category")
+	}
+
+	if privacyDataCategories.Validate([]string{"location", "health"}) == nil {
+		t.Error("Not configured data category should not be valid")
+	}
+}
+
+func TestCompiledRuleOnMissing(t *testing.T) {
+	illiniCash := NewIlliniCashRuleType(1, "illini_cash")
+	notResident := map[string]interface{}{"housingResidenceStatus": false}
+	uiItems := []UIItem{
+		{ID: 1, Name: "default", Order: 1, Rules: &[]Rule{{ID: 1, RuleType: illiniCash, Value: notResident}}},
+		{ID: 2, Name: "match", Order: 2, Rules: &[]Rule{{ID: 2, RuleType: illiniCash, Value: notResident, OnMissing: OnMissingMatch}}},
+		{ID: 3, Name: "no-match", Order: 3, Rules: &[]Rule{{ID: 3, RuleType: illiniCash, Value: notResident, OnMissing: OnMissingNoMatch}}},
+		{ID: 4, Name: "error", Order: 4, Rules: &[]Rule{{ID: 4, RuleType: illiniCash, Value: notResident, OnMissing: OnMissingError}}},
+	}
+	compiled := CompileUIContent(&UIContent{Data: []ContentItem{{ID: 1, Name: "browse", UIItems: uiItems}}}, PrivacyDataCategories{})
+
+	missingExpected := map[string]bool{"default": true, "match": true, "no-match": false, "error": false}
+	providedInput := InputRulesParameters{IlliniCash: &IlliniCash{HousingResidentStatus: false}}
+	for _, uiItem := range compiled.Data[0].UIItems {
+		if uiItem.Match(InputRulesParameters{}) != missingExpected[uiItem.Name] {
+			t.Errorf("%s should give %t when illini cash is missing", uiItem.Name, missingExpected[uiItem.Name])
+		}
+		if !uiItem.Match(providedInput) {
+			t.Errorf("%s should match when illini cash is provided", uiItem.Name)
+		}
+
+		result := uiItem.Rules[0].Evaluate(InputRulesParameters{})
+		if len(result.Missing) != 1 || result.Missing[0] != InputIlliniCash {
+			t.Errorf("%s should report the missing illini cash %v", uiItem.Name, result.Missing)
+		}
+		if (result.Err != nil) != (uiItem.Name == "error") {
+			t.Errorf("Wrong error for %s - %v", uiItem.Name, result.Err)
+		}
+	}
+}
+
+func TestValidateOnMissingFallback(t *testing.T) {
+	items := []struct {
+		ruleType  string
+		value     string
+		onMissing string
+		valid     bool
+	}{
+		{"privacy", `{"min": 2, "anonymous": true}`, OnMissingDefault, true},
+		{"privacy", `{"min": 2, "anonymous": true}`, OnMissingMatch, false},
+		{"privacy", `3`, OnMissingMatch, true},
+		{"rollout", `{"percentage": 10, "salt": "a", "fallback": false}`, OnMissingNoMatch, false},
+		{"geofence", `{"circle": {"latitude": 40, "longitude": -88, "radius": 10}, "fallback": true}`, OnMissingError, false},
+		{"geofence", `{"circle": {"latitude": 40, "longitude": -88, "radius": 10}}`, OnMissingError, true},
+		{"composite", `{"and": [{"enable": true}, {"not": {"rollout": {"percentage": 10, "salt": "a", "fallback": true}}}]}`, OnMissingMatch, false},
+		{"composite", `{"and": [{"enable": true}, {"rollout": {"percentage": 10, "salt": "a"}}]}`, OnMissingMatch, true},
+	}
+	for _, item := range items {
+		err := ValidateOnMissingFallback(item.ruleType, parseValue(t, item.value), item.onMissing)
+		if item.valid != (err == nil) {
+			t.Errorf("Wrong validation for %s %s with %s - %v", item.ruleType, item.value, item.onMissing, err)
+		}
+	}
+}
+
+func TestCompiledCompositeRuleOnMissing(t *testing.T) {
+	composite := NewCompositeRuleType(1, "composite")
+	value := parseValue(t, `{"or": [{"illini_cash": {"housingResidenceStatus": false}}, {"enable": true}]}`)
+	cases := []struct {
+		onMissing string
+		expected  bool
+	}{
+		{OnMissingDefault, true},
+		{OnMissingMatch, true},
+		//the policy applies to the whole composite, the matching enable branch is not evaluated
+		{OnMissingNoMatch, false},
+		{OnMissingError, false},
+	}
+	for _, item := range cases {
+		rule := Rule{ID: 1, RuleType: composite, Value: value, OnMissing: item.onMissing}
+		compiled := compileUIItem(UIItem{Name: "composite", Rules: &[]Rule{rule}}, PrivacyDataCategories{}, nil)
+		result := compiled.Rules[0].Evaluate(InputRulesParameters{})
+		if result.Matched != item.expected {
+			t.Errorf("\"%s\" should give %t when illini cash is missing", item.onMissing, item.expected)
+		}
+		if fmt.Sprint(result.Missing) != "[illini_cash]" {
+			t.Errorf("\"%s\" should report the missing illini cash %v", item.onMissing, result.Missing)
+		}
+	}
+}
+
+func TestRuleTypesInputs(t *testing.T) {
+	cases := map[string]string{
+		`{"roles": "student"}`:                                                                                       "user.roles",
+		`{"attribute": {"name": "campus", "op": "exists"}}`:                                                          "attributes.campus",
+		`{"header": {"name": "user-agent", "pattern": "."}}`:                                                         "headers.User-Agent",
+		`{"and": [{"locale": ["es"]}, {"not": {"app_version": {"version": ">=1.0.0", "os": "ios"}}}]}`:               "headers.Accept-Language,platform.app_version,platform.os",
+		`{"or": [{"enable": true}, {"rollout": {"percentage": 10, "salt": "a"}}, {"uuid_list": {"list": "pilot"}}]}`: "user.uuid",
+	}
+	composite := NewCompositeRuleType(1, "composite")
+	for value, expected := range cases {
+		inputs := composite.Inputs(parseValue(t, value))
+		if fmt.Sprint(inputs) != fmt.Sprint(strings.Split(expected, ",")) {
+			t.Errorf("%s should need %s but gives %v", value, expected, inputs)
+		}
+	}
+
+	inputData := InputRulesParameters{User: &User{}, Attributes: Attributes{"campus": "urbana"}, Headers: Headers{"User-Agent": "app"},
+		AuthVersion: "3", AuthV3: &AuthV3{}}
+	missing := MissingInputs([]string{"user", "user.uuid", "attributes.campus", "attributes.year", "headers.User-Agent", "auth"}, inputData)
+	if fmt.Sprint(missing) != "[user.uuid attributes.year auth]" {
+		t.Errorf("Wrong missing inputs %v", missing)
+	}
+	if ValidateOnMissing("skip") == nil || ValidateOnMissing("") != nil || ValidateOnMissing(OnMissingError) != nil {
+		t.Error("Wrong on missing validation")
+	}
+}
+
+func TestCompiledUIItemMatchMode(t *testing.T) {
+	enable := NewEnableRuleType(1, "enable")
+	rules := func(values ...bool) *[]Rule {
+		list := make([]Rule, len(values))
+		for index, value := range values {
+			list[index] = Rule{ID: index + 1, RuleType: enable, Value: value}
+		}
+		return &list
+	}
+	negated := &[]Rule{{ID: 1, RuleType: enable, Value: false, Negate: true}}
+	cases := []struct {
+		uiItem   UIItem
+		expected bool
+	}{
+		{UIItem{Name: "default", Rules: rules(true, false)}, false},
+		{UIItem{Name: "all", MatchMode: MatchModeAll, Rules: rules(true, true)}, true},
+		{UIItem{Name: "any", MatchMode: MatchModeAny, Rules: rules(false, true)}, true},
+		{UIItem{Name: "any none matched", MatchMode: MatchModeAny, Rules: rules(false, false)}, false},
+		{UIItem{Name: "none", MatchMode: MatchModeNone, Rules: rules(false, false)}, true},
+		{UIItem{Name: "none one matched", MatchMode: MatchModeNone, Rules: rules(false, true)}, false},
+		{UIItem{Name: "any without rules", MatchMode: MatchModeAny}, true},
+		{UIItem{Name: "negated", Rules: negated}, true},
+		{UIItem{Name: "none negated", MatchMode: MatchModeNone, Rules: negated}, false},
+	}
+	for _, item := range cases {
+		compiled := compileUIItem(item.uiItem, PrivacyDataCategories{}, nil)
+		if compiled.Match(InputRulesParameters{}) != item.expected {
+			t.Errorf("%s should give %t", item.uiItem.Name, item.expected)
+		}
+		results := make([]RuleResult, len(compiled.Rules))
+		for index, rule := range compiled.Rules {
+			results[index] = rule.Evaluate(InputRulesParameters{})
+		}
+		if compiled.CombineResults(results) != item.expected {
+			t.Errorf("%s combined results should give %t", item.uiItem.Name, item.expected)
+		}
+	}
+
+	if ValidateMatchMode("some") == nil || ValidateMatchMode("") != nil || ValidateMatchMode(MatchModeNone) != nil {
+		t.Error("Wrong match mode validation")
+	}
+}
+
+func TestCompiledUIItemChildren(t *testing.T) {
+	enable := NewEnableRuleType(1, "enable")
+	grandchildren := []UIItem{
+		{ID: 4, Name: "map", Order: 2},
+		{ID: 5, Name: "list", Order: 1},
+	}
+	children := []UIItem{
+		{ID: 2, Name: "hidden", Order: 1, Rules: &[]Rule{{ID: 1, RuleType: enable, Value: false}}, Children: []UIItem{{ID: 6, Name: "orphan", Order: 1}}},
+		{ID: 3, Name: "nearby", Order: 2, Children: grandchildren},
+	}
+	uiItems := []UIItem{
+		{ID: 1, Name: "panel", Order: 1, Children: children},
+		{ID: 7, Name: "disabled", Order: 2, Rules: &[]Rule{{ID: 2, RuleType: enable, Value: false}}, Children: []UIItem{{ID: 8, Name: "child", Order: 1}}},
+	}
+	uiContent := UIContent{Data: []ContentItem{{ID: 1, Name: "browse", UIItems: uiItems}}}
+	compiled := CompileUIContent(&uiContent, PrivacyDataCategories{})
+
+	var format func(nodes []UIContentNode) string
+	format = func(nodes []UIContentNode) string {
+		names := make([]string, len(nodes))
+		for index, node := range nodes {
+			names[index] = node.Name
+			if len(node.Children) > 0 {
+				names[index] += format(node.Children)
+			}
+		}
+		return fmt.Sprintf("%v", names)
+	}
+
+	var nodes []UIContentNode
+	for _, uiItem := range compiled.Data[0].UIItems {
+		if uiItem.Match(InputRulesParameters{}) {
+			nodes = append(nodes, UIContentNode{Name: uiItem.Name, Children: uiItem.MatchChildren(InputRulesParameters{})})
+		}
+	}
+	if result := format(nodes); result != "[panel[nearby[list map]]]" {
+		t.Errorf("Wrong matched tree %s", result)
+	}
+}
+
+func TestValidatePayload(t *testing.T) {
+	schema, _ := parseValue(t, `{"type": "object", "required": ["title"], "properties": {"title": {"type": "string", "minLength": 1}, "color": {"type": "string", "pattern": "^#[0-9a-f]{6}$"}}}`).(map[string]interface{})
+	if err := ValidatePayloadSchema(schema); err != nil {
+		t.Fatalf("Valid schema is rejected - %s", err.Error())
+	}
+
+	if err := ValidatePayload(schema, parseValue(t, `{"title": "Events", "color": "#13294b"}`)); err != nil {
+		t.Errorf("Valid payload is rejected - %s", err.Error())
+	}
+	if err := ValidatePayload(schema, nil); err != nil {
+		t.Errorf("No payload is rejected - %s", err.Error())
+	}
+	if err := ValidatePayload(nil, parseValue(t, `[1, 2]`)); err != nil {
+		t.Errorf("Payload without schema is rejected - %s", err.Error())
+	}
+	err := ValidatePayload(schema, parseValue(t, `{"color": "blue"}`))
+	if err == nil || err.Error() != "payload.title: is required; payload.color: must match ^#[0-9a-f]{6}$" {
+		t.Errorf("Wrong payload error %v", err)
+	}
+
+	uiItems := []UIItem{{ID: 1, Payload: parseValue(t, `{"title": "Events"}`), Children: []UIItem{{ID: 2, Payload: parseValue(t, `{"title": ""}`)}}}}
+	err = ValidateUIItemsPayloads(schema, uiItems)
+	if err == nil || err.Error() != "ui-items[id=2].payload.title: cannot be empty" {
+		t.Errorf("Wrong ui items payloads error %v", err)
+	}
+
+	badSchema, _ := parseValue(t, `{"type": "dict", "properties": {"link": {"pattern": "("}}}`).(map[string]interface{})
+	err = ValidatePayloadSchema(badSchema)
+	validationErrors, ok := err.(ValidationErrors)
+	if !ok || len(validationErrors) != 2 || validationErrors[0].Field != "payload-schema.type" || validationErrors[1].Field != "payload-schema.properties.link.pattern" {
+		t.Errorf("Wrong schema errors %v", err)
+	}
+}
+
+func TestCompiledUIItemPayloads(t *testing.T) {
+	enable := NewEnableRuleType(1, "enable")
+	children := []UIItem{
+		{ID: 2, Name: "hidden", Order: 1, Rules: &[]Rule{{ID: 1, RuleType: enable, Value: false}}, Payload: "hidden"},
+		{ID: 3, Name: "nearby", Order: 2, Payload: map[string]interface{}{"icon": "map"}},
+	}
+	uiItems := []UIItem{{ID: 1, Name: "panel", Order: 1, Children: children, Payload: map[string]interface{}{"title": "Panel"}}}
+	uiContent := UIContent{Data: []ContentItem{{ID: 1, Name: "browse", UIItems: uiItems, Payload: "browse"}}}
+	compiled := CompileUIContent(&uiContent, PrivacyDataCategories{})
+
+	contentItem := compiled.Data[0]
+	if contentItem.Payload != "browse" {
+		t.Errorf("Wrong content item payload %v", contentItem.Payload)
+	}
+	panel := contentItem.UIItems[0]
+	if fmt.Sprint(panel.Payload) != "map[title:Panel]" {
+		t.Errorf("Wrong ui item payload %v", panel.Payload)
+	}
+	nodes := panel.MatchChildrenPayloads(InputRulesParameters{}, nil)
+	if len(nodes) != 1 || nodes[0].Name != "nearby" || fmt.Sprint(nodes[0].Payload) != "map[icon:map]" || nodes[0].Children == nil {
+		t.Errorf("Wrong matched children payloads %v", nodes)
+	}
+}
+
+func TestLocaleFallbacksChain(t *testing.T) {
+	fallbacks := LocaleFallbacks{"zh-hk": "zh-hant", "zh-hant": "zh"}
+	items := []struct {
+		locales  []string
+		expected string
+	}{
+		{nil, "[en]"},
+		{[]string{"es-MX"}, "[es-mx es en]"},
+		{[]string{"zh-hk", "es"}, "[zh-hk zh-hant zh es en]"},
+		{[]string{"en-us", "es"}, "[en-us en es]"},
+	}
+	for _, item := range items {
+		chain := fallbacks.Chain(item.locales, "en")
+		if result := fmt.Sprint(chain); result != item.expected {
+			t.Errorf("Wrong chain for %v - %s", item.locales, result)
+		}
+	}
+
+	//a fallbacks cycle must not loop
+	cycle := LocaleFallbacks{"es": "pt", "pt": "es"}
+	if result := fmt.Sprint(cycle.Chain([]string{"es"}, "en")); result != "[es pt en]" {
+		t.Errorf("Wrong chain for cycle - %s", result)
+	}
+}
+
+func TestLocalizedStrings(t *testing.T) {
+	tables := []UIItemStrings{
+		{UIItemID: 1, Locale: "en", Strings: map[string]string{"title": "Events", "subtitle": "Near you"}},
+		{UIItemID: 1, Locale: "es", Strings: map[string]string{"title": "Eventos"}},
+		{UIItemID: 2, Locale: "en", Strings: map[string]string{"title": "Dining"}},
+		{UIItemID: 2, Locale: "zh", Strings: map[string]string{"title": "餐饮"}},
+	}
+	localizedStrings := NewLocalizedStrings(tables)
+
+	resolved := localizedStrings[1].Resolve([]string{"es-mx", "es", "en"})
+	if resolved["title"] != "Eventos" || resolved["subtitle"] != "Near you" {
+		t.Errorf("Wrong resolved strings %v", resolved)
+	}
+	if resolved := localizedStrings[3].Resolve([]string{"en"}); len(resolved) != 0 {
+		t.Errorf("Wrong resolved strings for no table %v", resolved)
+	}
+
+	missing := FindMissingStrings(tables, nil)
+	if result := fmt.Sprint(missing); result != "map[en:[] es:[{1 [subtitle]} {2 [title]}] zh:[{1 [subtitle title]}]]" {
+		t.Errorf("Wrong missing strings %s", result)
+	}
+	missing = FindMissingStrings(tables, []string{"ZH"})
+	if result := fmt.Sprint(missing); result != "map[zh:[{1 [subtitle title]}]]" {
+		t.Errorf("Wrong missing strings for zh %s", result)
+	}
+
+	err := UIItemStrings{UIItemID: 0, Locale: "Spanish", Strings: map[string]string{"": "x"}}.Validate()
+	if err == nil || err.Error() != "ui-item-id: must be positive; locale: must be a lower case locale, for example es-mx; strings: the keys cannot be empty" {
+		t.Errorf("Wrong validation error %v", err)
+	}
+}
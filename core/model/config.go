@@ -17,7 +17,59 @@
 
 package model
 
+import (
+	"fmt"
+)
+
 //Config represents the configuration entity
 type Config struct {
 	Flag1 bool
 }
+
+//EventEditorsGroupAlias is the group alias checked by the eventEditor auth rule condition
+const EventEditorsGroupAlias = "eventEditors"
+
+//GroupAliases maps group alias names to the group identifiers(URNs) which are configured for the deployment
+type GroupAliases map[string]string
+
+//Group gives the group identifier for an alias
+func (ga GroupAliases) Group(alias string) (string, bool) {
+	group, exist := ga[alias]
+	if !exist || len(group) == 0 {
+		return "", false
+	}
+	return group, true
+}
+
+//PrivacyDataCategories maps the data categories which ui items can use to the minimum privacy level which permits them
+type PrivacyDataCategories map[string]int
+
+//RequiredLevel gives the minimum privacy level which permits all the categories
+func (pdc PrivacyDataCategories) RequiredLevel(categories []string) (int, error) {
+	level := 0
+	for _, category := range categories {
+		categoryLevel, exist := pdc[category]
+		if !exist {
+			return 0, fmt.Errorf("%s is not a configured data category", category)
+		}
+		if categoryLevel > level {
+			level = categoryLevel
+		}
+	}
+	return level, nil
+}
+
+//Validate checks if all the categories are configured
+func (pdc PrivacyDataCategories) Validate(categories []string) error {
+	var validationErrors ValidationErrors
+	for index, category := range categories {
+		if _, exist := pdc[category]; !exist {
+			validationErrors = append(validationErrors, ValidationError{Field: fmt.Sprintf("data-categories[%d]", index),
+				Message: category + " is not a configured data category"})
+		}
+	}
+	if len(validationErrors) > 0 {
+		return validationErrors
+	}
+	return nil
+}
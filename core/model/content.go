@@ -23,7 +23,9 @@ import (
 
 //UIContent represents content ui entity
 type UIContent struct {
-	Data []ContentItem `json:"data"`
+	Data      []ContentItem   `json:"data"`
+	UUIDLists []UUIDList      `json:"uuid-lists"`
+	Strings   []UIItemStrings `json:"strings"`
 }
 
 //Print prints the ui content strucute
@@ -34,8 +36,29 @@ func (uiContent *UIContent) Print() {
 }
 
 //NewUIContent creates new ui content instance
-func NewUIContent(data []ContentItem) UIContent {
-	return UIContent{Data: data}
+func NewUIContent(data []ContentItem, uuidLists []UUIDList, strings []UIItemStrings) UIContent {
+	return UIContent{Data: data, UUIDLists: uuidLists, Strings: strings}
+}
+
+//UIContentNode represents a shown ui item with its shown children
+type UIContentNode struct {
+	Name     string          `json:"name"`
+	Children []UIContentNode `json:"children"`
+}
+
+//UIItemPayloadNode represents a shown ui item with its payload and its shown children
+type UIItemPayloadNode struct {
+	Name    string      `json:"name"`
+	Payload interface{} `json:"payload"`
+	//Strings are the ui item localized strings resolved for the request locale
+	Strings  map[string]string   `json:"strings"`
+	Children []UIItemPayloadNode `json:"children"`
+}
+
+//ContentItemPayloadNode represents a content item payload with its shown ui items
+type ContentItemPayloadNode struct {
+	Payload interface{}         `json:"payload"`
+	UIItems []UIItemPayloadNode `json:"ui-items"`
 }
 
 //ContentItem represents content item entity
@@ -43,6 +66,11 @@ type ContentItem struct {
 	ID      int      `json:"id"`
 	Name    string   `json:"name"`
 	UIItems []UIItem `json:"ui-items"`
+
+	//Payload is arbitrary JSON data which is given to the clients with the content item
+	Payload interface{} `json:"payload"`
+	//PayloadSchema is the JSON schema which the ui items payloads must satisfy, any payload is permitted if it is not set
+	PayloadSchema map[string]interface{} `json:"payload-schema"`
 }
 
 //String give the string representation of the content item
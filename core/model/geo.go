@@ -0,0 +1,140 @@
+/*
+ *   Copyright (c) 2020 Board of Trustees of the University of Illinois.
+ *   All rights reserved.
+
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+
+ *   http://www.apache.org/licenses/LICENSE-2.0
+
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+const earthRadiusMeters = 6371000
+
+//Location represents the client location entity
+type Location struct {
+	Latitude  float64
+	Longitude float64
+}
+
+func (location *Location) String() string {
+	return fmt.Sprintf("Latitude:%f, Longitude:%f", location.Latitude, location.Longitude)
+}
+
+//Validate checks if the coordinates are in range
+func (location Location) Validate() error {
+	if math.IsNaN(location.Latitude) || location.Latitude < -90 || location.Latitude > 90 {
+		return errors.New("latitude must be between -90 and 90")
+	}
+	if math.IsNaN(location.Longitude) || location.Longitude < -180 || location.Longitude > 180 {
+		return errors.New("longitude must be between -180 and 180")
+	}
+	return nil
+}
+
+//DistanceTo gives the great-circle distance in meters
+func (location Location) DistanceTo(other Location) float64 {
+	lat1 := location.Latitude * math.Pi / 180
+	lat2 := other.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLng := (other.Longitude - location.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+//Polygon represents a simple polygon, the last vertex is connected to the first one
+type Polygon []Location
+
+//Validate checks if the polygon has at least 3 vertices, has an area and its edges do not cross each other
+func (polygon Polygon) Validate() error {
+	if len(polygon) < 3 {
+		return errors.New("must have at least 3 points")
+	}
+	for index, point := range polygon {
+		if err := point.Validate(); err != nil {
+			return fmt.Errorf("point %d - %s", index, err.Error())
+		}
+	}
+	if polygon.area() == 0 {
+		return errors.New("must have an area")
+	}
+
+	count := len(polygon)
+	for i := 0; i < count; i++ {
+		for j := i + 1; j < count; j++ {
+			//neighbour edges share a vertex
+			if j == i+1 || (i == 0 && j == count-1) {
+				continue
+			}
+			if segmentsIntersect(polygon[i], polygon[(i+1)%count], polygon[j], polygon[(j+1)%count]) {
+				return fmt.Errorf("edges %d and %d cross each other", i, j)
+			}
+		}
+	}
+	return nil
+}
+
+//Contains checks if the point is inside the polygon. It treats the coordinates as planar which is accurate enough
+//for areas like a campus.
+func (polygon Polygon) Contains(point Location) bool {
+	inside := false
+	count := len(polygon)
+	for i, j := 0, count-1; i < count; j, i = i, i+1 {
+		a := polygon[i]
+		b := polygon[j]
+		if (a.Latitude > point.Latitude) != (b.Latitude > point.Latitude) {
+			longitude := (b.Longitude-a.Longitude)*(point.Latitude-a.Latitude)/(b.Latitude-a.Latitude) + a.Longitude
+			if point.Longitude < longitude {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+func (polygon Polygon) area() float64 {
+	sum := 0.0
+	count := len(polygon)
+	for i := 0; i < count; i++ {
+		a := polygon[i]
+		b := polygon[(i+1)%count]
+		sum += a.Longitude*b.Latitude - b.Longitude*a.Latitude
+	}
+	return math.Abs(sum) / 2
+}
+
+func segmentsIntersect(p1 Location, p2 Location, q1 Location, q2 Location) bool {
+	d1 := orientation(q1, q2, p1)
+	d2 := orientation(q1, q2, p2)
+	d3 := orientation(p1, p2, q1)
+	d4 := orientation(p1, p2, q2)
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+	return (d1 == 0 && onSegment(q1, q2, p1)) || (d2 == 0 && onSegment(q1, q2, p2)) ||
+		(d3 == 0 && onSegment(p1, p2, q1)) || (d4 == 0 && onSegment(p1, p2, q2))
+}
+
+func orientation(a Location, b Location, c Location) float64 {
+	return (b.Longitude-a.Longitude)*(c.Latitude-a.Latitude) - (b.Latitude-a.Latitude)*(c.Longitude-a.Longitude)
+}
+
+func onSegment(a Location, b Location, c Location) bool {
+	return math.Min(a.Longitude, b.Longitude) <= c.Longitude && c.Longitude <= math.Max(a.Longitude, b.Longitude) &&
+		math.Min(a.Latitude, b.Latitude) <= c.Latitude && c.Latitude <= math.Max(a.Latitude, b.Latitude)
+}
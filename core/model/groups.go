@@ -0,0 +1,159 @@
+/*
+ *   Copyright (c) 2020 Board of Trustees of the University of Illinois.
+ *   All rights reserved.
+
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+
+ *   http://www.apache.org/licenses/LICENSE-2.0
+
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+//GroupMatcher matches the groups which a user is member of
+type GroupMatcher interface {
+	Match(groups []string) bool
+}
+
+//ParseGroupMatcher parses and precompiles a group membership value. The value is one of
+//	"urn:group" - exact match
+//	{ "prefix": "urn:mace:uiuc.edu:urbana:authman:app-rokwire-" }
+//	{ "glob": "urn:mace:uiuc.edu:urbana:authman:app-rokwire-*" } - * matches any characters and ? matches one character
+//	{ "regex": "urn:mace:uiuc.edu:.*:app-rokwire-(admin|editor)" } - always anchored to the whole group
+//	{ "anyOf": [ value, value ] } or { "allOf": [ value, value ] }
+//A pattern matches if the user is member of at least one group which matches it.
+func ParseGroupMatcher(value interface{}, field string) (GroupMatcher, error) {
+	if group, ok := value.(string); ok {
+		if len(group) == 0 {
+			return nil, newValidationError(field, "must be a non empty string")
+		}
+		return exactGroupMatcher(group), nil
+	}
+
+	mapData, ok := value.(map[string]interface{})
+	if !ok || len(mapData) != 1 {
+		return nil, newValidationError(field, "must be a string or an object with exactly one field")
+	}
+	for key, item := range mapData {
+		field = field + "." + key
+		switch key {
+		case "anyOf", "allOf":
+			list, ok := item.([]interface{})
+			if !ok || len(list) == 0 {
+				return nil, newValidationError(field, "must be a non empty list")
+			}
+			matchers := make([]GroupMatcher, len(list))
+			for index, listItem := range list {
+				matcher, err := ParseGroupMatcher(listItem, fmt.Sprintf("%s[%d]", field, index))
+				if err != nil {
+					return nil, err
+				}
+				matchers[index] = matcher
+			}
+			return listGroupMatcher{all: key == "allOf", matchers: matchers}, nil
+		case "equals", "prefix", "glob", "regex":
+			pattern, ok := item.(string)
+			if !ok || len(pattern) == 0 {
+				return nil, newValidationError(field, "must be a non empty string")
+			}
+			return newPatternGroupMatcher(key, pattern, field)
+		default:
+			return nil, newValidationError(field, "is not supported")
+		}
+	}
+	return nil, newValidationError(field, "must be a string or an object with exactly one field")
+}
+
+func newPatternGroupMatcher(kind string, pattern string, field string) (GroupMatcher, error) {
+	switch kind {
+	case "equals":
+		return exactGroupMatcher(pattern), nil
+	case "prefix":
+		return prefixGroupMatcher(pattern), nil
+	case "glob":
+		var expression strings.Builder
+		for _, char := range pattern {
+			switch char {
+			case '*':
+				expression.WriteString(".*")
+			case '?':
+				expression.WriteString(".")
+			default:
+				expression.WriteString(regexp.QuoteMeta(string(char)))
+			}
+		}
+		pattern = expression.String()
+	}
+
+	regex, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, newValidationError(field, "is not a valid pattern - "+err.Error())
+	}
+	return regexGroupMatcher{regex: regex}, nil
+}
+
+type exactGroupMatcher string
+
+func (m exactGroupMatcher) Match(groups []string) bool {
+	for _, group := range groups {
+		if group == string(m) {
+			return true
+		}
+	}
+	return false
+}
+
+type prefixGroupMatcher string
+
+func (m prefixGroupMatcher) Match(groups []string) bool {
+	for _, group := range groups {
+		if strings.HasPrefix(group, string(m)) {
+			return true
+		}
+	}
+	return false
+}
+
+type regexGroupMatcher struct {
+	regex *regexp.Regexp
+}
+
+func (m regexGroupMatcher) Match(groups []string) bool {
+	for _, group := range groups {
+		if m.regex.MatchString(group) {
+			return true
+		}
+	}
+	return false
+}
+
+type listGroupMatcher struct {
+	all      bool
+	matchers []GroupMatcher
+}
+
+func (m listGroupMatcher) Match(groups []string) bool {
+	for _, matcher := range m.matchers {
+		matched := matcher.Match(groups)
+		if m.all && !matched {
+			return false
+		}
+		if !m.all && matched {
+			return true
+		}
+	}
+	return m.all
+}
@@ -19,6 +19,9 @@ package model
 
 import (
 	"fmt"
+	"net/textproto"
+	"sort"
+	"strconv"
 	"strings"
 	"talent-chooser/utils"
 )
@@ -151,5 +154,116 @@ type Pii struct {
 
 //Platform represents the platform entity
 type Platform struct {
-	OS *string
+	OS         *string
+	AppVersion *string
+}
+
+//Attributes represents free-form context attributes. The values are strings, numbers, booleans or lists of them.
+type Attributes map[string]interface{}
+
+//Validate checks if all the attributes values are supported
+func (attributes Attributes) Validate() error {
+	var validationErrors ValidationErrors
+	for name, value := range attributes {
+		field := "attributes." + name
+		if list, ok := value.([]interface{}); ok {
+			for index, item := range list {
+				if !isAttributeScalar(item) {
+					validationErrors = append(validationErrors, ValidationError{Field: fmt.Sprintf("%s[%d]", field, index),
+						Message: "must be a string, a number or a boolean"})
+				}
+			}
+			continue
+		}
+		if !isAttributeScalar(value) {
+			validationErrors = append(validationErrors, ValidationError{Field: field,
+				Message: "must be a string, a number, a boolean or a list of them"})
+		}
+	}
+	if len(validationErrors) > 0 {
+		return validationErrors
+	}
+	return nil
+}
+
+func isAttributeScalar(value interface{}) bool {
+	switch value.(type) {
+	case string, float64, bool:
+		return true
+	}
+	return false
+}
+
+//Headers represents the request headers which are available to the rules, the names are in canonical format
+type Headers map[string]string
+
+//Get gives the header value, the name is case insensitive
+func (headers Headers) Get(name string) (string, bool) {
+	value, ok := headers[textproto.CanonicalMIMEHeaderKey(name)]
+	return value, ok
+}
+
+//PreferredLocale gives the most preferred locale from the Accept-Language header in lower case, for example "es-mx"
+func (headers Headers) PreferredLocale() string {
+	locales := headers.PreferredLocales()
+	if len(locales) == 0 {
+		return ""
+	}
+	return locales[0]
+}
+
+//PreferredLocales gives the locales from the Accept-Language header in lower case ordered by preference
+func (headers Headers) PreferredLocales() []string {
+	acceptLanguage, ok := headers.Get("Accept-Language")
+	if !ok {
+		return nil
+	}
+	return ParseAcceptLanguage(acceptLanguage)
+}
+
+//ParseAcceptLanguage gives the locales of an Accept-Language header value in lower case ordered by preference
+func ParseAcceptLanguage(acceptLanguage string) []string {
+	type weightedLocale struct {
+		locale string
+		q      float64
+	}
+	var locales []weightedLocale
+	for _, item := range strings.Split(acceptLanguage, ",") {
+		parts := strings.Split(item, ";")
+		locale := NormalizeLocale(parts[0])
+		if len(locale) == 0 || locale == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range parts[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				value, err := strconv.ParseFloat(param[2:], 64)
+				if err == nil {
+					q = value
+				}
+			}
+		}
+		if q > 0 {
+			locales = append(locales, weightedLocale{locale: locale, q: q})
+		}
+	}
+	sort.SliceStable(locales, func(i, j int) bool {
+		return locales[i].q > locales[j].q
+	})
+	result := make([]string, len(locales))
+	for index, item := range locales {
+		result[index] = item.locale
+	}
+	return result
+}
+
+//NormalizeLocale gives the locale in lower case with "-" separators, for example "es_MX" gives "es-mx"
+func NormalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+//MatchLocale checks if the locale is the wanted locale or a more specific one, for example "es-mx" matches "es"
+func MatchLocale(locale string, wantedLocale string) bool {
+	return locale == wantedLocale || strings.HasPrefix(locale, wantedLocale+"-")
 }
@@ -0,0 +1,170 @@
+/*
+ *   Copyright (c) 2020 Board of Trustees of the University of Illinois.
+ *   All rights reserved.
+
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+
+ *   http://www.apache.org/licenses/LICENSE-2.0
+
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package model
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+//UIItemStrings represents the localized strings table of an ui item for a locale
+type UIItemStrings struct {
+	UIItemID int               `json:"ui-item-id"`
+	Locale   string            `json:"locale"`
+	Strings  map[string]string `json:"strings"`
+}
+
+//Validate checks if the strings table is valid, the locale must be normalized
+func (uiItemStrings UIItemStrings) Validate() error {
+	var errs ValidationErrors
+	if uiItemStrings.UIItemID <= 0 {
+		errs = append(errs, ValidationError{Field: "ui-item-id", Message: "must be positive"})
+	}
+	if !localePattern.MatchString(uiItemStrings.Locale) {
+		errs = append(errs, ValidationError{Field: "locale", Message: "must be a lower case locale, for example es-mx"})
+	}
+	for key := range uiItemStrings.Strings {
+		if len(strings.TrimSpace(key)) == 0 {
+			errs = append(errs, ValidationError{Field: "strings", Message: "the keys cannot be empty"})
+			break
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+//LocalizedStrings represents the strings tables of an ui item by locale
+type LocalizedStrings map[string]map[string]string
+
+//NewLocalizedStrings groups the strings tables by ui item id
+func NewLocalizedStrings(tables []UIItemStrings) map[int]LocalizedStrings {
+	result := map[int]LocalizedStrings{}
+	for _, table := range tables {
+		localizedStrings, exist := result[table.UIItemID]
+		if !exist {
+			localizedStrings = LocalizedStrings{}
+			result[table.UIItemID] = localizedStrings
+		}
+		localizedStrings[table.Locale] = table.Strings
+	}
+	return result
+}
+
+//Resolve gives every string from the first locale of the chain which has it, the keys which no locale has are not given
+func (localizedStrings LocalizedStrings) Resolve(chain []string) map[string]string {
+	result := map[string]string{}
+	for index := len(chain) - 1; index >= 0; index-- {
+		for key, value := range localizedStrings[chain[index]] {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+//LocaleFallbacks maps a locale to the locale which is tried when a string is missing, before the less specific locale.
+//For example "zh-hk" can fall back to "zh-hant".
+type LocaleFallbacks map[string]string
+
+//Chain gives the locales which are tried in order for the requested locales. Every locale is followed by its fallback and
+//then by the less specific locale, for example "es-mx" is followed by "es". The chain ends with the default locale.
+func (fallbacks LocaleFallbacks) Chain(locales []string, defaultLocale string) []string {
+	var result []string
+	added := map[string]bool{}
+	var add func(locale string)
+	add = func(locale string) {
+		for len(locale) > 0 && !added[locale] {
+			added[locale] = true
+			result = append(result, locale)
+			if fallback, ok := fallbacks[locale]; ok {
+				add(fallback)
+			}
+			index := strings.LastIndex(locale, "-")
+			if index < 0 {
+				break
+			}
+			locale = locale[:index]
+		}
+	}
+	for _, locale := range locales {
+		add(NormalizeLocale(locale))
+	}
+	add(NormalizeLocale(defaultLocale))
+	return result
+}
+
+//MissingStrings represents the keys which an ui item has in other locales but not in the reported locale
+type MissingStrings struct {
+	UIItemID int      `json:"ui-item-id"`
+	Keys     []string `json:"keys"`
+}
+
+//FindMissingStrings gives the missing strings for the provided locales, for every locale which has a strings table
+//if no locales are provided. The fallbacks are not considered.
+func FindMissingStrings(tables []UIItemStrings, wantedLocales []string) map[string][]MissingStrings {
+	byUIItem := NewLocalizedStrings(tables)
+	locales := map[string]bool{}
+	for _, locale := range wantedLocales {
+		locales[NormalizeLocale(locale)] = true
+	}
+	if len(locales) == 0 {
+		for _, table := range tables {
+			locales[table.Locale] = true
+		}
+	}
+
+	uiItemIDs := make([]int, 0, len(byUIItem))
+	for uiItemID := range byUIItem {
+		uiItemIDs = append(uiItemIDs, uiItemID)
+	}
+	sort.Ints(uiItemIDs)
+
+	result := map[string][]MissingStrings{}
+	for locale := range locales {
+		missingList := []MissingStrings{}
+		for _, uiItemID := range uiItemIDs {
+			localizedStrings := byUIItem[uiItemID]
+			keys := map[string]bool{}
+			for _, localeStrings := range localizedStrings {
+				for key := range localeStrings {
+					if _, exist := localizedStrings[locale][key]; !exist {
+						keys[key] = true
+					}
+				}
+			}
+			if len(keys) > 0 {
+				missingList = append(missingList, MissingStrings{UIItemID: uiItemID, Keys: sortedKeys(keys)})
+			}
+		}
+		result[locale] = missingList
+	}
+	return result
+}
+
+func sortedKeys(keys map[string]bool) []string {
+	result := make([]string, 0, len(keys))
+	for key := range keys {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
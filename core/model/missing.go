@@ -0,0 +1,219 @@
+/*
+ *   Copyright (c) 2020 Board of Trustees of the University of Illinois.
+ *   All rights reserved.
+
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+
+ *   http://www.apache.org/licenses/LICENSE-2.0
+
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+//The input parameters which the rules need. The attribute and header inputs are named "attributes.<name>" and "headers.<name>".
+const (
+	InputUser       = "user"
+	InputUserUUID   = "user.uuid"
+	InputUserRoles  = "user.roles"
+	InputAuth       = "auth"
+	InputIlliniCash = "illini_cash"
+	InputPlatformOS = "platform.os"
+	InputAppVersion = "platform.app_version"
+	InputClientIP   = "client_ip"
+	InputLocation   = "location"
+	inputAttributes = "attributes."
+	inputHeaders    = "headers."
+)
+
+//The policies for a rule when some of the inputs which it needs are missing
+const (
+	//OnMissingDefault keeps the rule type behavior for the missing inputs
+	OnMissingDefault = ""
+	//OnMissingMatch matches without evaluating the rule
+	OnMissingMatch = "match"
+	//OnMissingNoMatch does not match without evaluating the rule
+	OnMissingNoMatch = "no-match"
+	//OnMissingError fails the rule evaluation, the ui item is not shown
+	OnMissingError = "error"
+)
+
+//ValidateOnMissing checks if the on missing policy is supported
+func ValidateOnMissing(onMissing string) error {
+	switch onMissing {
+	case OnMissingDefault, OnMissingMatch, OnMissingNoMatch, OnMissingError:
+		return nil
+	}
+	return ValidationErrors{{Field: "on-missing", Message: "must be one of match, no-match and error"}}
+}
+
+//missingFallbackFields contains the rule value fields which give the rule type result when its input is missing
+var missingFallbackFields = map[string]string{"privacy": "anonymous", "rollout": "fallback", "geofence": "fallback"}
+
+//ValidateOnMissingFallback checks that an on missing policy is not combined with a rule value field which gives the result
+//for the missing input - the privacy "anonymous" and the rollout and geofence "fallback" fields, including the ones of the
+//composite leaves. The policy is applied before the rule value is evaluated so such a field would never be used, it applies
+//only with the default policy.
+func ValidateOnMissingFallback(ruleTypeName string, ruleValue interface{}, onMissing string) error {
+	if onMissing == OnMissingDefault {
+		return nil
+	}
+	fields := missingFallbacks(ruleTypeName, ruleValue)
+	if len(fields) == 0 {
+		return nil
+	}
+	var names []string
+	unique := map[string]bool{}
+	for _, field := range fields {
+		if !unique[field] {
+			unique[field] = true
+			names = append(names, field)
+		}
+	}
+	return ValidationErrors{{Field: "on-missing",
+		Message: fmt.Sprintf("cannot be combined with the %s value field which gives the result for the missing input", strings.Join(names, ", "))}}
+}
+
+func missingFallbacks(ruleTypeName string, ruleValue interface{}) []string {
+	if ruleTypeName == "composite" {
+		composite := CompositeRuleType{}
+		key, value, ok := composite.getNode(ruleValue)
+		if !ok {
+			return nil
+		}
+		switch key {
+		case "and", "or":
+			var result []string
+			list, _ := value.([]interface{})
+			for _, item := range list {
+				result = append(result, missingFallbacks("composite", item)...)
+			}
+			return result
+		case "not":
+			return missingFallbacks("composite", value)
+		default:
+			return missingFallbacks(key, value)
+		}
+	}
+
+	field, exist := missingFallbackFields[ruleTypeName]
+	if !exist {
+		return nil
+	}
+	mapData, ok := ruleValue.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if _, exist := mapData[field]; !exist {
+		return nil
+	}
+	return []string{field}
+}
+
+//AttributeInput gives the input name of an attribute
+func AttributeInput(name string) string {
+	return inputAttributes + name
+}
+
+//HeaderInput gives the input name of a header
+func HeaderInput(name string) string {
+	return inputHeaders + name
+}
+
+//MissingInputs gives the inputs which are not provided
+func MissingInputs(inputs []string, inputData InputRulesParameters) []string {
+	var missing []string
+	for _, input := range inputs {
+		if isInputMissing(input, inputData) {
+			missing = append(missing, input)
+		}
+	}
+	return missing
+}
+
+func isInputMissing(input string, inputData InputRulesParameters) bool {
+	user := inputData.User
+	platform := inputData.Platform
+	switch input {
+	case InputUser:
+		return user == nil
+	case InputUserUUID:
+		return user == nil || len(user.UUID) == 0
+	case InputUserRoles:
+		return user == nil || user.Roles == nil
+	case InputAuth:
+		return isAuthMissing(inputData)
+	case InputIlliniCash:
+		return inputData.IlliniCash == nil
+	case InputPlatformOS:
+		return platform == nil || platform.OS == nil
+	case InputAppVersion:
+		return platform == nil || platform.AppVersion == nil
+	case InputClientIP:
+		return inputData.ClientIP == nil
+	case InputLocation:
+		return inputData.Location == nil
+	}
+	if strings.HasPrefix(input, inputAttributes) {
+		value, exist := inputData.Attributes[strings.TrimPrefix(input, inputAttributes)]
+		return !exist || value == nil
+	}
+	if strings.HasPrefix(input, inputHeaders) {
+		_, exist := inputData.Headers.Get(strings.TrimPrefix(input, inputHeaders))
+		return !exist
+	}
+	return false
+}
+
+func isAuthMissing(inputData InputRulesParameters) bool {
+	switch inputData.AuthVersion {
+	case "1":
+		return inputData.Auth == nil
+	case "2":
+		return inputData.AuthV2 == nil
+	case "3":
+		auth := inputData.AuthV3
+		return auth == nil || (auth.Token == nil && auth.User == nil && auth.Card == nil && auth.Pii == nil)
+	}
+	return true
+}
+
+//RuleResult represents the result of a rule evaluation
+type RuleResult struct {
+	Matched bool
+	Missing []string
+	Err     error
+}
+
+//Evaluate evaluates the rule and applies its on missing policy when some of the inputs which it needs are missing.
+//The negate flag applies only to the evaluated rule value.
+//
+//The policy applies to the rule as a whole - the inputs of a composite rule are the union of its leaves inputs, so an input
+//which only one leaf needs applies the policy even if another "or" branch would match. Keep the default policy for a
+//composite rule whose leaves handle the missing inputs themselves.
+func (rule CompiledRule) Evaluate(inputData InputRulesParameters) RuleResult {
+	missing := MissingInputs(rule.Inputs, inputData)
+	if len(missing) > 0 {
+		switch rule.OnMissing {
+		case OnMissingMatch:
+			return RuleResult{Matched: true, Missing: missing}
+		case OnMissingNoMatch:
+			return RuleResult{Matched: false, Missing: missing}
+		case OnMissingError:
+			return RuleResult{Matched: false, Missing: missing,
+				Err: fmt.Errorf("rule %d misses %s", rule.Rule.ID, strings.Join(missing, ", "))}
+		}
+	}
+	return RuleResult{Matched: rule.Predicate(inputData) != rule.Rule.Negate, Missing: missing}
+}
@@ -0,0 +1,132 @@
+/*
+ *   Copyright (c) 2020 Board of Trustees of the University of Illinois.
+ *   All rights reserved.
+
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+
+ *   http://www.apache.org/licenses/LICENSE-2.0
+
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+//ValidatePayloadSchema checks if the content item payload schema can be used for validating the ui items payloads.
+//No schema is valid, it permits any payload.
+func ValidatePayloadSchema(schema map[string]interface{}) error {
+	if schema == nil {
+		return nil
+	}
+	errs := validateSchemaKeywords(schema, "payload-schema")
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+//ValidatePayload validates the ui item payload against the content item payload schema.
+//An ui item without payload is always valid as the schema describes the payload only when there is one.
+func ValidatePayload(schema map[string]interface{}, payload interface{}) error {
+	if schema == nil || payload == nil {
+		return nil
+	}
+	errs := ValidateSchema(schema, payload, "payload")
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+//ValidateUIItemsPayloads validates the payloads of the ui items and their children against the payload schema
+func ValidateUIItemsPayloads(schema map[string]interface{}, uiItems []UIItem) error {
+	errs := validateUIItemsPayloads(schema, uiItems)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateUIItemsPayloads(schema map[string]interface{}, uiItems []UIItem) ValidationErrors {
+	var errs ValidationErrors
+	for _, uiItem := range uiItems {
+		if schema != nil && uiItem.Payload != nil {
+			for _, item := range ValidateSchema(schema, uiItem.Payload, "payload") {
+				errs = append(errs, ValidationError{Field: fmt.Sprintf("ui-items[id=%d].%s", uiItem.ID, item.Field), Message: item.Message})
+			}
+		}
+		errs = append(errs, validateUIItemsPayloads(schema, uiItem.Children)...)
+	}
+	return errs
+}
+
+//validateSchemaKeywords checks the keywords which would make every value invalid - unknown types and bad patterns
+func validateSchemaKeywords(schema map[string]interface{}, field string) ValidationErrors {
+	var errs ValidationErrors
+	if types, exist := schema["type"]; exist {
+		names, ok := types.([]interface{})
+		if !ok {
+			names = []interface{}{types}
+		}
+		for _, item := range names {
+			name, _ := item.(string)
+			if !isSchemaType(name) {
+				errs = append(errs, ValidationError{Field: field + ".type", Message: fmt.Sprintf("%v is not supported", item)})
+			}
+		}
+	}
+	if pattern, exist := schema["pattern"]; exist {
+		value, ok := pattern.(string)
+		if !ok {
+			errs = append(errs, ValidationError{Field: field + ".pattern", Message: "must be a string"})
+		} else if _, err := regexp.Compile(value); err != nil {
+			errs = append(errs, ValidationError{Field: field + ".pattern", Message: err.Error()})
+		}
+	}
+
+	if properties, ok := schema["properties"].(map[string]interface{}); ok {
+		//sort the names for stable errors
+		names := make([]string, 0, len(properties))
+		for name := range properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if propertySchema, ok := properties[name].(map[string]interface{}); ok {
+				errs = append(errs, validateSchemaKeywords(propertySchema, field+".properties."+name)...)
+			}
+		}
+	}
+	for _, keyword := range []string{"items", "additionalProperties", "not"} {
+		if subschema, ok := schema[keyword].(map[string]interface{}); ok {
+			errs = append(errs, validateSchemaKeywords(subschema, field+"."+keyword)...)
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		for i, item := range anyOf {
+			if subschema, ok := item.(map[string]interface{}); ok {
+				errs = append(errs, validateSchemaKeywords(subschema, fmt.Sprintf("%s.anyOf[%d]", field, i))...)
+			}
+		}
+	}
+	return errs
+}
+
+func isSchemaType(name string) bool {
+	switch name {
+	case "object", "array", "string", "number", "integer", "boolean", "null":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+/*
+ *   Copyright (c) 2020 Board of Trustees of the University of Illinois.
+ *   All rights reserved.
+
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+
+ *   http://www.apache.org/licenses/LICENSE-2.0
+
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+//RuleTypeFactory creates a rule type instance for the stored rule type id and name.
+//The created instance gives the validator (ValidData) and the matcher (Match) of the rule type.
+type RuleTypeFactory func(id int, name string) RuleType
+
+//RuleTypeRegistration represents a rule type which the rules engine supports
+type RuleTypeRegistration struct {
+	Name    string
+	Schema  map[string]interface{} //JSON schema of the rule value
+	Factory RuleTypeFactory
+}
+
+var (
+	ruleTypesLock     = &sync.RWMutex{}
+	ruleTypesRegistry = map[string]RuleTypeRegistration{}
+)
+
+func init() {
+	MustRegisterRuleType("roles", `{"type": ["string", "array"], "minLength": 1, "minItems": 1}`,
+		func(id int, name string) RuleType { return NewRolesRuleType(id, name) })
+	MustRegisterRuleType("privacy", `{"anyOf": [{"type": "number", "minimum": 0},
+		{"type": "object", "minProperties": 1, "additionalProperties": false,
+			"properties": {"min": {"type": "integer", "minimum": 0}, "max": {"type": "integer", "minimum": 0}, "anonymous": {"type": "boolean"}}}]}`,
+		func(id int, name string) RuleType { return NewPrivacyRuleType(id, name) })
+	MustRegisterRuleType("auth", `{"type": "object", "minProperties": 1, "additionalProperties": false,
+		"properties": {
+			"shibbolethLoggedIn": {"type": "boolean"}, "loggedIn": {"type": "boolean"}, "phoneLoggedIn": {"type": "boolean"},
+			"eventEditor": {"type": "boolean"}, "memberOfAlias": {"type": "string", "minLength": 1},
+			"shibbolethMemberOf": {"anyOf": [{"type": "string", "minLength": 1},
+				{"type": "object", "minProperties": 1, "maxProperties": 1, "additionalProperties": false,
+					"properties": {"equals": {"type": "string", "minLength": 1}, "prefix": {"type": "string", "minLength": 1},
+						"glob": {"type": "string", "minLength": 1}, "regex": {"type": "string", "minLength": 1},
+						"anyOf": {"type": "array", "minItems": 1}, "allOf": {"type": "array", "minItems": 1}}}]},
+			"iCardNum": {"type": "boolean"}, "iCardLibraryNum": {"type": "boolean"}, "documentType": {"type": "string", "minLength": 1},
+			"operator": {"type": "string", "enum": ["and", "or"]}}}`,
+		func(id int, name string) RuleType { return NewAuthRuleType(id, name) })
+	MustRegisterRuleType("illini_cash", `{"type": "object", "required": ["housingResidenceStatus"], "additionalProperties": false,
+		"properties": {"housingResidenceStatus": {"type": "boolean"}}}`,
+		func(id int, name string) RuleType { return NewIlliniCashRuleType(id, name) })
+	MustRegisterRuleType("enable", `{"type": "boolean"}`,
+		func(id int, name string) RuleType { return NewEnableRuleType(id, name) })
+	MustRegisterRuleType("platform", `{"type": "object", "required": ["os"], "additionalProperties": false,
+		"properties": {"os": {"type": "string", "minLength": 1}}}`,
+		func(id int, name string) RuleType { return NewPlatformRuleType(id, name) })
+	MustRegisterRuleType("composite", `{"type": "object", "minProperties": 1, "maxProperties": 1}`,
+		func(id int, name string) RuleType { return NewCompositeRuleType(id, name) })
+	MustRegisterRuleType("schedule", `{"type": "object", "minProperties": 1, "additionalProperties": false,
+		"properties": {
+			"timezone": {"type": "string", "minLength": 1},
+			"start": {"type": "string", "format": "date-time"}, "end": {"type": "string", "format": "date-time"},
+			"weekly": {"type": "array", "minItems": 1, "items": {"type": "object", "required": ["days"], "additionalProperties": false,
+				"properties": {
+					"days": {"type": "array", "minItems": 1, "items": {"type": "string",
+						"pattern": "(?i)^(sun|mon|tue|wed|thu|fri|sat|sunday|monday|tuesday|wednesday|thursday|friday|saturday)$"}},
+					"from": {"type": "string", "pattern": "^([01][0-9]|2[0-4]):[0-5][0-9]$"},
+					"to": {"type": "string", "pattern": "^([01][0-9]|2[0-4]):[0-5][0-9]$"}}}}}}`,
+		func(id int, name string) RuleType { return NewScheduleRuleType(id, name) })
+	MustRegisterRuleType("app_version", `{"type": "object", "required": ["version"], "additionalProperties": false,
+		"properties": {"version": {"type": "string", "minLength": 1}, "os": {"type": "string", "minLength": 1}}}`,
+		func(id int, name string) RuleType { return NewAppVersionRuleType(id, name) })
+	MustRegisterRuleType("rollout", `{"type": "object", "required": ["percentage", "salt"], "additionalProperties": false,
+		"properties": {"percentage": {"type": "number", "minimum": 0, "maximum": 100}, "salt": {"type": "string", "minLength": 1},
+			"fallback": {"type": "boolean"}}}`,
+		func(id int, name string) RuleType { return NewRolloutRuleType(id, name) })
+	MustRegisterRuleType("attribute", `{"type": "object", "required": ["name", "op"], "additionalProperties": false,
+		"properties": {"name": {"type": "string", "minLength": 1},
+			"op": {"type": "string", "enum": ["eq", "ne", "in", "contains", "gt", "gte", "lt", "lte", "exists"]},
+			"value": {"type": ["string", "number", "boolean", "array"]}, "not": {"type": "boolean"}}}`,
+		func(id int, name string) RuleType { return NewAttributeRuleType(id, name) })
+	MustRegisterRuleType("network", `{"type": "array", "minItems": 1, "items": {"type": "string", "minLength": 1}}`,
+		func(id int, name string) RuleType { return NewNetworkRuleType(id, name) })
+	MustRegisterRuleType("locale", `{"type": "array", "minItems": 1, "items": {"type": "string", "minLength": 1}}`,
+		func(id int, name string) RuleType { return NewLocaleRuleType(id, name) })
+	MustRegisterRuleType("header", `{"type": "object", "required": ["name", "pattern"], "additionalProperties": false,
+		"properties": {"name": {"type": "string", "minLength": 1}, "pattern": {"type": "string"}}}`,
+		func(id int, name string) RuleType { return NewHeaderRuleType(id, name) })
+	MustRegisterRuleType("uuid_list", `{"type": "object", "required": ["list"], "additionalProperties": false,
+		"properties": {"list": {"type": "string", "minLength": 1}, "mode": {"type": "string", "enum": ["allow", "deny"]}}}`,
+		func(id int, name string) RuleType { return NewUUIDListRuleType(id, name) })
+	MustRegisterRuleType("geofence", `{"type": "object", "minProperties": 1, "additionalProperties": false,
+		"properties": {
+			"circle": {"type": "object", "required": ["latitude", "longitude", "radius"], "additionalProperties": false,
+				"properties": {
+					"latitude": {"type": "number", "minimum": -90, "maximum": 90},
+					"longitude": {"type": "number", "minimum": -180, "maximum": 180},
+					"radius": {"type": "number", "exclusiveMinimum": 0}}},
+			"polygon": {"type": "array", "minItems": 3, "items": {"type": "object", "required": ["latitude", "longitude"], "additionalProperties": false,
+				"properties": {
+					"latitude": {"type": "number", "minimum": -90, "maximum": 90},
+					"longitude": {"type": "number", "minimum": -180, "maximum": 180}}}},
+			"fallback": {"type": "boolean"}}}`,
+		func(id int, name string) RuleType { return NewGeofenceRuleType(id, name) })
+}
+
+//RegisterRuleType registers a rule type. It fails if there is already a rule type with the same name.
+func RegisterRuleType(registration RuleTypeRegistration) error {
+	if len(registration.Name) == 0 {
+		return fmt.Errorf("rule type name cannot be empty")
+	}
+	if registration.Factory == nil {
+		return fmt.Errorf("rule type %s has no factory", registration.Name)
+	}
+
+	ruleTypesLock.Lock()
+	defer ruleTypesLock.Unlock()
+
+	if _, exist := ruleTypesRegistry[registration.Name]; exist {
+		return fmt.Errorf("rule type %s is already registered", registration.Name)
+	}
+	ruleTypesRegistry[registration.Name] = registration
+	return nil
+}
+
+//MustRegisterRuleType registers a rule type with a JSON schema text and panics on error
+func MustRegisterRuleType(name string, schema string, factory RuleTypeFactory) {
+	var schemaData map[string]interface{}
+	err := json.Unmarshal([]byte(schema), &schemaData)
+	if err != nil {
+		panic(fmt.Sprintf("invalid schema for rule type %s - %s", name, err.Error()))
+	}
+
+	err = RegisterRuleType(RuleTypeRegistration{Name: name, Schema: schemaData, Factory: factory})
+	if err != nil {
+		panic(err.Error())
+	}
+}
+
+//GetRuleTypeRegistration gives the registration for the rule type name
+func GetRuleTypeRegistration(name string) (*RuleTypeRegistration, bool) {
+	ruleTypesLock.RLock()
+	defer ruleTypesLock.RUnlock()
+
+	registration, exist := ruleTypesRegistry[name]
+	if !exist {
+		return nil, false
+	}
+	return &registration, true
+}
+
+//GetRuleTypeRegistrations gives all registered rule types sorted by name
+func GetRuleTypeRegistrations() []RuleTypeRegistration {
+	ruleTypesLock.RLock()
+	defer ruleTypesLock.RUnlock()
+
+	result := make([]RuleTypeRegistration, 0, len(ruleTypesRegistry))
+	for _, registration := range ruleTypesRegistry {
+		result = append(result, registration)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+//NewRuleType creates a new rule type from the registry. It fails if the rule type is not registered.
+func NewRuleType(id int, name string) (RuleType, error) {
+	registration, exist := GetRuleTypeRegistration(name)
+	if !exist {
+		return nil, fmt.Errorf("rule type %s is not registered", name)
+	}
+	return registration.Factory(id, name), nil
+}
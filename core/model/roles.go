@@ -0,0 +1,243 @@
+/*
+ *   Copyright (c) 2020 Board of Trustees of the University of Illinois.
+ *   All rights reserved.
+
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+
+ *   http://www.apache.org/licenses/LICENSE-2.0
+
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package model
+
+import (
+	"fmt"
+	"strings"
+	"talent-chooser/utils"
+)
+
+//RolesExpression represents a parsed roles expression
+//
+//The grammar follows the usual boolean precedence - NOT binds tighter than AND which binds tighter than OR:
+//	expression = and-term { "OR" and-term }
+//	and-term   = unary { "AND" unary }
+//	unary      = "NOT" unary | role | [ expression ]
+type RolesExpression interface {
+	Eval(roles []string) bool
+	String() string
+}
+
+//RoleExpression represents a single role leaf
+type RoleExpression struct {
+	Role string
+}
+
+//Eval evaluates the expression for the provided roles
+func (e RoleExpression) Eval(roles []string) bool {
+	return utils.Contains(roles, e.Role)
+}
+
+func (e RoleExpression) String() string {
+	return e.Role
+}
+
+//NotExpression represents a negated expression
+type NotExpression struct {
+	Operand RolesExpression
+}
+
+//Eval evaluates the expression for the provided roles
+func (e NotExpression) Eval(roles []string) bool {
+	return !e.Operand.Eval(roles)
+}
+
+func (e NotExpression) String() string {
+	return fmt.Sprintf("NOT %s", e.Operand.String())
+}
+
+//AndExpression represents a conjunction of expressions
+type AndExpression struct {
+	Operands []RolesExpression
+}
+
+//Eval evaluates the expression for the provided roles
+func (e AndExpression) Eval(roles []string) bool {
+	for _, operand := range e.Operands {
+		if !operand.Eval(roles) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e AndExpression) String() string {
+	return joinExpressions(e.Operands, " AND ")
+}
+
+//OrExpression represents a disjunction of expressions
+type OrExpression struct {
+	Operands []RolesExpression
+}
+
+//Eval evaluates the expression for the provided roles
+func (e OrExpression) Eval(roles []string) bool {
+	for _, operand := range e.Operands {
+		if operand.Eval(roles) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e OrExpression) String() string {
+	return joinExpressions(e.Operands, " OR ")
+}
+
+func joinExpressions(list []RolesExpression, separator string) string {
+	items := make([]string, len(list))
+	for i, item := range list {
+		items[i] = item.String()
+	}
+	return fmt.Sprintf("(%s)", strings.Join(items, separator))
+}
+
+//ParseRolesExpression parses a roles rule value - a role name or a list of roles, operators and nested lists
+func ParseRolesExpression(value interface{}) (RolesExpression, error) {
+	switch v := value.(type) {
+	case string:
+		return parseRole(v, "value")
+	case []interface{}:
+		return parseRolesGroup(v, "value")
+	default:
+		return nil, newValidationError("value", fmt.Sprintf("expected a role or a list but got %T", value))
+	}
+}
+
+func parseRole(role string, path string) (RolesExpression, error) {
+	if len(role) == 0 {
+		return nil, newValidationError(path, "role cannot be empty")
+	}
+	if isRolesOperator(role) {
+		return nil, newValidationError(path, "unexpected operator "+role)
+	}
+	return RoleExpression{Role: role}, nil
+}
+
+func parseRolesGroup(list []interface{}, path string) (RolesExpression, error) {
+	if len(list) == 0 {
+		return nil, newValidationError(path, "empty group")
+	}
+	parser := rolesParser{tokens: list, path: path}
+	expression, err := parser.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if !parser.atEnd() {
+		return nil, newValidationError(parser.currentPath(), fmt.Sprintf("expected AND or OR but got %v", parser.current()))
+	}
+	return expression, nil
+}
+
+type rolesParser struct {
+	tokens []interface{}
+	pos    int
+	path   string
+}
+
+func (p *rolesParser) parseExpression() (RolesExpression, error) {
+	first, err := p.parseAndTerm()
+	if err != nil {
+		return nil, err
+	}
+	operands := []RolesExpression{first}
+	for p.acceptOperator("OR") {
+		next, err := p.parseAndTerm()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, next)
+	}
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return OrExpression{Operands: operands}, nil
+}
+
+func (p *rolesParser) parseAndTerm() (RolesExpression, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	operands := []RolesExpression{first}
+	for p.acceptOperator("AND") {
+		next, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, next)
+	}
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return AndExpression{Operands: operands}, nil
+}
+
+func (p *rolesParser) parseUnary() (RolesExpression, error) {
+	if p.acceptOperator("NOT") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return NotExpression{Operand: operand}, nil
+	}
+
+	if p.atEnd() {
+		return nil, newValidationError(p.path, "unexpected end of expression")
+	}
+	path := p.currentPath()
+	token := p.current()
+	p.pos++
+
+	switch v := token.(type) {
+	case string:
+		return parseRole(v, path)
+	case []interface{}:
+		return parseRolesGroup(v, path)
+	default:
+		return nil, newValidationError(path, fmt.Sprintf("expected a role or a list but got %T", token))
+	}
+}
+
+func (p *rolesParser) acceptOperator(operator string) bool {
+	if p.atEnd() {
+		return false
+	}
+	if value, ok := p.current().(string); ok && value == operator {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *rolesParser) atEnd() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *rolesParser) current() interface{} {
+	return p.tokens[p.pos]
+}
+
+func (p *rolesParser) currentPath() string {
+	return fmt.Sprintf("%s[%d]", p.path, p.pos)
+}
+
+func isRolesOperator(value string) bool {
+	return value == "AND" || value == "OR" || value == "NOT"
+}
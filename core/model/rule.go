@@ -18,15 +18,27 @@
 package model
 
 import (
-	"log"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/textproto"
+	"regexp"
+	"sort"
+	"strings"
 	"talent-chooser/utils"
+	"time"
 )
 
 //Rule represents rule entity
 type Rule struct {
-	ID       int         `json:"id"`
-	RuleType RuleType    `json:"rule-type"`
-	Value    interface{} `json:"value"`
+	ID        int         `json:"id"`
+	Name      string      `json:"name,omitempty"` //only named rules have a name, they can be attached to many ui items
+	RuleType  RuleType    `json:"rule-type"`
+	Value     interface{} `json:"value"`
+	OnMissing string      `json:"on-missing"` //match, no-match, error or empty for the rule type behavior
+	Negate    bool        `json:"negate"`     //negates the rule value result, it does not negate the on missing policy result
 }
 
 //Match matches if the input paramters match with the rule value
@@ -34,12 +46,35 @@ func (role Rule) Match(inputData InputRulesParameters) bool {
 	return role.RuleType.Match(inputData, role.Value)
 }
 
+//Compile compiles the rule value to a predicate
+func (role Rule) Compile() (Predicate, error) {
+	if role.RuleType == nil {
+		return nil, fmt.Errorf("rule %d has no rule type", role.ID)
+	}
+	return role.RuleType.Compile(role.Value)
+}
+
+//Predicate is a compiled rule value which is ready to be evaluated against the input parameters
+type Predicate func(inputData InputRulesParameters) bool
+
 //RuleType represents rule type interface
 type RuleType interface {
 	GetID() int
 	GetName() string
-	ValidData(data interface{}) bool
+	ValidData(data interface{}) error
+	Compile(ruleValue interface{}) (Predicate, error)
 	Match(inputData InputRulesParameters, ruleValue interface{}) bool
+	//Inputs gives the input parameters which the rule value needs, the on missing policy of the rule applies when they are missing
+	Inputs(ruleValue interface{}) []string
+}
+
+//matchCompiled compiles the rule value and evaluates it, a value which cannot be compiled does not match
+func matchCompiled(ruleType RuleType, inputData InputRulesParameters, ruleValue interface{}) bool {
+	predicate, err := ruleType.Compile(ruleValue)
+	if err != nil {
+		return false
+	}
+	return predicate(inputData)
 }
 
 //InputRulesParameters wraps all input rules parameters to be passed on the match function
@@ -53,6 +88,13 @@ type InputRulesParameters struct {
 
 	IlliniCash *IlliniCash
 	Platform   *Platform
+	Attributes Attributes
+	ClientIP   net.IP
+	Headers    Headers
+	Location   *Location
+
+	GroupAliases GroupAliases
+	UUIDLists    UUIDLists
 }
 
 //RolesRuleType represents roles rule type entity
@@ -72,61 +114,34 @@ func (rr RolesRuleType) GetName() string {
 }
 
 //ValidData checks if the input data is valid for the rule type
-func (rr RolesRuleType) ValidData(data interface{}) bool {
-	if data == nil {
-		return false
+func (rr RolesRuleType) ValidData(data interface{}) error {
+	_, err := ParseRolesExpression(data)
+	return err
+}
+
+//Compile compiles the rule value to a predicate
+func (rr RolesRuleType) Compile(ruleValue interface{}) (Predicate, error) {
+	expression, err := ParseRolesExpression(ruleValue)
+	if err != nil {
+		return nil, err
 	}
-	return true
+	return func(inputData InputRulesParameters) bool {
+		user := inputData.User
+		if user == nil || user.Roles == nil {
+			return expression.Eval(nil)
+		}
+		return expression.Eval(*user.Roles)
+	}, nil
 }
 
 //Match match if the input paramters match with the value rules
 func (rr RolesRuleType) Match(inputData InputRulesParameters, ruleValue interface{}) bool {
-	return rr.match(ruleValue, inputData.User)
+	return matchCompiled(rr, inputData, ruleValue)
 }
 
-func (rr RolesRuleType) match(roleRule interface{}, user *User) bool {
-
-	if utils.IsString(roleRule) {
-		return user != nil && user.Roles != nil && utils.Contains(*user.Roles, roleRule.(string))
-	}
-
-	if utils.IsSlice(roleRule) {
-		list := roleRule.([]interface{})
-		length := len(list)
-
-		if length == 1 {
-			return rr.match(list[0], user)
-		}
-
-		if length == 2 {
-			operation := list[0]
-			argument := list[1]
-			if utils.IsString(operation) {
-				if operation == "NOT" {
-					return !rr.match(argument, user)
-				}
-			}
-		}
-
-		if length > 2 {
-			result := rr.match(list[0], user)
-			for index := 1; (index + 1) < length; index += 2 {
-				operation := list[index]
-				argument := list[index+1]
-				if utils.IsString(operation) {
-					opr := operation.(string)
-					if opr == "AND" {
-						result = result && rr.match(argument, user)
-					} else if opr == "OR" {
-						result = result || rr.match(argument, user)
-					}
-				}
-			}
-			return result
-		}
-	}
-
-	return true // allow everything that is not defined or we do not understand
+//Inputs gives the input parameters which the rule value needs
+func (rr RolesRuleType) Inputs(ruleValue interface{}) []string {
+	return []string{InputUserRoles}
 }
 
 //NewRolesRuleType creates roles rule type instance
@@ -135,6 +150,10 @@ func NewRolesRuleType(id int, name string) RolesRuleType {
 }
 
 //PrivacyRuleType represents privacy rule type entity
+//
+//value 3 - the minimum privacy level, or { "min": 2, "max": 4, "anonymous": false } where every field is optional
+//but at least one is required. The anonymous field gives the result when there is no user and it is false if not provided,
+//it cannot be combined with an on missing policy of the rule.
 type PrivacyRuleType struct {
 	ID   int    `json:"id"`
 	Name string `json:"name"`
@@ -151,27 +170,72 @@ func (rr PrivacyRuleType) GetName() string {
 }
 
 //ValidData checks if the input data is valid for the rule type
-func (rr PrivacyRuleType) ValidData(data interface{}) bool {
-	if data == nil {
-		return false
-	}
-	_, ok := data.(float64)
-	if !ok {
-		return false
+func (rr PrivacyRuleType) ValidData(data interface{}) error {
+	_, err := rr.Compile(data)
+	return err
+}
+
+//Compile compiles the rule value to a predicate
+func (rr PrivacyRuleType) Compile(ruleValue interface{}) (Predicate, error) {
+	var minLevel, maxLevel *float64
+	anonymous := false
+
+	switch value := ruleValue.(type) {
+	case float64:
+		//wanted min level, this form has always been truncated to an integer level
+		level := float64(int(value))
+		minLevel = &level
+	case map[string]interface{}:
+		if len(value) == 0 {
+			return nil, errors.New("at least one of min, max and anonymous is required")
+		}
+		for key, item := range value {
+			switch key {
+			case "min", "max":
+				level, ok := item.(float64)
+				if !ok || level != float64(int(level)) {
+					return nil, newValidationError("value."+key, "must be an integer")
+				}
+				if key == "min" {
+					minLevel = &level
+				} else {
+					maxLevel = &level
+				}
+			case "anonymous":
+				flag, ok := item.(bool)
+				if !ok {
+					return nil, newValidationError("value.anonymous", "must be a boolean")
+				}
+				anonymous = flag
+			default:
+				return nil, newValidationError("value."+key, "is not supported")
+			}
+		}
+		if minLevel != nil && maxLevel != nil && *minLevel > *maxLevel {
+			return nil, newValidationError("value.max", "must be greater than or equal to min")
+		}
+	default:
+		return nil, errors.New("must be a number or an object")
 	}
-	return true
+
+	return func(inputData InputRulesParameters) bool {
+		user := inputData.User
+		if user == nil {
+			return anonymous
+		}
+		level := float64(user.PrivacySettings.Level)
+		return (minLevel == nil || level >= *minLevel) && (maxLevel == nil || level <= *maxLevel)
+	}, nil
 }
 
 //Match match if the input paramters match with the value rules
 func (rr PrivacyRuleType) Match(inputData InputRulesParameters, ruleValue interface{}) bool {
-	//wanted min level
-	minLevel := ruleValue.(float64)
+	return matchCompiled(rr, inputData, ruleValue)
+}
 
-	user := inputData.User
-	if user == nil {
-		return false // it does not match
-	}
-	return user.PrivacySettings.Level >= int(minLevel)
+//Inputs gives the input parameters which the rule value needs
+func (rr PrivacyRuleType) Inputs(ruleValue interface{}) []string {
+	return []string{InputUser}
 }
 
 //NewPrivacyRuleType creates privacy rule type instance
@@ -179,7 +243,27 @@ func NewPrivacyRuleType(id int, name string) PrivacyRuleType {
 	return PrivacyRuleType{ID: id, Name: name}
 }
 
+//authConditions contains the supported auth rule value conditions
+var authConditions = map[string]bool{"shibbolethLoggedIn": true, "loggedIn": true, "phoneLoggedIn": true, "eventEditor": true,
+	"shibbolethMemberOf": true, "memberOfAlias": true, "iCardNum": true, "iCardLibraryNum": true, "documentType": true}
+
+//authTextConditions contains the auth rule value conditions which have string values, the others have boolean values
+//shibbolethMemberOf has a group matcher value
+var authTextConditions = map[string]bool{"memberOfAlias": true, "documentType": true}
+
+//authCondition is a compiled auth rule value condition
+type authCondition struct {
+	key    string
+	flag   bool
+	text   string
+	groups GroupMatcher
+}
+
 //AuthRuleType represents auth rule type entity
+//
+//The conditions are combined with the operator for every auth version. V1 supports only shibbolethLoggedIn and V2 does not
+//support iCardNum, iCardLibraryNum and documentType. A condition which the auth version does not support does not match,
+//so it fails an "and" rule and it does not count for an "or" rule.
 type AuthRuleType struct {
 	ID   int    `json:"id"`
 	Name string `json:"name"`
@@ -196,48 +280,107 @@ func (rr AuthRuleType) GetName() string {
 }
 
 //ValidData checks if the input data is valid for the rule type
-func (rr AuthRuleType) ValidData(data interface{}) bool {
-	if data == nil {
-		return false
-	}
-	_, ok := data.(map[string]interface{})
+func (rr AuthRuleType) ValidData(data interface{}) error {
+	mapData, ok := data.(map[string]interface{})
 	if !ok {
-		return false
+		return errors.New("must be an object")
+	}
+
+	var validationErrors ValidationErrors
+	for key := range mapData {
+		if key != "operator" && !authConditions[key] {
+			validationErrors = append(validationErrors, ValidationError{Field: "value." + key, Message: "is not supported"})
+		}
 	}
-	return true
+	if len(validationErrors) > 0 {
+		return validationErrors
+	}
+	_, err := rr.Compile(data)
+	return err
 }
 
-//Match match if the input paramters match with the value rules
-func (rr AuthRuleType) Match(inputData InputRulesParameters, ruleValue interface{}) bool {
-	version := inputData.AuthVersion
-	if version == "1" {
-		return rr.matchV1(inputData, ruleValue)
+//Compile compiles the rule value to a predicate
+func (rr AuthRuleType) Compile(ruleValue interface{}) (Predicate, error) {
+	mapData, ok := ruleValue.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("must be an object")
 	}
-	if version == "2" {
-		return rr.matchV2(inputData, ruleValue)
+	operator := "and"
+	if value, exist := mapData["operator"]; exist {
+		operator, _ = value.(string)
+		if operator != "and" && operator != "or" {
+			return nil, newValidationError("value.operator", "must be and or or")
+		}
 	}
-	if version == "3" {
-		return rr.matchV3(inputData, ruleValue)
+
+	//keep the conditions in a stable order
+	var keys []string
+	for key := range mapData {
+		if authConditions[key] && mapData[key] != nil {
+			keys = append(keys, key)
+		}
 	}
-	return false
+	sort.Strings(keys)
+	if len(keys) == 0 {
+		return nil, errors.New("at least one condition is required") //not supported
+	}
+	conditions := make([]authCondition, len(keys))
+	for index, key := range keys {
+		condition := authCondition{key: key}
+		if key == "shibbolethMemberOf" {
+			groups, err := ParseGroupMatcher(mapData[key], "value."+key)
+			if err != nil {
+				return nil, err
+			}
+			condition.groups = groups
+		} else if authTextConditions[key] {
+			text, ok := mapData[key].(string)
+			if !ok || len(text) == 0 {
+				return nil, newValidationError("value."+key, "must be a non empty string")
+			}
+			condition.text = text
+		} else {
+			flag, ok := mapData[key].(bool)
+			if !ok {
+				return nil, newValidationError("value."+key, "must be a boolean")
+			}
+			condition.flag = flag
+		}
+		conditions[index] = condition
+	}
+
+	return func(inputData InputRulesParameters) bool {
+		switch inputData.AuthVersion {
+		case "1":
+			return rr.matchConditions(operator, conditions, inputData, rr.checkV1)
+		case "2":
+			return rr.matchConditions(operator, conditions, inputData, rr.checkV2)
+		case "3":
+			return rr.matchConditions(operator, conditions, inputData, rr.checkV3)
+		}
+		return false
+	}, nil
 }
 
-func (rr AuthRuleType) matchV1(inputData InputRulesParameters, ruleValue interface{}) bool {
-	// supported checks
-	//{ "shibbolethLoggedIn": true }
-	mapData := ruleValue.(map[string]interface{})
+//Match match if the input paramters match with the value rules
+func (rr AuthRuleType) Match(inputData InputRulesParameters, ruleValue interface{}) bool {
+	return matchCompiled(rr, inputData, ruleValue)
+}
 
-	//it supports only shibbolethLoggedIn check, return false otherwise
-	shibboVal := mapData["shibbolethLoggedIn"]
-	if shibboVal == nil {
-		return false
+//Inputs gives the input parameters which the rule value needs
+func (rr AuthRuleType) Inputs(ruleValue interface{}) []string {
+	return []string{InputAuth}
+}
+
+func (rr AuthRuleType) checkV1(inputData InputRulesParameters, condition authCondition) bool {
+	if condition.key != "shibbolethLoggedIn" {
+		return false //not supported by V1
 	}
 
 	authData := inputData.Auth
 
 	//wanted
-	shibbolethLoggedIn := mapData["shibbolethLoggedIn"].(bool)
-	if shibbolethLoggedIn {
+	if condition.flag {
 		//if we want to be true
 
 		if authData == nil {
@@ -253,122 +396,66 @@ func (rr AuthRuleType) matchV1(inputData InputRulesParameters, ruleValue interfa
 	return authData.UIuceduUIN == nil
 }
 
-func (rr AuthRuleType) matchV2(inputData InputRulesParameters, ruleValue interface{}) bool {
-	mapData := ruleValue.(map[string]interface{})
-
-	//it supports the following checks
-	shibbolethLoggedIn := mapData["shibbolethLoggedIn"]
-	loggedIn := mapData["loggedIn"]
-	phoneLoggedIn := mapData["phoneLoggedIn"]
-	eventEditor := mapData["eventEditor"]
-	shibbolethMemberOf := mapData["shibbolethMemberOf"]
-
-	if shibbolethLoggedIn == nil && loggedIn == nil && phoneLoggedIn == nil && eventEditor == nil && shibbolethMemberOf == nil {
-		return false //not supported
-	}
-
-	if shibbolethLoggedIn != nil {
-		wantedValue := shibbolethLoggedIn.(bool)
-		value := rr.isShibbolethLoggedIn(inputData.AuthV2)
-		return value == wantedValue
-	}
-
-	if loggedIn != nil {
-		wantedValue := loggedIn.(bool)
-		value := rr.isLoggedIn(inputData.AuthV2)
-		return value == wantedValue
-	}
-
-	if phoneLoggedIn != nil {
-		wantedValue := phoneLoggedIn.(bool)
-		value := rr.isPhoneLoggedIn(inputData.AuthV2)
-		return value == wantedValue
-	}
-
-	if eventEditor != nil {
-		wantedValue := eventEditor.(bool)
-		value := rr.isEventEditor(inputData.AuthV2)
-		return value == wantedValue
-	}
+func (rr AuthRuleType) checkV2(inputData InputRulesParameters, condition authCondition) bool {
+	auth := inputData.AuthV2
+	switch condition.key {
+	case "shibbolethLoggedIn":
+		return rr.isShibbolethLoggedIn(auth) == condition.flag
+	case "loggedIn":
+		return rr.isLoggedIn(auth) == condition.flag
+	case "phoneLoggedIn":
+		return rr.isPhoneLoggedIn(auth) == condition.flag
+	case "eventEditor":
+		return rr.isEventEditor(auth, inputData.GroupAliases) == condition.flag
+	case "shibbolethMemberOf":
+		return rr.matchMemberOf(auth, condition.groups)
+	case "memberOfAlias":
+		group, exist := inputData.GroupAliases.Group(condition.text)
+		return exist && rr.shibbolethMemberOf(auth, group)
+	}
+	return false //not supported by V2
+}
 
-	if shibbolethMemberOf != nil {
-		wantedValue := shibbolethMemberOf.(string)
-		return rr.shibbolethMemberOf(inputData.AuthV2, wantedValue)
+func (rr AuthRuleType) checkV3(inputData InputRulesParameters, condition authCondition) bool {
+	auth := inputData.AuthV3
+	switch condition.key {
+	case "shibbolethLoggedIn":
+		return rr.isShibbolethLoggedInV3(auth) == condition.flag
+	case "loggedIn":
+		return rr.isLoggedInV3(auth) == condition.flag
+	case "phoneLoggedIn":
+		return rr.isPhoneLoggedInV3(auth) == condition.flag
+	case "eventEditor":
+		return rr.isEventEditorV3(auth, inputData.GroupAliases) == condition.flag
+	case "shibbolethMemberOf":
+		return rr.matchMemberOfV3(auth, condition.groups)
+	case "memberOfAlias":
+		group, exist := inputData.GroupAliases.Group(condition.text)
+		return exist && rr.shibbolethMemberOfV3(auth, group)
+	case "iCardNum":
+		return rr.isICardNumV3(auth) == condition.flag
+	case "iCardLibraryNum":
+		return rr.isICardLibraryNumV3(auth) == condition.flag
+	case "documentType":
+		value := rr.getPiiDocumentType(auth)
+		return value != nil && *value == condition.text
 	}
 	return false
 }
 
-func (rr AuthRuleType) matchV3(inputData InputRulesParameters, ruleValue interface{}) bool {
-	mapData := ruleValue.(map[string]interface{})
-
-	//it supports the following checks
-	shibbolethLoggedIn := mapData["shibbolethLoggedIn"]
-	loggedIn := mapData["loggedIn"]
-	phoneLoggedIn := mapData["phoneLoggedIn"]
-	eventEditor := mapData["eventEditor"]
-	shibbolethMemberOf := mapData["shibbolethMemberOf"]
-	iCardNum := mapData["iCardNum"]
-	iCardLibraryNum := mapData["iCardLibraryNum"]
-	documentType := mapData["documentType"]
-
-	if shibbolethLoggedIn == nil && loggedIn == nil && phoneLoggedIn == nil && eventEditor == nil &&
-		shibbolethMemberOf == nil && iCardNum == nil && iCardLibraryNum == nil && documentType == nil {
-		return false //not supported
-	}
-
-	if shibbolethLoggedIn != nil {
-		wantedValue := shibbolethLoggedIn.(bool)
-		value := rr.isShibbolethLoggedInV3(inputData.AuthV3)
-		return value == wantedValue
-	}
-
-	if loggedIn != nil {
-		wantedValue := loggedIn.(bool)
-		value := rr.isLoggedInV3(inputData.AuthV3)
-		return value == wantedValue
-	}
-
-	if phoneLoggedIn != nil {
-		wantedValue := phoneLoggedIn.(bool)
-		value := rr.isPhoneLoggedInV3(inputData.AuthV3)
-		return value == wantedValue
-	}
-
-	if eventEditor != nil {
-		wantedValue := eventEditor.(bool)
-		value := rr.isEventEditorV3(inputData.AuthV3)
-		return value == wantedValue
-	}
-
-	if shibbolethMemberOf != nil {
-		wantedValue := shibbolethMemberOf.(string)
-		return rr.shibbolethMemberOfV3(inputData.AuthV3, wantedValue)
-	}
-
-	if iCardNum != nil {
-		wantedValue := iCardNum.(bool)
-		value := rr.isICardNumV3(inputData.AuthV3)
-		return value == wantedValue
-
-	}
-
-	if iCardLibraryNum != nil {
-		wantedValue := iCardLibraryNum.(bool)
-		value := rr.isICardLibraryNumV3(inputData.AuthV3)
-		return value == wantedValue
-	}
-
-	if documentType != nil {
-		wantedValue := documentType.(string)
-		log.Printf("wanted:%s", wantedValue)
-		value := rr.getPiiDocumentType(inputData.AuthV3)
-		if value == nil {
+//matchConditions evaluates the conditions and combines the results with the operator - "and" or "or"
+func (rr AuthRuleType) matchConditions(operator string, conditions []authCondition, inputData InputRulesParameters,
+	check func(inputData InputRulesParameters, condition authCondition) bool) bool {
+	for _, condition := range conditions {
+		matched := check(inputData, condition)
+		if operator == "or" && matched {
+			return true
+		}
+		if operator == "and" && !matched {
 			return false
 		}
-		return *value == wantedValue
 	}
-
-	return false
+	return operator == "and"
 }
 
 func (rr AuthRuleType) isLoggedIn(auth *AuthV2) bool {
@@ -433,44 +520,20 @@ func (rr AuthRuleType) isPhoneLoggedInV3(auth *AuthV3) bool {
 	return false
 }
 
-func (rr AuthRuleType) isEventEditor(auth *AuthV2) bool {
-	if auth == nil {
-		return false
-	}
-	userInfo := auth.UserInfo
-	if userInfo == nil {
-		return false
-	}
-	memberOfList := userInfo.UIuceduIsMemberOf
-	if memberOfList == nil {
+func (rr AuthRuleType) isEventEditor(auth *AuthV2, groupAliases GroupAliases) bool {
+	group, exist := groupAliases.Group(EventEditorsGroupAlias)
+	if !exist {
 		return false
 	}
-	for _, item := range *memberOfList {
-		if item == "urn:mace:uiuc.edu:urbana:authman:app-rokwire-service-policy-rokwire event approvers" {
-			return true
-		}
-	}
-	return false
+	return rr.shibbolethMemberOf(auth, group)
 }
 
-func (rr AuthRuleType) isEventEditorV3(auth *AuthV3) bool {
-	if auth == nil {
-		return false
-	}
-	userInfo := auth.User
-	if userInfo == nil {
-		return false
-	}
-	memberOfList := userInfo.UIuceduIsMemberOf
-	if memberOfList == nil {
+func (rr AuthRuleType) isEventEditorV3(auth *AuthV3, groupAliases GroupAliases) bool {
+	group, exist := groupAliases.Group(EventEditorsGroupAlias)
+	if !exist {
 		return false
 	}
-	for _, item := range *memberOfList {
-		if item == "urn:mace:uiuc.edu:urbana:authman:app-rokwire-service-policy-rokwire event approvers" {
-			return true
-		}
-	}
-	return false
+	return rr.shibbolethMemberOfV3(auth, group)
 }
 
 func (rr AuthRuleType) shibbolethMemberOf(auth *AuthV2, wantedValue string) bool {
@@ -513,6 +576,20 @@ func (rr AuthRuleType) shibbolethMemberOfV3(auth *AuthV3, wantedValue string) bo
 	return false
 }
 
+func (rr AuthRuleType) matchMemberOf(auth *AuthV2, groups GroupMatcher) bool {
+	if auth == nil || auth.UserInfo == nil || auth.UserInfo.UIuceduIsMemberOf == nil {
+		return false
+	}
+	return groups.Match(*auth.UserInfo.UIuceduIsMemberOf)
+}
+
+func (rr AuthRuleType) matchMemberOfV3(auth *AuthV3, groups GroupMatcher) bool {
+	if auth == nil || auth.User == nil || auth.User.UIuceduIsMemberOf == nil {
+		return false
+	}
+	return groups.Match(*auth.User.UIuceduIsMemberOf)
+}
+
 func (rr AuthRuleType) isICardNumV3(auth *AuthV3) bool {
 	if auth == nil {
 		return false
@@ -576,41 +653,52 @@ func (rr IlliniCashRuleType) GetName() string {
 }
 
 //ValidData checks if the input data is valid for the rule type
-func (rr IlliniCashRuleType) ValidData(data interface{}) bool {
-	if data == nil {
-		return false
-	}
-	_, ok := data.(map[string]interface{})
-	if !ok {
-		return false
-	}
-	return true
+func (rr IlliniCashRuleType) ValidData(data interface{}) error {
+	_, err := rr.Compile(data)
+	return err
 }
 
-//Match match if the input paramters match with the value rules
-func (rr IlliniCashRuleType) Match(inputData InputRulesParameters, ruleValue interface{}) bool {
+//Compile compiles the rule value to a predicate
+func (rr IlliniCashRuleType) Compile(ruleValue interface{}) (Predicate, error) {
 	//value { "housingResidenceStatus" : true }
 
-	mapData := ruleValue.(map[string]interface{})
-
-	illiniCashInputData := inputData.IlliniCash
+	mapData, ok := ruleValue.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("must be an object")
+	}
 
 	//wanted
-	housingResidenceStatus := mapData["housingResidenceStatus"].(bool)
-	if housingResidenceStatus {
-		//if we want to be true
+	housingResidenceStatus, ok := mapData["housingResidenceStatus"].(bool)
+	if !ok {
+		return nil, newValidationError("value.housingResidenceStatus", "must be a boolean")
+	}
+	return func(inputData InputRulesParameters) bool {
+		illiniCashInputData := inputData.IlliniCash
+		if housingResidenceStatus {
+			//if we want to be true
+
+			if illiniCashInputData == nil {
+				return false //it does not matches
+			}
+			return illiniCashInputData.HousingResidentStatus == true
+		}
+		//if we want to be false
 
 		if illiniCashInputData == nil {
-			return false //it does not matches
+			return true //it matches
 		}
-		return illiniCashInputData.HousingResidentStatus == true
-	}
-	//if we want to be false
+		return illiniCashInputData.HousingResidentStatus == false
+	}, nil
+}
 
-	if illiniCashInputData == nil {
-		return true //it matches
-	}
-	return illiniCashInputData.HousingResidentStatus == false
+//Match match if the input paramters match with the value rules
+func (rr IlliniCashRuleType) Match(inputData InputRulesParameters, ruleValue interface{}) bool {
+	return matchCompiled(rr, inputData, ruleValue)
+}
+
+//Inputs gives the input parameters which the rule value needs
+func (rr IlliniCashRuleType) Inputs(ruleValue interface{}) []string {
+	return []string{InputIlliniCash}
 }
 
 //NewIlliniCashRuleType creates privacy rule type instance
@@ -635,21 +723,34 @@ func (rr EnableRuleType) GetName() string {
 }
 
 //ValidData checks if the input data is valid for the rule type
-func (rr EnableRuleType) ValidData(data interface{}) bool {
-	if data == nil {
-		return false
-	}
+func (rr EnableRuleType) ValidData(data interface{}) error {
 	_, ok := data.(bool)
 	if !ok {
-		return false
+		return errors.New("must be a boolean")
+	}
+	return nil
+}
+
+//Compile compiles the rule value to a predicate
+func (rr EnableRuleType) Compile(ruleValue interface{}) (Predicate, error) {
+	enabled, ok := ruleValue.(bool)
+	if !ok {
+		return nil, errors.New("must be a boolean")
 	}
-	return true
+	//it does not rely on any input parameters
+	return func(inputData InputRulesParameters) bool {
+		return enabled
+	}, nil
 }
 
 //Match match if the input paramters match with the value rules
 func (rr EnableRuleType) Match(inputData InputRulesParameters, ruleValue interface{}) bool {
-	//it does not rely on any input parameters
-	return ruleValue.(bool)
+	return matchCompiled(rr, inputData, ruleValue)
+}
+
+//Inputs gives the input parameters which the rule value needs
+func (rr EnableRuleType) Inputs(ruleValue interface{}) []string {
+	return nil
 }
 
 //NewEnableRuleType creates privacy rule instance
@@ -674,39 +775,40 @@ func (rr PlatformRuleType) GetName() string {
 }
 
 //ValidData checks if the input data is valid for the rule type
-func (rr PlatformRuleType) ValidData(data interface{}) bool {
-	if data == nil {
-		return false
-	}
-	_, ok := data.(map[string]interface{})
-	if !ok {
-		return false
-	}
-	return true
+func (rr PlatformRuleType) ValidData(data interface{}) error {
+	_, err := rr.Compile(data)
+	return err
 }
 
-//Match match if the input paramters match with the value rules
-func (rr PlatformRuleType) Match(inputData InputRulesParameters, ruleValue interface{}) bool {
-	mapData := ruleValue.(map[string]interface{})
-
-	//it supports the following checks
-	os := mapData["os"]
-
-	if os == nil {
-		return false //not supported
+//Compile compiles the rule value to a predicate
+func (rr PlatformRuleType) Compile(ruleValue interface{}) (Predicate, error) {
+	mapData, ok := ruleValue.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("must be an object")
 	}
 
-	if os != nil {
+	//it supports only the os check
+	wantedValue, ok := mapData["os"].(string)
+	if !ok {
+		return nil, newValidationError("value.os", "must be a string")
+	}
+	return func(inputData InputRulesParameters) bool {
 		value := rr.getOSValue(inputData.Platform)
 		if value == nil {
 			return false
 		}
-
-		wantedValue := os.(string)
 		return *value == wantedValue
-	}
+	}, nil
+}
 
-	return false
+//Match match if the input paramters match with the value rules
+func (rr PlatformRuleType) Match(inputData InputRulesParameters, ruleValue interface{}) bool {
+	return matchCompiled(rr, inputData, ruleValue)
+}
+
+//Inputs gives the input parameters which the rule value needs
+func (rr PlatformRuleType) Inputs(ruleValue interface{}) []string {
+	return []string{InputPlatformOS}
 }
 
 func (rr PlatformRuleType) getOSValue(platform *Platform) *string {
@@ -721,22 +823,1216 @@ func NewPlatformRuleType(id int, name string) PlatformRuleType {
 	return PlatformRuleType{ID: id, Name: name}
 }
 
-//NewRuleType creates a new rule type
-func NewRuleType(id int, name string) *RuleType {
-	var ruleType RuleType
-	switch name {
-	case "roles":
-		ruleType = NewRolesRuleType(id, name)
-	case "privacy":
-		ruleType = NewPrivacyRuleType(id, name)
-	case "auth":
-		ruleType = NewAuthRuleType(id, name)
-	case "illini_cash":
-		ruleType = NewIlliniCashRuleType(id, name)
-	case "enable":
-		ruleType = NewEnableRuleType(id, name)
-	case "platform":
-		ruleType = NewPlatformRuleType(id, name)
-	}
-	return &ruleType
+//AppVersionRuleType represents app version rule type entity
+//
+//value { "version": ">=3.1.0 <4", "os": "ios" } where os is optional
+type AppVersionRuleType struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+//GetID gives the rule id
+func (rr AppVersionRuleType) GetID() int {
+	return rr.ID
+}
+
+//GetName gives the rule name
+func (rr AppVersionRuleType) GetName() string {
+	return rr.Name
+}
+
+//ValidData checks if the input data is valid for the rule type
+func (rr AppVersionRuleType) ValidData(data interface{}) error {
+	mapData, ok := data.(map[string]interface{})
+	if !ok {
+		return errors.New("must be an object")
+	}
+	version, ok := mapData["version"].(string)
+	if !ok {
+		return newValidationError("value.version", "must be a string")
+	}
+	_, err := utils.ParseSemConstraint(version)
+	if err != nil {
+		return newValidationError("value.version", err.Error())
+	}
+	return nil
+}
+
+//Compile compiles the rule value to a predicate
+func (rr AppVersionRuleType) Compile(ruleValue interface{}) (Predicate, error) {
+	mapData, ok := ruleValue.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("must be an object")
+	}
+	constraintValue, ok := mapData["version"].(string)
+	if !ok {
+		return nil, newValidationError("value.version", "must be a string")
+	}
+	constraint, err := utils.ParseSemConstraint(constraintValue)
+	if err != nil {
+		return nil, newValidationError("value.version", err.Error())
+	}
+	var wantedOS *string
+	if os, exist := mapData["os"]; exist {
+		value, ok := os.(string)
+		if !ok {
+			return nil, newValidationError("value.os", "must be a string")
+		}
+		wantedOS = &value
+	}
+
+	return func(inputData InputRulesParameters) bool {
+		platform := inputData.Platform
+		if platform == nil || platform.AppVersion == nil {
+			return false
+		}
+		if wantedOS != nil && (platform.OS == nil || *platform.OS != *wantedOS) {
+			return false
+		}
+		version, err := utils.ParseSemVersion(*platform.AppVersion)
+		if err != nil {
+			return false //we cannot say anything about an app which sends a broken version
+		}
+		return constraint.Check(*version)
+	}, nil
+}
+
+//Match match if the input paramters match with the value rules
+func (rr AppVersionRuleType) Match(inputData InputRulesParameters, ruleValue interface{}) bool {
+	return matchCompiled(rr, inputData, ruleValue)
+}
+
+//Inputs gives the input parameters which the rule value needs
+func (rr AppVersionRuleType) Inputs(ruleValue interface{}) []string {
+	mapData, _ := ruleValue.(map[string]interface{})
+	if _, exist := mapData["os"]; exist {
+		return []string{InputAppVersion, InputPlatformOS}
+	}
+	return []string{InputAppVersion}
+}
+
+//NewAppVersionRuleType creates app version rule type instance
+func NewAppVersionRuleType(id int, name string) AppVersionRuleType {
+	return AppVersionRuleType{ID: id, Name: name}
+}
+
+//RolloutRuleType represents percentage rollout rule type entity
+//
+//value { "percentage": 25, "salt": "new-dining", "fallback": false }
+//The user uuid and the salt are hashed in a stable bucket so the same user always gets the same result for the same rule.
+//The fallback is used for users without uuid and it is false if not provided, it cannot be combined with an on missing
+//policy of the rule.
+type RolloutRuleType struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+//rolloutBuckets gives 0.01% precision
+const rolloutBuckets = 10000
+
+//GetID gives the rule id
+func (rr RolloutRuleType) GetID() int {
+	return rr.ID
+}
+
+//GetName gives the rule name
+func (rr RolloutRuleType) GetName() string {
+	return rr.Name
+}
+
+//ValidData checks if the input data is valid for the rule type
+func (rr RolloutRuleType) ValidData(data interface{}) error {
+	mapData, ok := data.(map[string]interface{})
+	if !ok {
+		return errors.New("must be an object")
+	}
+	percentage, ok := mapData["percentage"].(float64)
+	if !ok || percentage < 0 || percentage > 100 {
+		return newValidationError("value.percentage", "must be a number between 0 and 100")
+	}
+	salt, ok := mapData["salt"].(string)
+	if !ok || len(salt) == 0 {
+		return newValidationError("value.salt", "must be a non empty string")
+	}
+	return nil
+}
+
+//Compile compiles the rule value to a predicate
+func (rr RolloutRuleType) Compile(ruleValue interface{}) (Predicate, error) {
+	mapData, ok := ruleValue.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("must be an object")
+	}
+	percentage, ok := mapData["percentage"].(float64)
+	if !ok {
+		return nil, newValidationError("value.percentage", "must be a number")
+	}
+	salt, ok := mapData["salt"].(string)
+	if !ok {
+		return nil, newValidationError("value.salt", "must be a string")
+	}
+	fallback, _ := mapData["fallback"].(bool)
+	threshold := percentage * rolloutBuckets / 100
+
+	return func(inputData InputRulesParameters) bool {
+		user := inputData.User
+		if user == nil || len(user.UUID) == 0 {
+			return fallback
+		}
+		return float64(RolloutBucket(salt, user.UUID)) < threshold
+	}, nil
+}
+
+//Match match if the input paramters match with the value rules
+func (rr RolloutRuleType) Match(inputData InputRulesParameters, ruleValue interface{}) bool {
+	return matchCompiled(rr, inputData, ruleValue)
+}
+
+//Inputs gives the input parameters which the rule value needs
+func (rr RolloutRuleType) Inputs(ruleValue interface{}) []string {
+	return []string{InputUserUUID}
+}
+
+//RolloutBucket gives the stable bucket in [0, 10000) for the salt and the user uuid
+func RolloutBucket(salt string, uuid string) int {
+	hash := sha256.Sum256([]byte(salt + ":" + uuid))
+	return int(binary.BigEndian.Uint64(hash[:8]) % rolloutBuckets)
+}
+
+//NewRolloutRuleType creates rollout rule type instance
+func NewRolloutRuleType(id int, name string) RolloutRuleType {
+	return RolloutRuleType{ID: id, Name: name}
+}
+
+//CompositeRuleType represents composite rule type entity
+//
+//The value is a boolean expression tree. Every node is an object with exactly one key - "and" and "or" take a list of nodes,
+//"not" takes a single node and any other key is the name of a rule type whose value is the leaf value, for example
+//{ "or": [ { "auth": { "shibbolethLoggedIn": true } }, { "and": [ { "auth": { "phoneLoggedIn": true } }, { "platform": { "os": "ios" } } ] } ] }
+type CompositeRuleType struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+//GetID gives the rule id
+func (rr CompositeRuleType) GetID() int {
+	return rr.ID
+}
+
+//GetName gives the rule name
+func (rr CompositeRuleType) GetName() string {
+	return rr.Name
+}
+
+//ValidData checks if the input data is valid for the rule type
+func (rr CompositeRuleType) ValidData(data interface{}) error {
+	errs := rr.validNode(data, "value")
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+//Compile compiles the rule value to a predicate
+func (rr CompositeRuleType) Compile(ruleValue interface{}) (Predicate, error) {
+	errs := rr.validNode(ruleValue, "value")
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return rr.compileNode(ruleValue)
+}
+
+//Match match if the input paramters match with the value rules
+func (rr CompositeRuleType) Match(inputData InputRulesParameters, ruleValue interface{}) bool {
+	return matchCompiled(rr, inputData, ruleValue)
+}
+
+//Inputs gives the input parameters which the rule value needs
+func (rr CompositeRuleType) Inputs(ruleValue interface{}) []string {
+	added := map[string]bool{}
+	var inputs []string
+	rr.nodeInputs(ruleValue, func(input string) {
+		if !added[input] {
+			added[input] = true
+			inputs = append(inputs, input)
+		}
+	})
+	sort.Strings(inputs)
+	return inputs
+}
+
+func (rr CompositeRuleType) validNode(node interface{}, field string) ValidationErrors {
+	key, value, ok := rr.getNode(node)
+	if !ok {
+		return ValidationErrors{{Field: field, Message: "must be an object with exactly one field"}}
+	}
+	field = field + "." + key
+
+	switch key {
+	case "and", "or":
+		list, ok := value.([]interface{})
+		if !ok || len(list) == 0 {
+			return ValidationErrors{{Field: field, Message: "must be a non empty list"}}
+		}
+		var errs ValidationErrors
+		for index, item := range list {
+			errs = append(errs, rr.validNode(item, fmt.Sprintf("%s[%d]", field, index))...)
+		}
+		return errs
+	case "not":
+		return rr.validNode(value, field)
+	default:
+		ruleType, err := NewRuleType(0, key)
+		if err != nil {
+			return ValidationErrors{{Field: field, Message: "is not a supported rule type"}}
+		}
+		return validateRuleValue(ruleType, value, field)
+	}
+}
+
+//compileNode compiles a node which is already validated
+func (rr CompositeRuleType) compileNode(node interface{}) (Predicate, error) {
+	key, value, _ := rr.getNode(node)
+
+	switch key {
+	case "and", "or":
+		list, _ := value.([]interface{})
+		predicates := make([]Predicate, len(list))
+		for index, item := range list {
+			predicate, err := rr.compileNode(item)
+			if err != nil {
+				return nil, err
+			}
+			predicates[index] = predicate
+		}
+		if key == "and" {
+			return func(inputData InputRulesParameters) bool {
+				for _, predicate := range predicates {
+					if !predicate(inputData) {
+						return false
+					}
+				}
+				return true
+			}, nil
+		}
+		return func(inputData InputRulesParameters) bool {
+			for _, predicate := range predicates {
+				if predicate(inputData) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case "not":
+		predicate, err := rr.compileNode(value)
+		if err != nil {
+			return nil, err
+		}
+		return func(inputData InputRulesParameters) bool {
+			return !predicate(inputData)
+		}, nil
+	default:
+		ruleType, err := NewRuleType(0, key)
+		if err != nil {
+			return nil, err
+		}
+		return ruleType.Compile(value)
+	}
+}
+
+//nodeInputs walks the tree and gives the inputs of every leaf
+func (rr CompositeRuleType) nodeInputs(node interface{}, add func(input string)) {
+	key, value, ok := rr.getNode(node)
+	if !ok {
+		return
+	}
+	switch key {
+	case "and", "or":
+		list, _ := value.([]interface{})
+		for _, item := range list {
+			rr.nodeInputs(item, add)
+		}
+	case "not":
+		rr.nodeInputs(value, add)
+	default:
+		ruleType, err := NewRuleType(0, key)
+		if err != nil {
+			return
+		}
+		for _, input := range ruleType.Inputs(value) {
+			add(input)
+		}
+	}
+}
+
+//getNode gives the single key and value of a tree node
+func (rr CompositeRuleType) getNode(node interface{}) (string, interface{}, bool) {
+	mapData, ok := node.(map[string]interface{})
+	if !ok || len(mapData) != 1 {
+		return "", nil, false
+	}
+	for key, value := range mapData {
+		return key, value, true
+	}
+	return "", nil, false
+}
+
+//NewCompositeRuleType creates composite rule type instance
+func NewCompositeRuleType(id int, name string) CompositeRuleType {
+	return CompositeRuleType{ID: id, Name: name}
+}
+
+//ScheduleRuleType represents schedule rule type entity
+//
+//value { "timezone": "America/Chicago", "start": "2021-08-23T00:00:00-05:00", "end": "2021-12-18T00:00:00-06:00",
+//	"weekly": [ { "days": ["sat"], "from": "08:00", "to": "18:00" } ] }
+//All fields are optional but at least one of start, end and weekly must be present. The start is inclusive and the end is exclusive.
+//An end in the past is valid so that expired rules can still be updated, such a rule just never matches.
+//The weekly windows are evaluated in the time zone which defaults to UTC.
+type ScheduleRuleType struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+
+	now func() time.Time
+}
+
+type schedule struct {
+	location *time.Location
+	start    *time.Time
+	end      *time.Time
+	weekly   []weeklyWindow
+}
+
+type weeklyWindow struct {
+	days map[time.Weekday]bool
+	from int //minutes from midnight
+	to   int //minutes from midnight
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+//GetID gives the rule id
+func (rr ScheduleRuleType) GetID() int {
+	return rr.ID
+}
+
+//GetName gives the rule name
+func (rr ScheduleRuleType) GetName() string {
+	return rr.Name
+}
+
+//ValidData checks if the input data is valid for the rule type
+func (rr ScheduleRuleType) ValidData(data interface{}) error {
+	schedule, err := rr.parse(data)
+	if err != nil {
+		return err
+	}
+
+	//reject schedules which can never be active
+	if schedule.end != nil {
+		if schedule.start != nil && !schedule.end.After(*schedule.start) {
+			return newValidationError("value.end", "must be after the start")
+		}
+	}
+	if schedule.start != nil && schedule.end != nil && len(schedule.weekly) > 0 && !schedule.hasWeeklyWindowInRange() {
+		return newValidationError("value.weekly", "there is no weekly window between the start and the end")
+	}
+	return nil
+}
+
+//Compile compiles the rule value to a predicate
+func (rr ScheduleRuleType) Compile(ruleValue interface{}) (Predicate, error) {
+	schedule, err := rr.parse(ruleValue)
+	if err != nil {
+		return nil, err
+	}
+	//it does not rely on any input parameters but on the current time
+	return func(inputData InputRulesParameters) bool {
+		return schedule.isActive(rr.currentTime())
+	}, nil
+}
+
+//Match match if the input paramters match with the value rules
+func (rr ScheduleRuleType) Match(inputData InputRulesParameters, ruleValue interface{}) bool {
+	return matchCompiled(rr, inputData, ruleValue)
+}
+
+//Inputs gives the input parameters which the rule value needs
+func (rr ScheduleRuleType) Inputs(ruleValue interface{}) []string {
+	return nil
+}
+
+func (rr ScheduleRuleType) currentTime() time.Time {
+	if rr.now == nil {
+		return time.Now()
+	}
+	return rr.now()
+}
+
+func (rr ScheduleRuleType) parse(data interface{}) (*schedule, error) {
+	mapData, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("must be an object")
+	}
+	for key := range mapData {
+		if key != "timezone" && key != "start" && key != "end" && key != "weekly" {
+			return nil, newValidationError("value."+key, "is not supported")
+		}
+	}
+
+	result := schedule{location: time.UTC}
+	if timezone, exist := mapData["timezone"]; exist {
+		name, ok := timezone.(string)
+		if !ok || len(name) == 0 {
+			return nil, newValidationError("value.timezone", "must be a non empty string")
+		}
+		location, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, newValidationError("value.timezone", "is not a valid IANA time zone")
+		}
+		result.location = location
+	}
+
+	start, err := rr.parseInstant(mapData, "start")
+	if err != nil {
+		return nil, err
+	}
+	result.start = start
+	end, err := rr.parseInstant(mapData, "end")
+	if err != nil {
+		return nil, err
+	}
+	result.end = end
+
+	if weekly, exist := mapData["weekly"]; exist {
+		list, ok := weekly.([]interface{})
+		if !ok || len(list) == 0 {
+			return nil, newValidationError("value.weekly", "must be a non empty list")
+		}
+		for index, item := range list {
+			window, err := rr.parseWeeklyWindow(item, fmt.Sprintf("value.weekly[%d]", index))
+			if err != nil {
+				return nil, err
+			}
+			result.weekly = append(result.weekly, *window)
+		}
+	}
+
+	if result.start == nil && result.end == nil && len(result.weekly) == 0 {
+		return nil, errors.New("at least one of start, end and weekly is required")
+	}
+	return &result, nil
+}
+
+func (rr ScheduleRuleType) parseInstant(mapData map[string]interface{}, key string) (*time.Time, error) {
+	value, exist := mapData[key]
+	if !exist {
+		return nil, nil
+	}
+	text, _ := value.(string)
+	instant, err := time.Parse(time.RFC3339, text)
+	if err != nil {
+		return nil, newValidationError("value."+key, "must be a RFC 3339 date-time")
+	}
+	return &instant, nil
+}
+
+func (rr ScheduleRuleType) parseWeeklyWindow(data interface{}, field string) (*weeklyWindow, error) {
+	mapData, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, newValidationError(field, "must be an object")
+	}
+
+	days, ok := mapData["days"].([]interface{})
+	if !ok || len(days) == 0 {
+		return nil, newValidationError(field+".days", "must be a non empty list")
+	}
+	window := weeklyWindow{days: map[time.Weekday]bool{}}
+	for index, day := range days {
+		name, _ := day.(string)
+		weekday, exist := weekdays[strings.ToLower(name)]
+		if !exist {
+			return nil, newValidationError(fmt.Sprintf("%s.days[%d]", field, index), "is not a valid day")
+		}
+		window.days[weekday] = true
+	}
+
+	from, ok := rr.parseClockTime(mapData["from"], 0)
+	if !ok {
+		return nil, newValidationError(field+".from", "must be a HH:MM time")
+	}
+	to, ok := rr.parseClockTime(mapData["to"], 24*60)
+	if !ok {
+		return nil, newValidationError(field+".to", "must be a HH:MM time")
+	}
+	if from >= to {
+		return nil, newValidationError(field, "from must be before to")
+	}
+	window.from = from
+	window.to = to
+	return &window, nil
+}
+
+//parseClockTime parses HH:MM in minutes from midnight, 24:00 is allowed as the end of the day
+func (rr ScheduleRuleType) parseClockTime(data interface{}, defaultValue int) (int, bool) {
+	if data == nil {
+		return defaultValue, true
+	}
+	text, ok := data.(string)
+	if !ok {
+		return 0, false
+	}
+	var hours, minutes int
+	_, err := fmt.Sscanf(text, "%2d:%2d", &hours, &minutes)
+	if err != nil || len(text) != 5 || hours < 0 || minutes < 0 || minutes > 59 || hours > 24 || (hours == 24 && minutes > 0) {
+		return 0, false
+	}
+	return hours*60 + minutes, true
+}
+
+func (s schedule) isActive(now time.Time) bool {
+	if s.start != nil && now.Before(*s.start) {
+		return false
+	}
+	if s.end != nil && !now.Before(*s.end) {
+		return false
+	}
+	if len(s.weekly) == 0 {
+		return true
+	}
+
+	local := now.In(s.location)
+	minutes := local.Hour()*60 + local.Minute()
+	for _, window := range s.weekly {
+		if window.days[local.Weekday()] && minutes >= window.from && minutes < window.to {
+			return true
+		}
+	}
+	return false
+}
+
+//hasWeeklyWindowInRange checks if any weekly window overlaps with the start - end range
+func (s schedule) hasWeeklyWindowInRange() bool {
+	start := s.start.In(s.location)
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, s.location)
+	//a week and a day is enough to see every weekday in the range
+	for i := 0; i < 8 && day.Before(*s.end); i++ {
+		for _, window := range s.weekly {
+			if !window.days[day.Weekday()] {
+				continue
+			}
+			from := day.Add(time.Duration(window.from) * time.Minute)
+			to := day.Add(time.Duration(window.to) * time.Minute)
+			if from.Before(*s.end) && to.After(*s.start) {
+				return true
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return false
+}
+
+//NewScheduleRuleType creates schedule rule type instance
+func NewScheduleRuleType(id int, name string) ScheduleRuleType {
+	return NewScheduleRuleTypeWithClock(id, name, time.Now)
+}
+
+//NewScheduleRuleTypeWithClock creates schedule rule type instance which uses the provided clock
+func NewScheduleRuleTypeWithClock(id int, name string, now func() time.Time) ScheduleRuleType {
+	return ScheduleRuleType{ID: id, Name: name, now: now}
+}
+
+//AttributeRuleType represents attribute rule type entity
+//
+//value { "name": "campus", "op": "in", "value": ["urbana", "chicago"], "not": false } where not is optional
+//The operators are eq, ne, in, contains, gt, gte, lt, lte and exists. The contains operator checks a list attribute for
+//the value or a string attribute for a substring. Every operator except exists does not match a missing attribute.
+type AttributeRuleType struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+//GetID gives the rule id
+func (rr AttributeRuleType) GetID() int {
+	return rr.ID
+}
+
+//GetName gives the rule name
+func (rr AttributeRuleType) GetName() string {
+	return rr.Name
+}
+
+//ValidData checks if the input data is valid for the rule type
+func (rr AttributeRuleType) ValidData(data interface{}) error {
+	_, err := rr.Compile(data)
+	return err
+}
+
+//Compile compiles the rule value to a predicate
+func (rr AttributeRuleType) Compile(ruleValue interface{}) (Predicate, error) {
+	mapData, ok := ruleValue.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("must be an object")
+	}
+	name, ok := mapData["name"].(string)
+	if !ok || len(name) == 0 {
+		return nil, newValidationError("value.name", "must be a non empty string")
+	}
+	op, _ := mapData["op"].(string)
+	negate, ok := mapData["not"].(bool)
+	if _, exist := mapData["not"]; exist && !ok {
+		return nil, newValidationError("value.not", "must be a boolean")
+	}
+	wantedValue, hasValue := mapData["value"]
+
+	var check func(value interface{}) bool
+	switch op {
+	case "exists":
+		if hasValue {
+			return nil, newValidationError("value.value", "is not allowed for the exists operator")
+		}
+		check = func(value interface{}) bool {
+			return true
+		}
+	case "eq", "ne", "contains":
+		if !isAttributeScalar(wantedValue) {
+			return nil, newValidationError("value.value", "must be a string, a number or a boolean")
+		}
+		check = rr.scalarCheck(op, wantedValue)
+	case "in":
+		list, ok := wantedValue.([]interface{})
+		if !ok || len(list) == 0 {
+			return nil, newValidationError("value.value", "must be a non empty list")
+		}
+		for index, item := range list {
+			if !isAttributeScalar(item) {
+				return nil, newValidationError(fmt.Sprintf("value.value[%d]", index), "must be a string, a number or a boolean")
+			}
+		}
+		check = func(value interface{}) bool {
+			for _, item := range list {
+				if item == value {
+					return true
+				}
+			}
+			return false
+		}
+	case "gt", "gte", "lt", "lte":
+		number, ok := wantedValue.(float64)
+		if !ok {
+			return nil, newValidationError("value.value", "must be a number")
+		}
+		check = rr.numberCheck(op, number)
+	default:
+		return nil, newValidationError("value.op", "must be one of eq, ne, in, contains, gt, gte, lt, lte and exists")
+	}
+
+	return func(inputData InputRulesParameters) bool {
+		value, exist := inputData.Attributes[name]
+		return (exist && value != nil && check(value)) != negate
+	}, nil
+}
+
+func (rr AttributeRuleType) scalarCheck(op string, wantedValue interface{}) func(value interface{}) bool {
+	switch op {
+	case "eq":
+		return func(value interface{}) bool {
+			return value == wantedValue
+		}
+	case "ne":
+		return func(value interface{}) bool {
+			return value != wantedValue
+		}
+	}
+	//contains
+	return func(value interface{}) bool {
+		switch v := value.(type) {
+		case []interface{}:
+			for _, item := range v {
+				if item == wantedValue {
+					return true
+				}
+			}
+		case string:
+			text, ok := wantedValue.(string)
+			return ok && strings.Contains(v, text)
+		}
+		return false
+	}
+}
+
+func (rr AttributeRuleType) numberCheck(op string, wantedValue float64) func(value interface{}) bool {
+	return func(value interface{}) bool {
+		number, ok := value.(float64)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "gt":
+			return number > wantedValue
+		case "gte":
+			return number >= wantedValue
+		case "lt":
+			return number < wantedValue
+		}
+		return number <= wantedValue
+	}
+}
+
+//Match match if the input paramters match with the value rules
+func (rr AttributeRuleType) Match(inputData InputRulesParameters, ruleValue interface{}) bool {
+	return matchCompiled(rr, inputData, ruleValue)
+}
+
+//Inputs gives the input parameters which the rule value needs
+func (rr AttributeRuleType) Inputs(ruleValue interface{}) []string {
+	mapData, _ := ruleValue.(map[string]interface{})
+	name, ok := mapData["name"].(string)
+	if !ok {
+		return nil
+	}
+	return []string{AttributeInput(name)}
+}
+
+//NewAttributeRuleType creates attribute rule type instance
+func NewAttributeRuleType(id int, name string) AttributeRuleType {
+	return AttributeRuleType{ID: id, Name: name}
+}
+
+//NetworkRuleType represents network rule type entity
+//
+//value ["192.17.0.0/16", "2620:0:e00::/48", "10.1.2.3"] where a single address is a range of one address.
+//It matches if the client address is in any of the ranges, it does not match if the client address is not known.
+type NetworkRuleType struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+//GetID gives the rule id
+func (rr NetworkRuleType) GetID() int {
+	return rr.ID
+}
+
+//GetName gives the rule name
+func (rr NetworkRuleType) GetName() string {
+	return rr.Name
+}
+
+//ValidData checks if the input data is valid for the rule type
+func (rr NetworkRuleType) ValidData(data interface{}) error {
+	_, err := rr.Compile(data)
+	return err
+}
+
+//Compile compiles the rule value to a predicate
+func (rr NetworkRuleType) Compile(ruleValue interface{}) (Predicate, error) {
+	list, ok := ruleValue.([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, errors.New("must be a non empty list")
+	}
+	ranges := make([]*net.IPNet, len(list))
+	for index, item := range list {
+		text, _ := item.(string)
+		ipNet, err := ParseIPNet(text)
+		if err != nil {
+			return nil, newValidationError(fmt.Sprintf("value[%d]", index), err.Error())
+		}
+		ranges[index] = ipNet
+	}
+
+	return func(inputData InputRulesParameters) bool {
+		return ContainsIP(ranges, inputData.ClientIP)
+	}, nil
+}
+
+//Match match if the input paramters match with the value rules
+func (rr NetworkRuleType) Match(inputData InputRulesParameters, ruleValue interface{}) bool {
+	return matchCompiled(rr, inputData, ruleValue)
+}
+
+//Inputs gives the input parameters which the rule value needs
+func (rr NetworkRuleType) Inputs(ruleValue interface{}) []string {
+	return []string{InputClientIP}
+}
+
+//NewNetworkRuleType creates network rule type instance
+func NewNetworkRuleType(id int, name string) NetworkRuleType {
+	return NetworkRuleType{ID: id, Name: name}
+}
+
+//LocaleRuleType represents locale rule type entity
+//
+//value ["es", "pt-BR"]
+//It matches if the most preferred locale of the Accept-Language header is one of the locales or a more specific one,
+//for example "es-MX" matches "es" but "es" does not match "es-MX".
+type LocaleRuleType struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+//GetID gives the rule id
+func (rr LocaleRuleType) GetID() int {
+	return rr.ID
+}
+
+//GetName gives the rule name
+func (rr LocaleRuleType) GetName() string {
+	return rr.Name
+}
+
+//ValidData checks if the input data is valid for the rule type
+func (rr LocaleRuleType) ValidData(data interface{}) error {
+	_, err := rr.Compile(data)
+	return err
+}
+
+//Compile compiles the rule value to a predicate
+func (rr LocaleRuleType) Compile(ruleValue interface{}) (Predicate, error) {
+	list, ok := ruleValue.([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, errors.New("must be a non empty list")
+	}
+	locales := make([]string, len(list))
+	for index, item := range list {
+		text, _ := item.(string)
+		locale := NormalizeLocale(text)
+		if len(locale) == 0 {
+			return nil, newValidationError(fmt.Sprintf("value[%d]", index), "must be a non empty string")
+		}
+		locales[index] = locale
+	}
+
+	return func(inputData InputRulesParameters) bool {
+		preferredLocale := inputData.Headers.PreferredLocale()
+		if len(preferredLocale) == 0 {
+			return false
+		}
+		for _, locale := range locales {
+			if MatchLocale(preferredLocale, locale) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+//Match match if the input paramters match with the value rules
+func (rr LocaleRuleType) Match(inputData InputRulesParameters, ruleValue interface{}) bool {
+	return matchCompiled(rr, inputData, ruleValue)
+}
+
+//Inputs gives the input parameters which the rule value needs
+func (rr LocaleRuleType) Inputs(ruleValue interface{}) []string {
+	return []string{HeaderInput("Accept-Language")}
+}
+
+//NewLocaleRuleType creates locale rule type instance
+func NewLocaleRuleType(id int, name string) LocaleRuleType {
+	return LocaleRuleType{ID: id, Name: name}
+}
+
+//HeaderRuleType represents header rule type entity
+//
+//value { "name": "User-Agent", "pattern": "Illinois/3\\.1\\.0 \\(41\\)" }
+//It matches if the header contains a match of the regular expression, it does not match if the header is not sent
+//or it is not one of the headers which are passed to the rules.
+type HeaderRuleType struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+//GetID gives the rule id
+func (rr HeaderRuleType) GetID() int {
+	return rr.ID
+}
+
+//GetName gives the rule name
+func (rr HeaderRuleType) GetName() string {
+	return rr.Name
+}
+
+//ValidData checks if the input data is valid for the rule type
+func (rr HeaderRuleType) ValidData(data interface{}) error {
+	_, err := rr.Compile(data)
+	return err
+}
+
+//Compile compiles the rule value to a predicate
+func (rr HeaderRuleType) Compile(ruleValue interface{}) (Predicate, error) {
+	mapData, ok := ruleValue.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("must be an object")
+	}
+	name, ok := mapData["name"].(string)
+	if !ok || len(name) == 0 {
+		return nil, newValidationError("value.name", "must be a non empty string")
+	}
+	pattern, ok := mapData["pattern"].(string)
+	if !ok {
+		return nil, newValidationError("value.pattern", "must be a string")
+	}
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, newValidationError("value.pattern", "must be a valid regular expression - "+err.Error())
+	}
+
+	return func(inputData InputRulesParameters) bool {
+		value, ok := inputData.Headers.Get(name)
+		return ok && regex.MatchString(value)
+	}, nil
+}
+
+//Match match if the input paramters match with the value rules
+func (rr HeaderRuleType) Match(inputData InputRulesParameters, ruleValue interface{}) bool {
+	return matchCompiled(rr, inputData, ruleValue)
+}
+
+//Inputs gives the input parameters which the rule value needs
+func (rr HeaderRuleType) Inputs(ruleValue interface{}) []string {
+	mapData, _ := ruleValue.(map[string]interface{})
+	name, ok := mapData["name"].(string)
+	if !ok {
+		return nil
+	}
+	return []string{HeaderInput(textproto.CanonicalMIMEHeaderKey(name))}
+}
+
+//NewHeaderRuleType creates header rule type instance
+func NewHeaderRuleType(id int, name string) HeaderRuleType {
+	return HeaderRuleType{ID: id, Name: name}
+}
+
+//UUIDListRuleType represents uuid list rule type entity
+//
+//value { "list": "pilot-users", "mode": "allow" } where mode is allow or deny, allow is the default.
+//The allow mode matches if the user uuid is in the list and the deny mode matches if it is not in the list.
+//A rule which references a list which is not in the data version never matches.
+type UUIDListRuleType struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+//GetID gives the rule id
+func (rr UUIDListRuleType) GetID() int {
+	return rr.ID
+}
+
+//GetName gives the rule name
+func (rr UUIDListRuleType) GetName() string {
+	return rr.Name
+}
+
+//ValidData checks if the input data is valid for the rule type
+func (rr UUIDListRuleType) ValidData(data interface{}) error {
+	_, err := rr.Compile(data)
+	return err
+}
+
+//Compile compiles the rule value to a predicate
+func (rr UUIDListRuleType) Compile(ruleValue interface{}) (Predicate, error) {
+	listName, mode, err := ParseUUIDListRuleValue(ruleValue)
+	if err != nil {
+		return nil, err
+	}
+	deny := mode == "deny"
+
+	return func(inputData InputRulesParameters) bool {
+		uuidSet, ok := inputData.UUIDLists[listName]
+		if !ok {
+			return false
+		}
+		inList := inputData.User != nil && uuidSet.Contains(inputData.User.UUID)
+		return inList != deny
+	}, nil
+}
+
+//Match match if the input paramters match with the value rules
+func (rr UUIDListRuleType) Match(inputData InputRulesParameters, ruleValue interface{}) bool {
+	return matchCompiled(rr, inputData, ruleValue)
+}
+
+//Inputs gives the input parameters which the rule value needs
+func (rr UUIDListRuleType) Inputs(ruleValue interface{}) []string {
+	return []string{InputUserUUID}
+}
+
+//NewUUIDListRuleType creates uuid list rule type instance
+func NewUUIDListRuleType(id int, name string) UUIDListRuleType {
+	return UUIDListRuleType{ID: id, Name: name}
+}
+
+//ParseUUIDListRuleValue gives the list name and the mode of an uuid list rule value
+func ParseUUIDListRuleValue(ruleValue interface{}) (string, string, error) {
+	mapData, ok := ruleValue.(map[string]interface{})
+	if !ok {
+		return "", "", errors.New("must be an object")
+	}
+	listName, ok := mapData["list"].(string)
+	if !ok || len(listName) == 0 {
+		return "", "", newValidationError("value.list", "must be a non empty string")
+	}
+	mode := "allow"
+	if value, exist := mapData["mode"]; exist {
+		mode, _ = value.(string)
+		if mode != "allow" && mode != "deny" {
+			return "", "", newValidationError("value.mode", "must be allow or deny")
+		}
+	}
+	return listName, mode, nil
+}
+
+//ReferencedUUIDLists gives the names of the uuid lists which a rule value references, a composite rule value
+//references the lists of its uuid_list leaves
+func ReferencedUUIDLists(ruleTypeName string, ruleValue interface{}) []string {
+	switch ruleTypeName {
+	case "uuid_list":
+		listName, _, err := ParseUUIDListRuleValue(ruleValue)
+		if err != nil {
+			return nil
+		}
+		return []string{listName}
+	case "composite":
+		composite := CompositeRuleType{}
+		key, value, ok := composite.getNode(ruleValue)
+		if !ok {
+			return nil
+		}
+		switch key {
+		case "and", "or":
+			var result []string
+			list, _ := value.([]interface{})
+			for _, item := range list {
+				result = append(result, ReferencedUUIDLists("composite", item)...)
+			}
+			return result
+		case "not":
+			return ReferencedUUIDLists("composite", value)
+		default:
+			return ReferencedUUIDLists(key, value)
+		}
+	}
+	return nil
+}
+
+//GeofenceRuleType represents geofence rule type entity
+//
+//value { "circle": { "latitude": 40.1020, "longitude": -88.2272, "radius": 1500 }, "fallback": false }
+//or { "polygon": [{ "latitude": 40.11, "longitude": -88.24 }, ...], "fallback": false }
+//The radius is in meters. It matches if the client location is inside the circle or the polygon. The fallback
+//is the result when the client does not send location, it is false if omitted and it cannot be combined with an on
+//missing policy of the rule.
+type GeofenceRuleType struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+//GetID gives the rule id
+func (rr GeofenceRuleType) GetID() int {
+	return rr.ID
+}
+
+//GetName gives the rule name
+func (rr GeofenceRuleType) GetName() string {
+	return rr.Name
+}
+
+//ValidData checks if the input data is valid for the rule type, the polygon must be a simple polygon
+func (rr GeofenceRuleType) ValidData(data interface{}) error {
+	_, err := rr.Compile(data)
+	return err
+}
+
+//Compile compiles the rule value to a predicate
+func (rr GeofenceRuleType) Compile(ruleValue interface{}) (Predicate, error) {
+	mapData, ok := ruleValue.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("must be an object")
+	}
+	fallback, ok := mapData["fallback"].(bool)
+	if _, exist := mapData["fallback"]; exist && !ok {
+		return nil, newValidationError("value.fallback", "must be a boolean")
+	}
+
+	circleValue, hasCircle := mapData["circle"]
+	polygonValue, hasPolygon := mapData["polygon"]
+	if hasCircle == hasPolygon {
+		return nil, errors.New("must have either circle or polygon")
+	}
+
+	var contains func(location Location) bool
+	if hasCircle {
+		circle, ok := circleValue.(map[string]interface{})
+		if !ok {
+			return nil, newValidationError("value.circle", "must be an object")
+		}
+		center, err := rr.parseLocation(circle)
+		if err != nil {
+			return nil, newValidationError("value.circle", err.Error())
+		}
+		radius, ok := circle["radius"].(float64)
+		if !ok || radius <= 0 {
+			return nil, newValidationError("value.circle.radius", "must be a positive number")
+		}
+		contains = func(location Location) bool {
+			return center.DistanceTo(location) <= radius
+		}
+	} else {
+		points, ok := polygonValue.([]interface{})
+		if !ok {
+			return nil, newValidationError("value.polygon", "must be a list")
+		}
+		polygon := make(Polygon, len(points))
+		for index, item := range points {
+			point, _ := item.(map[string]interface{})
+			location, err := rr.parseLocation(point)
+			if err != nil {
+				return nil, newValidationError(fmt.Sprintf("value.polygon[%d]", index), err.Error())
+			}
+			polygon[index] = location
+		}
+		if err := polygon.Validate(); err != nil {
+			return nil, newValidationError("value.polygon", err.Error())
+		}
+		contains = polygon.Contains
+	}
+
+	return func(inputData InputRulesParameters) bool {
+		if inputData.Location == nil {
+			return fallback
+		}
+		return contains(*inputData.Location)
+	}, nil
+}
+
+func (rr GeofenceRuleType) parseLocation(data map[string]interface{}) (Location, error) {
+	latitude, ok := data["latitude"].(float64)
+	if !ok {
+		return Location{}, errors.New("latitude must be a number")
+	}
+	longitude, ok := data["longitude"].(float64)
+	if !ok {
+		return Location{}, errors.New("longitude must be a number")
+	}
+	location := Location{Latitude: latitude, Longitude: longitude}
+	return location, location.Validate()
+}
+
+//Match match if the input paramters match with the value rules
+func (rr GeofenceRuleType) Match(inputData InputRulesParameters, ruleValue interface{}) bool {
+	return matchCompiled(rr, inputData, ruleValue)
+}
+
+//Inputs gives the input parameters which the rule value needs
+func (rr GeofenceRuleType) Inputs(ruleValue interface{}) []string {
+	return []string{InputLocation}
+}
+
+//NewGeofenceRuleType creates geofence rule type instance
+func NewGeofenceRuleType(id int, name string) GeofenceRuleType {
+	return GeofenceRuleType{ID: id, Name: name}
 }
@@ -0,0 +1,850 @@
+/*
+ *   Copyright (c) 2020 Board of Trustees of the University of Illinois.
+ *   All rights reserved.
+
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+
+ *   http://www.apache.org/licenses/LICENSE-2.0
+
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+func parseValue(t *testing.T, value string) interface{} {
+	var result interface{}
+	err := json.Unmarshal([]byte(value), &result)
+	if err != nil {
+		t.Fatalf("Cannot unmarshal %s - %s", value, err.Error())
+	}
+	return result
+}
+
+func TestCompositeRuleType(t *testing.T) {
+	ruleType := NewCompositeRuleType(1, "composite")
+
+	value := parseValue(t, `{"or": [
+		{"auth": {"shibbolethLoggedIn": true}},
+		{"and": [{"auth": {"phoneLoggedIn": true}}, {"platform": {"os": "ios"}}]}
+	]}`)
+	if ValidateRuleValue(ruleType, value) != nil {
+		t.Fatal("The value should be valid")
+	}
+
+	idToken := "id"
+	accessToken := "access"
+	refreshToken := "refresh"
+	phone := "+1000"
+	ios := "ios"
+	android := "android"
+
+	shibboleth := InputRulesParameters{AuthVersion: "3",
+		AuthV3: &AuthV3{Token: &AuthToken{IDToken: &idToken, AccessToken: &accessToken, RefreshToken: &refreshToken}}}
+	phoneIOS := InputRulesParameters{AuthVersion: "3", AuthV3: &AuthV3{Token: &AuthToken{IDToken: &idToken, PhoneNumber: &phone}},
+		Platform: &Platform{OS: &ios}}
+	phoneAndroid := InputRulesParameters{AuthVersion: "3", AuthV3: &AuthV3{Token: &AuthToken{IDToken: &idToken, PhoneNumber: &phone}},
+		Platform: &Platform{OS: &android}}
+
+	if !ruleType.Match(shibboleth, value) {
+		t.Error("Shibboleth logged in should match")
+	}
+	if !ruleType.Match(phoneIOS, value) {
+		t.Error("Phone logged in on iOS should match")
+	}
+	if ruleType.Match(phoneAndroid, value) {
+		t.Error("Phone logged in on Android should not match")
+	}
+
+	notValue := parseValue(t, `{"not": {"platform": {"os": "ios"}}}`)
+	if ruleType.Match(phoneIOS, notValue) || !ruleType.Match(phoneAndroid, notValue) {
+		t.Error("Not node is wrong")
+	}
+
+	invalid := []string{`{}`, `{"and": []}`, `{"or": {"auth": {}}}`, `{"unknown": true}`,
+		`{"privacy": "high"}`, `{"and": [{"enable": true}], "or": [{"enable": true}]}`}
+	for _, item := range invalid {
+		if ValidateRuleValue(ruleType, parseValue(t, item)) == nil {
+			t.Errorf("%s should not be valid", item)
+		}
+	}
+}
+
+func TestAuthRuleType(t *testing.T) {
+	ruleType := NewAuthRuleType(1, "auth")
+
+	idToken := "id"
+	accessToken := "access"
+	refreshToken := "refresh"
+	groups := []string{"group1"}
+
+	shibbolethV2 := InputRulesParameters{AuthVersion: "2",
+		AuthV2: &AuthV2{IDToken: &idToken, AccessToken: &accessToken, RefreshToken: &refreshToken, UserInfo: &AuthInfo{UIuceduIsMemberOf: &groups}}}
+	shibbolethV3 := InputRulesParameters{AuthVersion: "3",
+		AuthV3: &AuthV3{Token: &AuthToken{IDToken: &idToken, AccessToken: &accessToken, RefreshToken: &refreshToken}, User: &AuthUser{UIuceduIsMemberOf: &groups}}}
+
+	for _, inputData := range []InputRulesParameters{shibbolethV2, shibbolethV3} {
+		version := inputData.AuthVersion
+		inputData.GroupAliases = GroupAliases{"eventEditors": "group1", "staff": "group2"}
+		and := parseValue(t, `{"shibbolethLoggedIn": true, "shibbolethMemberOf": "group2"}`)
+		if ValidateRuleValue(ruleType, and) != nil {
+			t.Fatal("The value should be valid")
+		}
+		if ruleType.Match(inputData, and) {
+			t.Errorf("V%s - all conditions should be evaluated", version)
+		}
+		if !ruleType.Match(inputData, parseValue(t, `{"shibbolethLoggedIn": true, "shibbolethMemberOf": "group1"}`)) {
+			t.Errorf("V%s - all conditions match", version)
+		}
+		if !ruleType.Match(inputData, parseValue(t, `{"operator": "or", "phoneLoggedIn": true, "shibbolethMemberOf": "group1"}`)) {
+			t.Errorf("V%s - one condition matches for or", version)
+		}
+		if ruleType.Match(inputData, parseValue(t, `{"operator": "or", "phoneLoggedIn": true, "loggedIn": false}`)) {
+			t.Errorf("V%s - no condition matches for or", version)
+		}
+		if !ruleType.Match(inputData, parseValue(t, `{"eventEditor": true, "memberOfAlias": "eventEditors"}`)) {
+			t.Errorf("V%s - event editors group is configured", version)
+		}
+		if ruleType.Match(inputData, parseValue(t, `{"memberOfAlias": "staff"}`)) || ruleType.Match(inputData, parseValue(t, `{"memberOfAlias": "unknown"}`)) {
+			t.Errorf("V%s - user is not member of the alias group", version)
+		}
+	}
+
+	//not supported conditions do not match for the older auth versions
+	uin := "123"
+	v1 := InputRulesParameters{AuthVersion: "1", Auth: &Auth{UIuceduUIN: &uin}}
+	if !ruleType.Match(v1, parseValue(t, `{"shibbolethLoggedIn": true}`)) {
+		t.Error("V1 - shibboleth logged in matches")
+	}
+	if ruleType.Match(v1, parseValue(t, `{"shibbolethLoggedIn": true, "loggedIn": true}`)) {
+		t.Error("V1 - not supported condition fails and")
+	}
+	if !ruleType.Match(v1, parseValue(t, `{"operator": "or", "shibbolethLoggedIn": true, "loggedIn": false}`)) {
+		t.Error("V1 - not supported condition does not count for or")
+	}
+	if ruleType.Match(shibbolethV2, parseValue(t, `{"shibbolethLoggedIn": true, "iCardNum": false}`)) {
+		t.Error("V2 - not supported condition fails and")
+	}
+	if !ruleType.Match(shibbolethV2, parseValue(t, `{"operator": "or", "shibbolethLoggedIn": true, "documentType": "passport"}`)) {
+		t.Error("V2 - not supported condition does not count for or")
+	}
+
+	invalid := []string{`{}`, `{"operator": "and"}`, `{"loggedIn": true, "operator": "xor"}`, `{"loggedIn": true, "unknown": true}`}
+	for _, item := range invalid {
+		if ValidateRuleValue(ruleType, parseValue(t, item)) == nil {
+			t.Errorf("%s should not be valid", item)
+		}
+		if ruleType.ValidData(parseValue(t, item)) == nil {
+			t.Errorf("%s should not be valid for the rule type", item)
+		}
+	}
+}
+
+func TestAuthRuleTypeGroupAliases(t *testing.T) {
+	ruleType := NewAuthRuleType(1, "auth")
+
+	groups := []string{"urn:editors", "urn:staff"}
+	v2 := InputRulesParameters{AuthVersion: "2", AuthV2: &AuthV2{UserInfo: &AuthInfo{UIuceduIsMemberOf: &groups}}}
+	v3 := InputRulesParameters{AuthVersion: "3", AuthV3: &AuthV3{User: &AuthUser{UIuceduIsMemberOf: &groups}}}
+
+	items := []struct {
+		value        string
+		groupAliases GroupAliases
+		matches      bool
+	}{
+		{`{"eventEditor": true}`, GroupAliases{"eventEditors": "urn:editors"}, true},
+		{`{"eventEditor": true}`, GroupAliases{"eventEditors": "urn:other"}, false},
+		{`{"eventEditor": false}`, GroupAliases{"eventEditors": "urn:other"}, true},
+		{`{"eventEditor": true}`, GroupAliases{}, false},
+		{`{"eventEditor": false}`, GroupAliases{}, true},
+		{`{"memberOfAlias": "staff"}`, GroupAliases{"staff": "urn:staff"}, true},
+		{`{"memberOfAlias": "staff"}`, GroupAliases{"staff": "urn:students"}, false},
+		{`{"memberOfAlias": "staff"}`, GroupAliases{"eventEditors": "urn:editors"}, false},
+		{`{"memberOfAlias": "eventEditors"}`, GroupAliases{"eventEditors": "urn:editors"}, true},
+		{`{"operator": "or", "memberOfAlias": "students", "eventEditor": true}`, GroupAliases{"eventEditors": "urn:editors", "students": "urn:students"}, true},
+	}
+	for _, item := range items {
+		value := parseValue(t, item.value)
+		if err := ValidateRuleValue(ruleType, value); err != nil {
+			t.Errorf("%s should be valid - %s", item.value, err.Error())
+			continue
+		}
+		for _, inputData := range []InputRulesParameters{v2, v3} {
+			inputData.GroupAliases = item.groupAliases
+			if ruleType.Match(inputData, value) != item.matches {
+				t.Errorf("V%s - %s with %v should give %t", inputData.AuthVersion, item.value, item.groupAliases, item.matches)
+			}
+		}
+	}
+
+	invalid := []string{`{"memberOfAlias": ""}`, `{"memberOfAlias": true}`, `{"eventEditor": "eventEditors"}`}
+	for _, item := range invalid {
+		if ValidateRuleValue(ruleType, parseValue(t, item)) == nil {
+			t.Errorf("%s should not be valid", item)
+		}
+	}
+}
+
+func TestAuthRuleTypeMemberOfPatterns(t *testing.T) {
+	ruleType := NewAuthRuleType(1, "auth")
+
+	groups := []string{"urn:mace:uiuc.edu:urbana:authman:app-rokwire-service-policy-rokwire admin app", "urn:mace:uiuc.edu:urbana:staff"}
+	inputData := InputRulesParameters{AuthVersion: "3", AuthV3: &AuthV3{User: &AuthUser{UIuceduIsMemberOf: &groups}}}
+
+	cases := map[string]bool{
+		`"urn:mace:uiuc.edu:urbana:staff"`:                                                    true,
+		`"urn:mace:uiuc.edu:urbana"`:                                                          false,
+		`{"prefix": "urn:mace:uiuc.edu:urbana:authman:app-rokwire-"}`:                         true,
+		`{"glob": "urn:mace:uiuc.edu:*:app-rokwire-*admin app"}`:                              true,
+		`{"glob": "urn:mace:uiuc.edu:*:app-rokwire"}`:                                         false,
+		`{"regex": "urn:mace:uiuc.edu:urbana:(staff|faculty)"}`:                               true,
+		`{"regex": "urbana:staff"}`:                                                           false,
+		`{"anyOf": ["urn:other", {"prefix": "urn:mace:uiuc.edu:urbana:st"}]}`:                 true,
+		`{"allOf": ["urn:mace:uiuc.edu:urbana:staff", {"glob": "*admin app"}]}`:               true,
+		`{"allOf": ["urn:mace:uiuc.edu:urbana:staff", {"anyOf": [{"equals": "urn:other"}]}]}`: false,
+	}
+	for memberOf, expected := range cases {
+		value := parseValue(t, `{"shibbolethMemberOf": `+memberOf+`}`)
+		if err := ValidateRuleValue(ruleType, value); err != nil {
+			t.Errorf("%s should be valid - %s", memberOf, err.Error())
+			continue
+		}
+		if ruleType.Match(inputData, value) != expected {
+			t.Errorf("%s should give %t", memberOf, expected)
+		}
+	}
+
+	invalid := map[string]string{
+		`{"regex": "urn:(staff"}`:               "value.shibbolethMemberOf.regex",
+		`{"anyOf": ["urn:a", {"suffix": "x"}]}`: "value.shibbolethMemberOf.anyOf[1].suffix",
+		`{"allOf": []}`:                         "value.shibbolethMemberOf.allOf",
+		`{"prefix": "a", "glob": "b"}`:          "value.shibbolethMemberOf",
+	}
+	for memberOf, field := range invalid {
+		err := ValidateRuleValue(ruleType, parseValue(t, `{"shibbolethMemberOf": `+memberOf+`}`))
+		validationErrors, ok := err.(ValidationErrors)
+		if !ok || len(validationErrors) == 0 || validationErrors[0].Field != field {
+			t.Errorf("%s should fail for %s but got %v", memberOf, field, err)
+		}
+	}
+}
+
+func TestPrivacyRuleType(t *testing.T) {
+	ruleType := NewPrivacyRuleType(1, "privacy")
+	user := func(level int) InputRulesParameters {
+		return InputRulesParameters{User: &User{PrivacySettings: PrivacySettings{Level: level}}}
+	}
+
+	cases := []struct {
+		value     string
+		levels    map[int]bool
+		anonymous bool
+	}{
+		{`3`, map[int]bool{2: false, 3: true, 5: true}, false},
+		{`2.5`, map[int]bool{1: false, 2: true, 3: true}, false},
+		{`{"max": 2}`, map[int]bool{1: true, 2: true, 3: false}, false},
+		{`{"min": 2, "max": 4}`, map[int]bool{1: false, 2: true, 4: true, 5: false}, false},
+		{`{"min": 3, "anonymous": true}`, map[int]bool{2: false, 3: true}, true},
+		{`{"anonymous": true}`, map[int]bool{1: true, 5: true}, true},
+	}
+	for _, item := range cases {
+		value := parseValue(t, item.value)
+		if err := ValidateRuleValue(ruleType, value); err != nil {
+			t.Errorf("%s should be valid - %s", item.value, err.Error())
+			continue
+		}
+		for level, expected := range item.levels {
+			if ruleType.Match(user(level), value) != expected {
+				t.Errorf("%s for level %d should give %t", item.value, level, expected)
+			}
+		}
+		if ruleType.Match(InputRulesParameters{}, value) != item.anonymous {
+			t.Errorf("%s for anonymous user should give %t", item.value, item.anonymous)
+		}
+	}
+
+	invalid := []string{`{}`, `{"min": 4, "max": 2}`, `{"min": "2"}`, `{"level": 2}`, `"3"`, `-1`, `{"min": 2.5}`, `{"max": 3.5}`}
+	for _, item := range invalid {
+		if ValidateRuleValue(ruleType, parseValue(t, item)) == nil {
+			t.Errorf("%s should not be valid", item)
+		}
+	}
+	for _, item := range []string{`{"min": 2.5}`, `{"max": 3.5}`} {
+		if ruleType.ValidData(parseValue(t, item)) == nil {
+			t.Errorf("%s should not be valid for the rule type", item)
+		}
+	}
+}
+
+func TestRolesRuleType(t *testing.T) {
+	ruleType := NewRolesRuleType(1, "roles")
+
+	items := []struct {
+		value   string
+		roles   []string
+		matches bool
+	}{
+		{`"student"`, []string{"student"}, true},
+		{`["student"]`, []string{"fan"}, false},
+		{`["NOT", "fan"]`, []string{"student"}, true},
+		{`[["NOT", "fan"], "OR", "student"]`, []string{"fan"}, false},
+		//AND binds tighter than OR
+		{`["fan", "AND", "student", "OR", "employee"]`, []string{"employee"}, true},
+		{`["employee", "OR", "fan", "AND", "student"]`, []string{"fan"}, false},
+		{`[["employee", "OR", "fan"], "AND", "student"]`, []string{"fan"}, false},
+		{`["fan", "AND", ["NOT", ["student", "OR", "employee"]]]`, []string{"fan"}, true},
+		{`["NOT", "fan", "AND", "student"]`, []string{"student"}, true},
+		{`["fan", "AND", "NOT", "student"]`, []string{"fan", "student"}, false},
+	}
+	for _, item := range items {
+		value := parseValue(t, item.value)
+		if ValidateRuleValue(ruleType, value) != nil {
+			t.Errorf("%s should be valid", item.value)
+			continue
+		}
+		roles := item.roles
+		inputData := InputRulesParameters{User: &User{Roles: &roles}}
+		if ruleType.Match(inputData, value) != item.matches {
+			t.Errorf("%s for %v should give %t", item.value, item.roles, item.matches)
+		}
+	}
+
+	invalid := []string{`[]`, `[[]]`, `""`, `5`, `["AND"]`, `["fan", "AND"]`, `["fan", "student"]`,
+		`["fan", "XOR", "student"]`, `["NOT"]`, `["fan", "OR", 1]`, `{"role": "fan"}`}
+	for _, item := range invalid {
+		value := parseValue(t, item)
+		if ValidateRuleValue(ruleType, value) == nil {
+			t.Errorf("%s should not be valid", item)
+		}
+		if ruleType.Match(InputRulesParameters{}, value) {
+			t.Errorf("%s should not match", item)
+		}
+	}
+}
+
+func TestParseRolesExpressionErrors(t *testing.T) {
+	_, err := ParseRolesExpression(parseValue(t, `["fan", "AND", ["student", "OR"]]`))
+	if err == nil || err.Error() != "value[2]: unexpected end of expression" {
+		t.Errorf("Wrong error %v", err)
+	}
+
+	expression, err := ParseRolesExpression(parseValue(t, `["a", "AND", "b", "OR", "NOT", "c"]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expression.String() != "((a AND b) OR NOT c)" {
+		t.Errorf("Wrong expression %s", expression.String())
+	}
+}
+
+func TestScheduleRuleType(t *testing.T) {
+	now := time.Date(2021, 9, 4, 15, 0, 0, 0, time.UTC) //Saturday, 10:00 in Chicago
+	ruleType := NewScheduleRuleTypeWithClock(1, "schedule", func() time.Time { return now })
+
+	items := []struct {
+		value   string
+		matches bool
+	}{
+		{`{"start": "2021-08-23T00:00:00-05:00", "end": "2021-12-18T00:00:00-06:00"}`, true},
+		{`{"start": "2021-09-05T00:00:00-05:00"}`, false},
+		{`{"end": "2022-01-01T00:00:00Z"}`, true},
+		{`{"end": "2021-09-01T00:00:00Z"}`, false},
+		{`{"timezone": "America/Chicago", "weekly": [{"days": ["sat"], "from": "09:00", "to": "12:00"}]}`, true},
+		{`{"timezone": "America/Chicago", "weekly": [{"days": ["sat"], "from": "10:30"}]}`, false},
+		{`{"weekly": [{"days": ["sat"], "from": "09:00", "to": "12:00"}]}`, false},
+		{`{"timezone": "America/Chicago", "weekly": [{"days": ["Friday", "Sunday"]}]}`, false},
+	}
+	for _, item := range items {
+		value := parseValue(t, item.value)
+		if ValidateRuleValue(ruleType, value) != nil {
+			t.Errorf("%s should be valid", item.value)
+			continue
+		}
+		if ruleType.Match(InputRulesParameters{}, value) != item.matches {
+			t.Errorf("%s should give %t", item.value, item.matches)
+		}
+	}
+
+	invalid := []string{`{}`, `true`, `{"timezone": "Mars/Olympus"}`, `{"start": "tomorrow"}`,
+		`{"start": "2021-12-01T00:00:00Z", "end": "2021-11-01T00:00:00Z"}`,
+		`{"weekly": []}`, `{"weekly": [{"days": []}]}`, `{"weekly": [{"days": ["someday"]}]}`,
+		`{"weekly": [{"days": ["mon"], "from": "12:00", "to": "12:00"}]}`,
+		`{"weekly": [{"days": ["mon"], "from": "25:00"}]}`,
+		`{"start": "2021-09-07T00:00:00Z", "end": "2021-09-09T00:00:00Z", "weekly": [{"days": ["sat", "sun"]}]}`,
+		`{"start": "2021-09-06T00:00:00Z", "unknown": 1}`}
+	for _, item := range invalid {
+		if ValidateRuleValue(ruleType, parseValue(t, item)) == nil {
+			t.Errorf("%s should not be valid", item)
+		}
+	}
+}
+
+func TestScheduleRuleTypeZeroValue(t *testing.T) {
+	ruleType := ScheduleRuleType{}
+	if !ruleType.Match(InputRulesParameters{}, parseValue(t, `{"start": "2021-01-01T00:00:00Z"}`)) {
+		t.Error("It should use the current time")
+	}
+}
+
+func TestAppVersionRuleType(t *testing.T) {
+	ruleType := NewAppVersionRuleType(1, "app_version")
+
+	items := []struct {
+		value   string
+		os      string
+		version string
+		matches bool
+	}{
+		{`{"version": ">=3.1.0 <4"}`, "ios", "3.1.0", true},
+		{`{"version": ">=3.1.0 <4"}`, "ios", "3.0.9", false},
+		{`{"version": ">=3.1.0 <4"}`, "ios", "4.0.0-beta.1", true},
+		{`{"version": ">=3.1.0 <4"}`, "ios", "4.0.0", false},
+		{`{"version": ">= 3.1 <4", "os": "android"}`, "ios", "3.2.0", false},
+		{`{"version": ">= 3.1 <4", "os": "android"}`, "android", "v3.2.0+1234", true},
+		{`{"version": "~3.1.2"}`, "ios", "3.1.9", true},
+		{`{"version": "~3.1.2"}`, "ios", "3.2.0", false},
+		{`{"version": "^3.1.2 || =2.5"}`, "ios", "2.5.0", true},
+		{`{"version": "^3.1.2 || =2.5"}`, "ios", "3.9.1", true},
+		{`{"version": "!=3.0.1"}`, "ios", "3.0.1", false},
+		{`{"version": ">=3.0.0"}`, "ios", "3.0.0-rc.1", false},
+		{`{"version": ">=3.0.0"}`, "ios", "broken", false},
+	}
+	for _, item := range items {
+		value := parseValue(t, item.value)
+		if ValidateRuleValue(ruleType, value) != nil {
+			t.Errorf("%s should be valid", item.value)
+			continue
+		}
+		os := item.os
+		version := item.version
+		inputData := InputRulesParameters{Platform: &Platform{OS: &os, AppVersion: &version}}
+		if ruleType.Match(inputData, value) != item.matches {
+			t.Errorf("%s for %s %s should give %t", item.value, item.os, item.version, item.matches)
+		}
+	}
+
+	if ruleType.Match(InputRulesParameters{}, parseValue(t, `{"version": ">=1"}`)) {
+		t.Error("It should not match without platform")
+	}
+
+	invalid := []string{`{}`, `{"version": ""}`, `{"version": ">=3.x"}`, `{"version": ">=3.1 ||"}`,
+		`{"version": "3.1", "os": ""}`, `{"version": "3.1", "build": 1}`, `">=3.1"`}
+	for _, item := range invalid {
+		if ValidateRuleValue(ruleType, parseValue(t, item)) == nil {
+			t.Errorf("%s should not be valid", item)
+		}
+	}
+}
+
+func TestRolloutRuleType(t *testing.T) {
+	ruleType := NewRolloutRuleType(1, "rollout")
+
+	value := parseValue(t, `{"percentage": 30, "salt": "new-dining"}`)
+	if ValidateRuleValue(ruleType, value) != nil {
+		t.Fatal("The value should be valid")
+	}
+
+	matched := 0
+	for i := 0; i < 1000; i++ {
+		user := &User{UUID: fmt.Sprintf("user-%d", i)}
+		first := ruleType.Match(InputRulesParameters{User: user}, value)
+		second := ruleType.Match(InputRulesParameters{User: user}, value)
+		if first != second {
+			t.Fatalf("The result for %s is not stable", user.UUID)
+		}
+		if first {
+			matched++
+		}
+	}
+	if matched < 250 || matched > 350 {
+		t.Errorf("Wrong number of matched users %d", matched)
+	}
+
+	if RolloutBucket("a", "user-1") == RolloutBucket("b", "user-1") && RolloutBucket("a", "user-2") == RolloutBucket("b", "user-2") {
+		t.Error("The salt does not affect the bucket")
+	}
+
+	if ruleType.Match(InputRulesParameters{User: &User{}}, value) {
+		t.Error("It should not match a user without uuid by default")
+	}
+	fallback := parseValue(t, `{"percentage": 0, "salt": "x", "fallback": true}`)
+	if !ruleType.Match(InputRulesParameters{}, fallback) {
+		t.Error("It should use the fallback when there is no user")
+	}
+	if ruleType.Match(InputRulesParameters{User: &User{UUID: "1"}}, fallback) {
+		t.Error("0 percentage should not match")
+	}
+
+	invalid := []string{`{"percentage": 101, "salt": "x"}`, `{"percentage": -1, "salt": "x"}`, `{"percentage": 10}`,
+		`{"percentage": 10, "salt": ""}`, `{"percentage": "10", "salt": "x"}`, `{"percentage": 10, "salt": "x", "fallback": 1}`}
+	for _, item := range invalid {
+		if ValidateRuleValue(ruleType, parseValue(t, item)) == nil {
+			t.Errorf("%s should not be valid", item)
+		}
+	}
+}
+
+func TestIlliniCashAndPlatformRuleTypes(t *testing.T) {
+	illiniCash := NewIlliniCashRuleType(1, "illini_cash")
+	platform := NewPlatformRuleType(2, "platform")
+
+	valid := map[RuleType]string{illiniCash: `{"housingResidenceStatus": true}`, platform: `{"os": "android"}`}
+	for ruleType, item := range valid {
+		if ValidateRuleValue(ruleType, parseValue(t, item)) != nil || ruleType.ValidData(parseValue(t, item)) != nil {
+			t.Errorf("%s should be valid for %s", item, ruleType.GetName())
+		}
+	}
+
+	invalid := map[RuleType][]string{
+		illiniCash: {`{}`, `{"housingResidenceStatus": "true"}`, `{"housingResidenceStatus": 1}`, `true`},
+		platform:   {`{}`, `{"os": true}`, `{"os": ["ios"]}`, `"ios"`},
+	}
+	for ruleType, items := range invalid {
+		for _, item := range items {
+			if ValidateRuleValue(ruleType, parseValue(t, item)) == nil {
+				t.Errorf("%s should not be valid for %s", item, ruleType.GetName())
+			}
+			if ruleType.ValidData(parseValue(t, item)) == nil {
+				t.Errorf("%s should not be valid for the rule type %s", item, ruleType.GetName())
+			}
+		}
+	}
+}
+
+func TestRuleTypesRegistry(t *testing.T) {
+	for _, name := range []string{"roles", "privacy", "auth", "illini_cash", "enable", "platform", "composite", "schedule", "app_version", "rollout", "attribute", "network", "locale", "header", "uuid_list", "geofence"} {
+		ruleType, err := NewRuleType(7, name)
+		if err != nil {
+			t.Errorf("%s should be registered - %s", name, err.Error())
+			continue
+		}
+		if ruleType.GetID() != 7 || ruleType.GetName() != name {
+			t.Errorf("Wrong rule type %d %s", ruleType.GetID(), ruleType.GetName())
+		}
+		registration, _ := GetRuleTypeRegistration(name)
+		if registration.Schema == nil {
+			t.Errorf("%s has no schema", name)
+		}
+	}
+
+	_, err := NewRuleType(1, "not_registered")
+	if err == nil {
+		t.Error("It should fail for not registered rule type")
+	}
+
+	err = RegisterRuleType(RuleTypeRegistration{Name: "enable", Factory: func(id int, name string) RuleType { return NewEnableRuleType(id, name) }})
+	if err == nil {
+		t.Error("It should not register the same name twice")
+	}
+}
+
+func TestValidateRuleValueErrors(t *testing.T) {
+	ruleType, _ := NewRuleType(1, "rollout")
+	err := ValidateRuleValue(ruleType, parseValue(t, `{"percentage": 120, "salt": "", "other": 1}`))
+	validationErrors, ok := err.(ValidationErrors)
+	if !ok {
+		t.Fatalf("Validation errors expected but got %v", err)
+	}
+	fields := map[string]bool{}
+	for _, item := range validationErrors {
+		fields[item.Field] = true
+	}
+	for _, field := range []string{"value.percentage", "value.salt", "value.other"} {
+		if !fields[field] {
+			t.Errorf("There is no error for %s - %s", field, err.Error())
+		}
+	}
+
+	ruleType, _ = NewRuleType(2, "schedule")
+	err = ValidateRuleValue(ruleType, parseValue(t, `{"weekly": [{"days": ["mon"], "from": "10:00", "to": "09:00"}]}`))
+	if err == nil || err.Error() != "value.weekly[0]: from must be before to" {
+		t.Errorf("Wrong schedule error %v", err)
+	}
+}
+
+type notCompilingRuleType struct {
+	EnableRuleType
+}
+
+func (rr notCompilingRuleType) Compile(ruleValue interface{}) (Predicate, error) {
+	return nil, errors.New("cannot be compiled")
+}
+
+func TestValidateRuleValueCompiles(t *testing.T) {
+	ruleType := notCompilingRuleType{NewEnableRuleType(1, "enable")}
+	err := ValidateRuleValue(ruleType, true)
+	if err == nil || err.Error() != "value: cannot be compiled" {
+		t.Errorf("Wrong compile error %v", err)
+	}
+}
+
+func TestRuleTypesMatchInvalidData(t *testing.T) {
+	invalid := []string{`null`, `1`, `"text"`, `[1, {}]`, `{"unknown": [null]}`}
+	for _, registration := range GetRuleTypeRegistrations() {
+		ruleType := registration.Factory(1, registration.Name)
+		for _, item := range invalid {
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						t.Errorf("%s panics for %s - %v", registration.Name, item, r)
+					}
+				}()
+				ruleType.Match(InputRulesParameters{}, parseValue(t, item))
+			}()
+		}
+	}
+}
+
+func TestAttributeRuleType(t *testing.T) {
+	ruleType, _ := NewRuleType(1, "attribute")
+	inputData := InputRulesParameters{Attributes: Attributes(parseValue(t,
+		`{"campus": "urbana", "year": 3, "staff": false, "majors": ["math", "cs"]}`).(map[string]interface{}))}
+	if inputData.Attributes.Validate() != nil {
+		t.Fatal("The attributes should be valid")
+	}
+
+	cases := map[string]bool{
+		`{"name": "campus", "op": "eq", "value": "urbana"}`:              true,
+		`{"name": "campus", "op": "ne", "value": "urbana"}`:              false,
+		`{"name": "staff", "op": "eq", "value": false}`:                  true,
+		`{"name": "campus", "op": "in", "value": ["chicago", "urbana"]}`: true,
+		`{"name": "majors", "op": "contains", "value": "cs"}`:            true,
+		`{"name": "campus", "op": "contains", "value": "rba"}`:           true,
+		`{"name": "year", "op": "gt", "value": 2}`:                       true,
+		`{"name": "year", "op": "lte", "value": 2}`:                      false,
+		`{"name": "year", "op": "lt", "value": 4, "not": true}`:          false,
+		`{"name": "campus", "op": "exists"}`:                             true,
+		`{"name": "college", "op": "exists", "not": true}`:               true,
+		`{"name": "college", "op": "ne", "value": "engineering"}`:        false,
+		`{"name": "campus", "op": "gt", "value": 1}`:                     false,
+	}
+	for value, expected := range cases {
+		ruleValue := parseValue(t, value)
+		if err := ValidateRuleValue(ruleType, ruleValue); err != nil {
+			t.Errorf("%s should be valid - %s", value, err.Error())
+			continue
+		}
+		if ruleType.Match(inputData, ruleValue) != expected {
+			t.Errorf("%s should give %t", value, expected)
+		}
+	}
+
+	invalid := []string{`{"name": "campus", "op": "like", "value": "u"}`, `{"name": "campus", "op": "exists", "value": true}`,
+		`{"name": "campus", "op": "in", "value": []}`, `{"name": "year", "op": "gt", "value": "2"}`, `{"op": "eq", "value": 1}`,
+		`{"name": "campus", "op": "eq", "value": {"a": 1}}`}
+	for _, value := range invalid {
+		if ValidateRuleValue(ruleType, parseValue(t, value)) == nil {
+			t.Errorf("%s should not be valid", value)
+		}
+	}
+
+	if (Attributes{"nested": map[string]interface{}{}}).Validate() == nil {
+		t.Error("Nested objects attributes should not be valid")
+	}
+}
+
+func TestNetworkRuleType(t *testing.T) {
+	ruleType, _ := NewRuleType(1, "network")
+	ruleValue := parseValue(t, `["192.17.0.0/16", "2620:0:e00::/48", "10.1.2.3"]`)
+	if err := ValidateRuleValue(ruleType, ruleValue); err != nil {
+		t.Fatalf("The value should be valid - %s", err.Error())
+	}
+
+	cases := map[string]bool{"192.17.40.5": true, "192.18.0.1": false, "10.1.2.3": true, "10.1.2.4": false,
+		"2620:0:e00:1::5": true, "2620:0:e01::5": false, "::ffff:192.17.1.1": true}
+	for ip, expected := range cases {
+		if ruleType.Match(InputRulesParameters{ClientIP: net.ParseIP(ip)}, ruleValue) != expected {
+			t.Errorf("%s should give %t", ip, expected)
+		}
+	}
+	if ruleType.Match(InputRulesParameters{}, ruleValue) {
+		t.Error("It should not match without client ip")
+	}
+
+	invalid := []string{`[]`, `["192.17.0.0/33"]`, `["campus"]`, `[1]`, `"10.0.0.0/8"`}
+	for _, value := range invalid {
+		if ValidateRuleValue(ruleType, parseValue(t, value)) == nil {
+			t.Errorf("%s should not be valid", value)
+		}
+	}
+}
+
+func TestLocaleRuleType(t *testing.T) {
+	ruleType, _ := NewRuleType(1, "locale")
+	ruleValue := parseValue(t, `["es", "pt-BR"]`)
+	if err := ValidateRuleValue(ruleType, ruleValue); err != nil {
+		t.Fatalf("The value should be valid - %s", err.Error())
+	}
+
+	cases := map[string]bool{"es-MX,es;q=0.9,en;q=0.8": true, "es": true, "pt_BR": true, "pt-PT": false,
+		"en-US,en;q=0.9,es;q=0.5": false, "en;q=0.5, es-419": true, "espanol": false, "*": false, "": false}
+	for acceptLanguage, expected := range cases {
+		inputData := InputRulesParameters{Headers: Headers{"Accept-Language": acceptLanguage}}
+		if ruleType.Match(inputData, ruleValue) != expected {
+			t.Errorf("%s should give %t", acceptLanguage, expected)
+		}
+	}
+	if ruleType.Match(InputRulesParameters{}, ruleValue) {
+		t.Error("It should not match without headers")
+	}
+	if ruleType.Match(InputRulesParameters{Headers: Headers{"Accept-Language": "es"}}, parseValue(t, `["es-MX"]`)) {
+		t.Error("es should not match es-MX")
+	}
+	if ValidateRuleValue(ruleType, parseValue(t, `[" "]`)) == nil {
+		t.Error("Empty locale should not be valid")
+	}
+}
+
+func TestHeaderRuleType(t *testing.T) {
+	ruleType, _ := NewRuleType(1, "header")
+	ruleValue := parseValue(t, `{"name": "user-agent", "pattern": "Illinois/3\\.1\\.0 \\(4[12]\\)"}`)
+	if err := ValidateRuleValue(ruleType, ruleValue); err != nil {
+		t.Fatalf("The value should be valid - %s", err.Error())
+	}
+
+	cases := map[string]bool{"Illinois/3.1.0 (41) iOS": true, "Illinois/3.1.0 (43) iOS": false, "Illinois/3.1.1 (41)": false}
+	for userAgent, expected := range cases {
+		inputData := InputRulesParameters{Headers: Headers{"User-Agent": userAgent}}
+		if ruleType.Match(inputData, ruleValue) != expected {
+			t.Errorf("%s should give %t", userAgent, expected)
+		}
+	}
+	if ruleType.Match(InputRulesParameters{}, ruleValue) {
+		t.Error("It should not match without the header")
+	}
+
+	invalid := []string{`{"name": "User-Agent", "pattern": "("}`, `{"pattern": "a"}`, `{"name": "User-Agent"}`}
+	for _, value := range invalid {
+		if ValidateRuleValue(ruleType, parseValue(t, value)) == nil {
+			t.Errorf("%s should not be valid", value)
+		}
+	}
+}
+
+func TestUUIDListRuleType(t *testing.T) {
+	ruleType, _ := NewRuleType(1, "uuid_list")
+	uuidLists := NewUUIDLists([]UUIDList{{ID: 1, Name: "pilot", UUIDs: []string{"uuid-1", "uuid-2"}}})
+	pilotUser := &User{UUID: "uuid-2"}
+	otherUser := &User{UUID: "uuid-3"}
+
+	cases := []struct {
+		value    string
+		user     *User
+		expected bool
+	}{
+		{`{"list": "pilot"}`, pilotUser, true},
+		{`{"list": "pilot", "mode": "allow"}`, otherUser, false},
+		{`{"list": "pilot"}`, nil, false},
+		{`{"list": "pilot", "mode": "deny"}`, pilotUser, false},
+		{`{"list": "pilot", "mode": "deny"}`, otherUser, true},
+		{`{"list": "pilot", "mode": "deny"}`, nil, true},
+		{`{"list": "missing"}`, pilotUser, false},
+		{`{"list": "missing", "mode": "deny"}`, otherUser, false},
+	}
+	for _, item := range cases {
+		ruleValue := parseValue(t, item.value)
+		if err := ValidateRuleValue(ruleType, ruleValue); err != nil {
+			t.Errorf("%s should be valid - %s", item.value, err.Error())
+			continue
+		}
+		inputData := InputRulesParameters{User: item.user, UUIDLists: uuidLists}
+		if ruleType.Match(inputData, ruleValue) != item.expected {
+			t.Errorf("%s for %v should give %t", item.value, item.user, item.expected)
+		}
+	}
+
+	invalid := []string{`{"list": ""}`, `{"list": "pilot", "mode": "block"}`, `{"mode": "allow"}`, `"pilot"`}
+	for _, value := range invalid {
+		if ValidateRuleValue(ruleType, parseValue(t, value)) == nil {
+			t.Errorf("%s should not be valid", value)
+		}
+	}
+
+	uuidList := UUIDList{Name: " ", UUIDs: []string{"uuid-1", ""}}
+	validationErrors, ok := uuidList.Validate().(ValidationErrors)
+	if !ok || len(validationErrors) != 2 || validationErrors[0].Field != "name" || validationErrors[1].Field != "uuids[1]" {
+		t.Errorf("Wrong uuid list validation errors %v", validationErrors)
+	}
+}
+
+func TestReferencedUUIDLists(t *testing.T) {
+	cases := []struct {
+		ruleType string
+		value    string
+		expected string
+	}{
+		{"uuid_list", `{"list": "pilot"}`, "[pilot]"},
+		{"uuid_list", `{"mode": "allow"}`, "[]"},
+		{"composite", `{"or": [{"uuid_list": {"list": "pilot"}}, {"not": {"uuid_list": {"list": "staff", "mode": "deny"}}}]}`, "[pilot staff]"},
+		{"composite", `{"and": [{"auth": {"shibbolethLoggedIn": true}}, {"platform": {"os": "ios"}}]}`, "[]"},
+		{"auth", `{"shibbolethLoggedIn": true}`, "[]"},
+	}
+	for _, item := range cases {
+		result := fmt.Sprintf("%v", ReferencedUUIDLists(item.ruleType, parseValue(t, item.value)))
+		if result != item.expected {
+			t.Errorf("%s %s should reference %s but references %s", item.ruleType, item.value, item.expected, result)
+		}
+	}
+}
+
+func TestGeofenceRuleType(t *testing.T) {
+	ruleType, _ := NewRuleType(1, "geofence")
+	quad := &Location{Latitude: 40.1074, Longitude: -88.2272}
+	chicago := &Location{Latitude: 41.8781, Longitude: -87.6298}
+
+	circle := `{"circle": {"latitude": 40.1020, "longitude": -88.2272, "radius": 1500}}`
+	polygon := `{"polygon": [{"latitude": 40.09, "longitude": -88.24}, {"latitude": 40.12, "longitude": -88.24},
+		{"latitude": 40.12, "longitude": -88.21}, {"latitude": 40.09, "longitude": -88.21}], "fallback": true}`
+	cases := []struct {
+		value    string
+		location *Location
+		expected bool
+	}{
+		{circle, quad, true},
+		{circle, chicago, false},
+		{circle, nil, false},
+		{polygon, quad, true},
+		{polygon, chicago, false},
+		{polygon, nil, true},
+	}
+	for _, item := range cases {
+		ruleValue := parseValue(t, item.value)
+		if err := ValidateRuleValue(ruleType, ruleValue); err != nil {
+			t.Errorf("%s should be valid - %s", item.value, err.Error())
+			continue
+		}
+		if ruleType.Match(InputRulesParameters{Location: item.location}, ruleValue) != item.expected {
+			t.Errorf("%s for %v should give %t", item.value, item.location, item.expected)
+		}
+	}
+
+	invalid := []string{
+		`{"fallback": true}`,
+		`{"circle": {"latitude": 40.1, "longitude": -88.2, "radius": 0}}`,
+		`{"circle": {"latitude": 91, "longitude": -88.2, "radius": 10}}`,
+		`{"polygon": [{"latitude": 40.09, "longitude": -88.24}, {"latitude": 40.12, "longitude": -88.24}]}`,
+		`{"polygon": [{"latitude": 40, "longitude": -88}, {"latitude": 41, "longitude": -88}, {"latitude": 42, "longitude": -88}]}`,
+		`{"polygon": [{"latitude": 40, "longitude": -88}, {"latitude": 42, "longitude": -87},
+			{"latitude": 40, "longitude": -87}, {"latitude": 41, "longitude": -88}]}`,
+		`{"circle": {"latitude": 40.1, "longitude": -88.2, "radius": 10}, "polygon": []}`,
+	}
+	for _, value := range invalid {
+		if ruleType.ValidData(parseValue(t, value)) == nil {
+			t.Errorf("%s should not be valid", value)
+		}
+	}
+}
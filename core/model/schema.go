@@ -0,0 +1,329 @@
+/*
+ *   Copyright (c) 2020 Board of Trustees of the University of Illinois.
+ *   All rights reserved.
+
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+
+ *   http://www.apache.org/licenses/LICENSE-2.0
+
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package model
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+//ValidationError represents a validation error for a field of a rule value
+type ValidationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+//ValidationErrors represents the validation errors of a rule value
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	items := make([]string, len(e))
+	for i, item := range e {
+		items[i] = fmt.Sprintf("%s: %s", item.Field, item.Message)
+	}
+	return strings.Join(items, "; ")
+}
+
+func newValidationError(field string, message string) ValidationErrors {
+	return ValidationErrors{{Field: field, Message: message}}
+}
+
+//ValidateRuleValue validates the value against the schema of the rule type, then against the rule type own checks and
+//finally it checks that the value can be compiled.
+//It gives ValidationErrors when the value is not valid.
+func ValidateRuleValue(ruleType RuleType, value interface{}) error {
+	errs := validateRuleValue(ruleType, value, "value")
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateRuleValue(ruleType RuleType, value interface{}, field string) ValidationErrors {
+	if value == nil {
+		return ValidationErrors{{Field: field, Message: "is required"}}
+	}
+
+	registration, exist := GetRuleTypeRegistration(ruleType.GetName())
+	if exist && registration.Schema != nil {
+		errs := ValidateSchema(registration.Schema, value, field)
+		if len(errs) > 0 {
+			return errs
+		}
+	}
+
+	errs := toValidationErrors(ruleType.ValidData(value), field)
+	if len(errs) > 0 {
+		return errs
+	}
+
+	//the loaded data versions are compiled so a value which cannot be compiled must never be stored
+	_, err := ruleType.Compile(value)
+	return toValidationErrors(err, field)
+}
+
+//toValidationErrors gives the error as validation errors where the fields are relative to the provided field
+func toValidationErrors(err error, field string) ValidationErrors {
+	if err == nil {
+		return nil
+	}
+	errs, ok := err.(ValidationErrors)
+	if !ok {
+		return ValidationErrors{{Field: field, Message: err.Error()}}
+	}
+	result := make(ValidationErrors, len(errs))
+	for i, item := range errs {
+		result[i] = ValidationError{Field: field + strings.TrimPrefix(item.Field, "value"), Message: item.Message}
+	}
+	return result
+}
+
+//ValidateSchema validates the value against a JSON schema. It supports the type, enum, const, minimum, maximum,
+//exclusiveMinimum, exclusiveMaximum, minLength, maxLength, pattern, format (date-time), items, minItems, maxItems,
+//properties, required, additionalProperties, minProperties, maxProperties, anyOf and not keywords.
+func ValidateSchema(schema map[string]interface{}, value interface{}, field string) ValidationErrors {
+	var errs ValidationErrors
+	add := func(format string, a ...interface{}) {
+		errs = append(errs, ValidationError{Field: field, Message: fmt.Sprintf(format, a...)})
+	}
+
+	if types, exist := schema["type"]; exist {
+		if !matchesSchemaType(types, value) {
+			add("must be %s", describeSchemaType(types))
+			return errs
+		}
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, item := range enum {
+			if schemaEqual(item, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			add("must be one of %v", enum)
+		}
+	}
+	if constValue, exist := schema["const"]; exist && !schemaEqual(constValue, value) {
+		add("must be %v", constValue)
+	}
+
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		var first, sameType ValidationErrors
+		matched := false
+		for _, item := range anyOf {
+			itemSchema, _ := item.(map[string]interface{})
+			itemErrs := ValidateSchema(itemSchema, value, field)
+			if len(itemErrs) == 0 {
+				matched = true
+				break
+			}
+			if first == nil {
+				first = itemErrs
+			}
+			if types, exist := itemSchema["type"]; sameType == nil && exist && matchesSchemaType(types, value) {
+				sameType = itemErrs
+			}
+		}
+		if !matched {
+			//give the errors of the alternative for the value type, otherwise the first alternative is the preferred one
+			if sameType != nil {
+				first = sameType
+			}
+			errs = append(errs, first...)
+		}
+	}
+	if not, ok := schema["not"].(map[string]interface{}); ok {
+		if len(ValidateSchema(not, value, field)) == 0 {
+			add("is not allowed")
+		}
+	}
+
+	switch v := value.(type) {
+	case float64:
+		if minimum, ok := schema["minimum"].(float64); ok && v < minimum {
+			add("must be >= %v", minimum)
+		}
+		if maximum, ok := schema["maximum"].(float64); ok && v > maximum {
+			add("must be <= %v", maximum)
+		}
+		if minimum, ok := schema["exclusiveMinimum"].(float64); ok && v <= minimum {
+			add("must be > %v", minimum)
+		}
+		if maximum, ok := schema["exclusiveMaximum"].(float64); ok && v >= maximum {
+			add("must be < %v", maximum)
+		}
+	case string:
+		length := len([]rune(v))
+		if minLength, ok := schema["minLength"].(float64); ok && length < int(minLength) {
+			if minLength == 1 {
+				add("cannot be empty")
+			} else {
+				add("must be at least %v characters", minLength)
+			}
+		}
+		if maxLength, ok := schema["maxLength"].(float64); ok && length > int(maxLength) {
+			add("must be at most %v characters", maxLength)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			re, err := regexp.Compile(pattern)
+			if err != nil || !re.MatchString(v) {
+				add("must match %s", pattern)
+			}
+		}
+		if format, ok := schema["format"].(string); ok && format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, v); err != nil {
+				add("must be a RFC 3339 date-time")
+			}
+		}
+	case []interface{}:
+		if minItems, ok := schema["minItems"].(float64); ok && len(v) < int(minItems) {
+			if minItems == 1 {
+				add("cannot be empty")
+			} else {
+				add("must have at least %v items", minItems)
+			}
+		}
+		if maxItems, ok := schema["maxItems"].(float64); ok && len(v) > int(maxItems) {
+			add("must have at most %v items", maxItems)
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				errs = append(errs, ValidateSchema(items, item, fmt.Sprintf("%s[%d]", field, i))...)
+			}
+		}
+	case map[string]interface{}:
+		if minProperties, ok := schema["minProperties"].(float64); ok && len(v) < int(minProperties) {
+			if minProperties == 1 {
+				add("cannot be empty")
+			} else {
+				add("must have at least %v fields", minProperties)
+			}
+		}
+		if maxProperties, ok := schema["maxProperties"].(float64); ok && len(v) > int(maxProperties) {
+			add("must have at most %v fields", maxProperties)
+		}
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, item := range required {
+				name, _ := item.(string)
+				if _, exist := v[name]; !exist {
+					errs = append(errs, ValidationError{Field: field + "." + name, Message: "is required"})
+				}
+			}
+		}
+
+		properties, _ := schema["properties"].(map[string]interface{})
+		//sort the keys for stable errors
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			keyField := field + "." + key
+			if propertySchema, ok := properties[key].(map[string]interface{}); ok {
+				errs = append(errs, ValidateSchema(propertySchema, v[key], keyField)...)
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					errs = append(errs, ValidationError{Field: keyField, Message: "is not supported"})
+				}
+			case map[string]interface{}:
+				errs = append(errs, ValidateSchema(additional, v[key], keyField)...)
+			}
+		}
+	}
+	return errs
+}
+
+func matchesSchemaType(types interface{}, value interface{}) bool {
+	switch t := types.(type) {
+	case string:
+		return matchesSchemaSingleType(t, value)
+	case []interface{}:
+		for _, item := range t {
+			name, _ := item.(string)
+			if matchesSchemaSingleType(name, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchesSchemaSingleType(name string, value interface{}) bool {
+	switch name {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		number, ok := value.(float64)
+		return ok && number == math.Trunc(number)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	}
+	return false
+}
+
+func describeSchemaType(types interface{}) string {
+	switch t := types.(type) {
+	case string:
+		return withArticle(t)
+	case []interface{}:
+		items := make([]string, len(t))
+		for i, item := range t {
+			items[i] = withArticle(fmt.Sprint(item))
+		}
+		return strings.Join(items, " or ")
+	}
+	return fmt.Sprint(types)
+}
+
+func withArticle(name string) string {
+	switch name {
+	case "object", "array", "integer":
+		return "an " + name
+	case "null":
+		return name
+	}
+	return "a " + name
+}
+
+func schemaEqual(a interface{}, b interface{}) bool {
+	return fmt.Sprintf("%#v", a) == fmt.Sprintf("%#v", b)
+}
@@ -0,0 +1,48 @@
+/*
+ *   Copyright (c) 2020 Board of Trustees of the University of Illinois.
+ *   All rights reserved.
+
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+
+ *   http://www.apache.org/licenses/LICENSE-2.0
+
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package model
+
+//UIItemTrace represents how the rules of an ui item were evaluated
+type UIItemTrace struct {
+	ID      int         `json:"id"`
+	Name    string      `json:"name"`
+	Order   int         `json:"order"`
+	Matched bool        `json:"matched"`
+	Rules   []RuleTrace `json:"rules"`
+
+	MatchMode string `json:"match-mode"`
+
+	DataCategories          []string `json:"data-categories"`
+	DataCategoriesPermitted bool     `json:"data-categories-permitted"`
+
+	//Children are the nested ui items traces, they are not matched if the ui item is not matched
+	Children []UIItemTrace `json:"children"`
+}
+
+//RuleTrace represents how a rule was evaluated
+type RuleTrace struct {
+	ID        int         `json:"id"`
+	RuleType  string      `json:"rule-type"`
+	Value     interface{} `json:"value"`
+	Matched   bool        `json:"matched"`
+	Negate    bool        `json:"negate"`
+	OnMissing string      `json:"on-missing"`
+	//Missing are the inputs which the rule needs but they were not provided
+	Missing []string `json:"missing"`
+	Error   string   `json:"error,omitempty"`
+}
@@ -27,6 +27,34 @@ type UIItem struct {
 	Name  string  `json:"name"`
 	Order int     `json:"order"`
 	Rules *[]Rule `json:"rules"`
+
+	//DataCategories are the user data categories which the ui item needs, it is hidden if the user privacy level does not permit them
+	DataCategories []string `json:"data-categories"`
+	//MatchMode is how the rules results are combined - all, any or none, all if empty
+	MatchMode string `json:"match-mode"`
+	//Children are the nested ui items, they are shown only if the ui item is shown
+	Children []UIItem `json:"children"`
+	//Payload is arbitrary JSON data which is given to the clients with the ui item, for example title, icon and link
+	Payload interface{} `json:"payload"`
+}
+
+//The ui item match modes
+const (
+	//MatchModeAll shows the ui item if all the rules match
+	MatchModeAll = "all"
+	//MatchModeAny shows the ui item if at least one rule matches
+	MatchModeAny = "any"
+	//MatchModeNone shows the ui item if no rule matches
+	MatchModeNone = "none"
+)
+
+//ValidateMatchMode checks if the match mode is supported, empty is the same as all
+func ValidateMatchMode(matchMode string) error {
+	switch matchMode {
+	case "", MatchModeAll, MatchModeAny, MatchModeNone:
+		return nil
+	}
+	return ValidationErrors{{Field: "match-mode", Message: "must be one of all, any and none"}}
 }
 
 //String gives the string representation of the ui item
@@ -37,5 +65,9 @@ func (uiItem UIItem) String() string {
 			rules = fmt.Sprintf("%s %d %s %s\n\t\t\t", rules, rule.ID, rule.RuleType.GetName(), rule.Value)
 		}
 	}
-	return fmt.Sprintf("name:%s\n\t\trules:\n\t\t[\n\t\t\t%s\n\t\t]", uiItem.Name, rules)
+	var children string
+	for _, child := range uiItem.Children {
+		children = fmt.Sprintf("%s %s\n\t\t\t", children, child.String())
+	}
+	return fmt.Sprintf("name:%s\n\t\trules:\n\t\t[\n\t\t\t%s\n\t\t]\n\t\tchildren:\n\t\t[\n\t\t\t%s\n\t\t]", uiItem.Name, rules, children)
 }
@@ -0,0 +1,77 @@
+/*
+ *   Copyright (c) 2020 Board of Trustees of the University of Illinois.
+ *   All rights reserved.
+
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+
+ *   http://www.apache.org/licenses/LICENSE-2.0
+
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+//UUIDList represents a named list of user uuids which the uuid_list rules reference by name
+type UUIDList struct {
+	ID    int      `json:"id"`
+	Name  string   `json:"name"`
+	UUIDs []string `json:"uuids"`
+}
+
+//Validate checks if the list has a name and all the uuids are not empty
+func (uuidList UUIDList) Validate() error {
+	var validationErrors ValidationErrors
+	if len(strings.TrimSpace(uuidList.Name)) == 0 {
+		validationErrors = append(validationErrors, ValidationError{Field: "name", Message: "must be a non empty string"})
+	}
+	for index, uuid := range uuidList.UUIDs {
+		if len(strings.TrimSpace(uuid)) == 0 {
+			validationErrors = append(validationErrors, ValidationError{Field: fmt.Sprintf("uuids[%d]", index), Message: "must be a non empty string"})
+		}
+	}
+	if len(validationErrors) > 0 {
+		return validationErrors
+	}
+	return nil
+}
+
+//UUIDSet represents a set of user uuids
+type UUIDSet map[string]struct{}
+
+//Contains checks if the uuid is in the set
+func (uuidSet UUIDSet) Contains(uuid string) bool {
+	_, ok := uuidSet[uuid]
+	return ok
+}
+
+//NewUUIDSet creates uuid set instance
+func NewUUIDSet(uuids []string) UUIDSet {
+	uuidSet := make(UUIDSet, len(uuids))
+	for _, uuid := range uuids {
+		uuidSet[uuid] = struct{}{}
+	}
+	return uuidSet
+}
+
+//UUIDLists represents the uuid lists of a data version by name
+type UUIDLists map[string]UUIDSet
+
+//NewUUIDLists creates the uuid sets for the lists
+func NewUUIDLists(uuidLists []UUIDList) UUIDLists {
+	result := make(UUIDLists, len(uuidLists))
+	for _, uuidList := range uuidLists {
+		result[uuidList.Name] = NewUUIDSet(uuidList.UUIDs)
+	}
+	return result
+}
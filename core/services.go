@@ -20,7 +20,7 @@ package core
 import (
 	"fmt"
 	"log"
-	"sort"
+	"net"
 	"talent-chooser/core/model"
 )
 
@@ -32,7 +32,7 @@ func (app *Application) getUIContent(user *model.User, dataVersion string, auth
 	app.printGetUIContentParameters(user, dataVersion, auth, illiniCash)
 
 	inputRulesparameters := model.InputRulesParameters{
-		User: user, Auth: auth, AuthV2: nil, AuthV3: nil, AuthVersion: "1", IlliniCash: illiniCash, Platform: nil}
+		User: user, Auth: auth, AuthV2: nil, AuthV3: nil, AuthVersion: "1", IlliniCash: illiniCash, Platform: nil, GroupAliases: app.groupAliases}
 	readyData := app.prepareData(dataVersion, inputRulesparameters)
 	return readyData
 }
@@ -41,64 +41,160 @@ func (app *Application) getUIContentV2(user *model.User, dataVersion string, aut
 	app.printGetUIContentV2Parameters(user, dataVersion, auth, illiniCash)
 
 	inputRulesparameters := model.InputRulesParameters{
-		User: user, Auth: nil, AuthV2: auth, AuthV3: nil, AuthVersion: "2", IlliniCash: illiniCash, Platform: nil}
+		User: user, Auth: nil, AuthV2: auth, AuthV3: nil, AuthVersion: "2", IlliniCash: illiniCash, Platform: nil, GroupAliases: app.groupAliases}
 	readyData := app.prepareData(dataVersion, inputRulesparameters)
 	return readyData
 }
 
-func (app *Application) getUIContentV3(user *model.User, dataVersion string, auth *model.AuthV3, illiniCash *model.IlliniCash, platform *model.Platform) map[string][]string {
+func (app *Application) getUIContentV3(user *model.User, dataVersion string, auth *model.AuthV3, illiniCash *model.IlliniCash, platform *model.Platform, attributes model.Attributes, clientIP net.IP, headers model.Headers, location *model.Location) map[string][]string {
 	app.printGetUIContentV3Parameters(user, dataVersion, auth, illiniCash, platform)
 
-	inputRulesparameters := model.InputRulesParameters{
-		User: user, Auth: nil, AuthV2: nil, AuthV3: auth, AuthVersion: "3", IlliniCash: illiniCash, Platform: platform}
+	inputRulesparameters := app.newInputRulesParametersV3(user, auth, illiniCash, platform, attributes, clientIP, headers, location)
 	readyData := app.prepareData(dataVersion, inputRulesparameters)
 	return readyData
 }
 
-//apply rules and sort
-func (app *Application) prepareData(dataVersion string, inputRulesParameters model.InputRulesParameters) map[string][]string {
-	result := make(map[string][]string)
-	allData := app.getData()
-	data := allData[dataVersion]
+func (app *Application) getUIContentV4(user *model.User, dataVersion string, auth *model.AuthV3, illiniCash *model.IlliniCash, platform *model.Platform, attributes model.Attributes, clientIP net.IP, headers model.Headers, location *model.Location) map[string][]model.UIContentNode {
+	app.printGetUIContentV3Parameters(user, dataVersion, auth, illiniCash, platform)
+
+	inputRulesparameters := app.newInputRulesParametersV3(user, auth, illiniCash, platform, attributes, clientIP, headers, location)
+	readyData := app.prepareDataTree(dataVersion, inputRulesparameters)
+	return readyData
+}
+
+func (app *Application) getUIContentV5(user *model.User, dataVersion string, auth *model.AuthV3, illiniCash *model.IlliniCash, platform *model.Platform, attributes model.Attributes, clientIP net.IP, headers model.Headers, location *model.Location, locales []string) map[string]model.ContentItemPayloadNode {
+	app.printGetUIContentV3Parameters(user, dataVersion, auth, illiniCash, platform)
+
+	inputRulesparameters := app.newInputRulesParametersV3(user, auth, illiniCash, platform, attributes, clientIP, headers, location)
+	localeChain := app.localeFallbacks.Chain(locales, app.defaultLocale)
+	readyData := app.prepareDataPayloads(dataVersion, inputRulesparameters, localeChain)
+	return readyData
+}
+
+func (app *Application) explainUIContentV3(user *model.User, dataVersion string, auth *model.AuthV3, illiniCash *model.IlliniCash, platform *model.Platform, attributes model.Attributes, clientIP net.IP, headers model.Headers, location *model.Location) map[string][]model.UIItemTrace {
+	app.printGetUIContentV3Parameters(user, dataVersion, auth, illiniCash, platform)
+
+	inputRulesparameters := app.newInputRulesParametersV3(user, auth, illiniCash, platform, attributes, clientIP, headers, location)
+	return app.explainData(dataVersion, inputRulesparameters)
+}
+
+func (app *Application) newInputRulesParametersV3(user *model.User, auth *model.AuthV3, illiniCash *model.IlliniCash, platform *model.Platform, attributes model.Attributes, clientIP net.IP, headers model.Headers, location *model.Location) model.InputRulesParameters {
+	return model.InputRulesParameters{
+		User: user, Auth: nil, AuthV2: nil, AuthV3: auth, AuthVersion: "3", IlliniCash: illiniCash, Platform: platform, Attributes: attributes, ClientIP: clientIP, Headers: headers, Location: location, GroupAliases: app.groupAliases}
+}
+
+//apply rules on the compiled data which is already sorted, the handle function is called for every content item
+//which has matched ui items
+func (app *Application) matchData(dataVersion string, inputRulesParameters model.InputRulesParameters,
+	handle func(contentItem model.CompiledContentItem, uiItems []model.CompiledUIItem, inputRulesParameters model.InputRulesParameters)) {
+	data := app.getCompiledData(dataVersion)
+	if data == nil {
+		return
+	}
+	inputRulesParameters.UUIDLists = data.UUIDLists
 	for _, item := range data.Data {
-		name := item.Name
-		uiItems := item.UIItems
-
-		if uiItems != nil {
-			//sort
-			sort.Slice(uiItems, func(i, j int) bool {
-				return uiItems[i].Order < uiItems[j].Order
-			})
-
-			//apply rules
-			var uiItemsList []string
-			for _, uiItem := range uiItems {
-				if app.matchRules(uiItem, inputRulesParameters) {
-					uiItemsList = append(uiItemsList, uiItem.Name)
-				}
+		var uiItems []model.CompiledUIItem
+		for _, uiItem := range item.UIItems {
+			if uiItem.Match(inputRulesParameters) {
+				uiItems = append(uiItems, uiItem)
 			}
+		}
 
-			if len(uiItemsList) > 0 {
-				result[name] = uiItemsList
-			}
+		if len(uiItems) > 0 {
+			handle(item, uiItems, inputRulesParameters)
 		}
 	}
+}
+
+//gives the names of the matched ui items
+func (app *Application) prepareData(dataVersion string, inputRulesParameters model.InputRulesParameters) map[string][]string {
+	result := make(map[string][]string)
+	app.matchData(dataVersion, inputRulesParameters, func(contentItem model.CompiledContentItem, uiItems []model.CompiledUIItem, inputRulesParameters model.InputRulesParameters) {
+		uiItemsList := make([]string, len(uiItems))
+		for index, uiItem := range uiItems {
+			uiItemsList[index] = uiItem.Name
+		}
+		result[contentItem.Name] = uiItemsList
+	})
+	return result
+}
+
+//gives the matched ui items keeping the nested ui items, the children of a not shown ui item are not shown
+func (app *Application) prepareDataTree(dataVersion string, inputRulesParameters model.InputRulesParameters) map[string][]model.UIContentNode {
+	result := make(map[string][]model.UIContentNode)
+	app.matchData(dataVersion, inputRulesParameters, func(contentItem model.CompiledContentItem, uiItems []model.CompiledUIItem, inputRulesParameters model.InputRulesParameters) {
+		uiItemsList := make([]model.UIContentNode, len(uiItems))
+		for index, uiItem := range uiItems {
+			uiItemsList[index] = model.UIContentNode{Name: uiItem.Name, Children: uiItem.MatchChildren(inputRulesParameters)}
+		}
+		result[contentItem.Name] = uiItemsList
+	})
+	return result
+}
+
+//gives the matched ui items keeping the nested ui items and giving the content items and ui items payloads,
+//the ui items strings are resolved for the locale chain
+func (app *Application) prepareDataPayloads(dataVersion string, inputRulesParameters model.InputRulesParameters, localeChain []string) map[string]model.ContentItemPayloadNode {
+	result := make(map[string]model.ContentItemPayloadNode)
+	app.matchData(dataVersion, inputRulesParameters, func(contentItem model.CompiledContentItem, uiItems []model.CompiledUIItem, inputRulesParameters model.InputRulesParameters) {
+		uiItemsList := make([]model.UIItemPayloadNode, len(uiItems))
+		for index, uiItem := range uiItems {
+			uiItemsList[index] = uiItem.PayloadNode(inputRulesParameters, localeChain)
+		}
+		result[contentItem.Name] = model.ContentItemPayloadNode{Payload: contentItem.Payload, UIItems: uiItemsList}
+	})
 	return result
 }
 
-func (app *Application) matchRules(uiItem model.UIItem, inputRulesParams model.InputRulesParameters) bool {
-	itemRules := uiItem.Rules
-	if itemRules == nil {
-		return true //if no rules it matches
+//evaluate every rule for every ui item without skipping anything
+func (app *Application) explainData(dataVersion string, inputRulesParameters model.InputRulesParameters) map[string][]model.UIItemTrace {
+	result := make(map[string][]model.UIItemTrace)
+	data := app.getCompiledData(dataVersion)
+	if data == nil {
+		return result
+	}
+	inputRulesParameters.UUIDLists = data.UUIDLists
+	for _, item := range data.Data {
+		traces := make([]model.UIItemTrace, len(item.UIItems))
+		for index, uiItem := range item.UIItems {
+			traces[index] = app.explainRules(uiItem, inputRulesParameters, true)
+		}
+		result[item.Name] = traces
 	}
+	return result
+}
 
-	for _, rule := range *itemRules {
-		matches := rule.Match(inputRulesParams)
-		if !matches {
-			return false //does not match if any of them does not match
+func (app *Application) explainRules(uiItem model.CompiledUIItem, inputRulesParams model.InputRulesParameters, parentMatched bool) model.UIItemTrace {
+	permitted := uiItem.PermitsDataCategories(inputRulesParams)
+	trace := model.UIItemTrace{ID: uiItem.ID, Name: uiItem.Name, Order: uiItem.Order, Rules: []model.RuleTrace{},
+		MatchMode: uiItem.MatchMode, DataCategories: uiItem.DataCategories, DataCategoriesPermitted: permitted}
+	results := make([]model.RuleResult, len(uiItem.Rules))
+	for index, compiledRule := range uiItem.Rules {
+		rule := compiledRule.Rule
+		ruleTypeName := ""
+		if rule.RuleType != nil {
+			ruleTypeName = rule.RuleType.GetName()
+		}
+		result := compiledRule.Evaluate(inputRulesParams)
+		results[index] = result
+		missing := result.Missing
+		if missing == nil {
+			missing = []string{}
+		}
+		ruleTrace := model.RuleTrace{ID: rule.ID, RuleType: ruleTypeName, Value: rule.Value, Matched: result.Matched,
+			Negate: rule.Negate, OnMissing: compiledRule.OnMissing, Missing: missing}
+		if result.Err != nil {
+			ruleTrace.Error = result.Err.Error()
 		}
+		trace.Rules = append(trace.Rules, ruleTrace)
+	}
+	trace.Matched = parentMatched && permitted && uiItem.CombineResults(results)
+
+	trace.Children = make([]model.UIItemTrace, len(uiItem.Children))
+	for index, child := range uiItem.Children {
+		trace.Children[index] = app.explainRules(child, inputRulesParams, trace.Matched)
 	}
-	return true
+	return trace
 }
 
 func (app *Application) printGetUIContentParameters(user *model.User, dataVersion string, auth *model.Auth, illiniCash *model.IlliniCash) {
@@ -0,0 +1,82 @@
+/*
+ *   Copyright (c) 2020 Board of Trustees of the University of Illinois.
+ *   All rights reserved.
+
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+
+ *   http://www.apache.org/licenses/LICENSE-2.0
+
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/json"
+	"testing"
+
+	"talent-chooser/core/model"
+)
+
+func newTestServicesApplication(uiContent *model.UIContent) *Application {
+	app := NewApplication("1.0", "1", newTestStorage(), nil, nil, nil, "en")
+	app.setData(nil, map[string]*model.CompiledUIContent{"1.0": model.CompileUIContent(uiContent, nil)})
+	app.setDataStatus(true)
+	return app
+}
+
+func TestPrepareData(t *testing.T) {
+	enable := model.NewEnableRuleType(1, "enable")
+	disabled := &[]model.Rule{{ID: 1, RuleType: enable, Value: false}}
+	uiItems := []model.UIItem{
+		{ID: 1, Name: "second", Order: 2, Children: []model.UIItem{{ID: 4, Name: "child", Order: 1}, {ID: 5, Name: "hidden", Order: 2, Rules: disabled}}},
+		{ID: 2, Name: "first", Order: 1},
+		{ID: 3, Name: "disabled", Order: 0, Rules: disabled, Children: []model.UIItem{{ID: 6, Name: "orphan", Order: 1}}},
+	}
+	app := newTestServicesApplication(&model.UIContent{Data: []model.ContentItem{
+		{ID: 1, Name: "browse", UIItems: uiItems},
+		{ID: 2, Name: "empty", UIItems: []model.UIItem{{ID: 7, Name: "never", Rules: disabled}}}}})
+
+	flat := app.prepareData("1.0", model.InputRulesParameters{})
+	if data, _ := json.Marshal(flat); string(data) != `{"browse":["first","second"]}` {
+		t.Errorf("Wrong flat data %s", data)
+	}
+
+	tree := app.prepareDataTree("1.0", model.InputRulesParameters{})
+	expected := `{"browse":[{"name":"first","children":[]},{"name":"second","children":[{"name":"child","children":[]}]}]}`
+	if data, _ := json.Marshal(tree); string(data) != expected {
+		t.Errorf("Wrong tree data %s", data)
+	}
+
+	if len(app.prepareData("2.0", model.InputRulesParameters{})) != 0 {
+		t.Error("There is no data for a not existing data version")
+	}
+}
+
+func TestPrepareDataPayloads(t *testing.T) {
+	uiItems := []model.UIItem{{ID: 1, Name: "events", Order: 1, Payload: map[string]interface{}{"icon": "calendar"},
+		Children: []model.UIItem{{ID: 2, Name: "today", Order: 1}}}}
+	app := newTestServicesApplication(&model.UIContent{Data: []model.ContentItem{
+		{ID: 1, Name: "browse", Payload: map[string]interface{}{"title": "Browse"}, UIItems: uiItems}}})
+
+	payloads := app.prepareDataPayloads("1.0", model.InputRulesParameters{}, nil)
+	browse, exist := payloads["browse"]
+	if !exist || len(payloads) != 1 {
+		t.Fatalf("Wrong content items %v", payloads)
+	}
+	if data, _ := json.Marshal(browse.Payload); string(data) != `{"title":"Browse"}` {
+		t.Errorf("Wrong content item payload %s", data)
+	}
+	if len(browse.UIItems) != 1 || browse.UIItems[0].Name != "events" || len(browse.UIItems[0].Children) != 1 {
+		t.Fatalf("Wrong ui items %v", browse.UIItems)
+	}
+	if data, _ := json.Marshal(browse.UIItems[0].Payload); string(data) != `{"icon":"calendar"}` {
+		t.Errorf("Wrong ui item payload %s", data)
+	}
+}
@@ -53,6 +53,12 @@ var doc = `{
                         "name": "data-version",
                         "in": "query"
                     },
+                    {
+                        "type": "boolean",
+                        "description": "gives how every rule of every ui item was evaluated instead of the ui content, requires an admin session, true or false",
+                        "name": "explain",
+                        "in": "query"
+                    },
                     {
                         "description": "body data",
                         "name": "data",
@@ -73,6 +79,108 @@ var doc = `{
                 }
             }
         },
+        "/api/v4/ui-content": {
+            "get": {
+                "security": [
+                    {
+                        "RokwireAuth": []
+                    }
+                ],
+                "description": "Gives the ui content based on the parameters. The ui items are given with their nested ui items, the nested ui items of a not shown ui item are not shown.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "APIs"
+                ],
+                "operationId": "GetUIContentV4",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "for example '2.2'",
+                        "name": "data-version",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "gives how every rule of every ui item was evaluated instead of the ui content in the same shape as V3 with the nested ui items traces as children, requires an admin session, true or false",
+                        "name": "explain",
+                        "in": "query"
+                    },
+                    {
+                        "description": "body data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/getUIContentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/UIContentTree"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v5/ui-content": {
+            "get": {
+                "security": [
+                    {
+                        "RokwireAuth": []
+                    }
+                ],
+                "description": "Gives the ui content based on the parameters. Every content item and ui item is given with its payload, the ui items are given with their nested ui items. The ui items strings are given for the most preferred Accept-Language locale which has them, falling back to the default locale.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "APIs"
+                ],
+                "operationId": "GetUIContentV5",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "for example '2.2'",
+                        "name": "data-version",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "gives how every rule of every ui item was evaluated instead of the ui content in the same shape as V3 with the nested ui items traces as children, requires an admin session, true or false",
+                        "name": "explain",
+                        "in": "query"
+                    },
+                    {
+                        "description": "body data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/getUIContentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/UIContentPayloads"
+                        }
+                    }
+                }
+            }
+        },
         "/api/version": {
             "get": {
                 "description": "Gives the service version.",
@@ -106,6 +214,20 @@ var doc = `{
                 }
             }
         },
+        "ContentItemPayloadNode": {
+            "type": "object",
+            "properties": {
+                "payload": {
+                    "type": "object"
+                },
+                "ui-items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/UIItemPayloadNode"
+                    }
+                }
+            }
+        },
         "IliniCash": {
             "type": "object",
             "properties": {
@@ -114,6 +236,17 @@ var doc = `{
                 }
             }
         },
+        "Location": {
+            "type": "object",
+            "properties": {
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                }
+            }
+        },
         "Pii": {
             "type": "object",
             "properties": {
@@ -125,6 +258,9 @@ var doc = `{
         "Platform": {
             "type": "object",
             "properties": {
+                "app_version": {
+                    "type": "string"
+                },
                 "os": {
                     "type": "string"
                 }
@@ -164,6 +300,58 @@ var doc = `{
                 }
             }
         },
+        "UIContentNode": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/UIContentNode"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "UIContentPayloads": {
+            "type": "object",
+            "additionalProperties": {
+                "$ref": "#/definitions/ContentItemPayloadNode"
+            }
+        },
+        "UIContentTree": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "array",
+                "items": {
+                    "$ref": "#/definitions/UIContentNode"
+                }
+            }
+        },
+        "UIItemPayloadNode": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/UIItemPayloadNode"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "strings": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "User": {
             "type": "object",
             "properties": {
@@ -217,6 +405,10 @@ var doc = `{
         "getUIContentRequest": {
             "type": "object",
             "properties": {
+                "attributes": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
                 "auth_token": {
                     "type": "object",
                     "$ref": "#/definitions/Token"
@@ -233,6 +425,10 @@ var doc = `{
                     "type": "object",
                     "$ref": "#/definitions/IliniCash"
                 },
+                "location": {
+                    "type": "object",
+                    "$ref": "#/definitions/Location"
+                },
                 "pii": {
                     "type": "object",
                     "$ref": "#/definitions/Pii"
@@ -19,6 +19,7 @@ package aws
 
 import (
 	"errors"
+	"fmt"
 	"log"
 	"sync"
 	"talent-chooser/core/model"
@@ -143,7 +144,7 @@ func (a *Adapter) ReadUIContent() (*model.UIContent, error) {
 		return nil, err
 	}
 
-	uiContent := model.NewUIContent(contentItems)
+	uiContent := model.NewUIContent(contentItems, nil, nil)
 	return &uiContent, nil
 }
 
@@ -360,7 +361,10 @@ func (a *Adapter) ReadUIItem(contentItemID int, ID int) (*model.UIItem, error) {
 	rulesList := data.Rules
 	rulesUIItems := data.RulesUIItems
 
-	rules := a.getRules(uiItem.ID, rulesList, ruleTypesList, rulesUIItems)
+	rules, err := a.getRules(uiItem.ID, rulesList, ruleTypesList, rulesUIItems)
+	if err != nil {
+		return nil, err
+	}
 
 	return &model.UIItem{ID: uiItem.ID, Name: uiItem.Name, Order: uiItem.Order, Rules: rules}, nil
 }
@@ -572,8 +576,10 @@ func (a *Adapter) ReadRule(uiItemID int, ID int) (*model.Rule, error) {
 
 	//3. read rule types
 	ruleTypesList := data.RuleTypes
-	rType := a.findRuleType(rule.RuleTypeID, ruleTypesList)
-	ruleType := *model.NewRuleType(rType.ID, rType.Name)
+	ruleType, err := a.newRuleType(rule.RuleTypeID, ruleTypesList)
+	if err != nil {
+		return nil, err
+	}
 
 	return &model.Rule{ID: rule.ID, RuleType: ruleType, Value: rule.Value}, nil
 }
@@ -608,10 +614,13 @@ func (a *Adapter) CreateRule(uiItemID int, ruleTypeID int, value interface{}) (*
 	}
 
 	//3. Validate the value data for the rule type
-	ruleType := *model.NewRuleType(rType.ID, rType.Name)
-	valid := ruleType.ValidData(value)
-	if !valid {
-		return nil, errors.New("the provided data is not valid for this rule type")
+	ruleType, err := model.NewRuleType(rType.ID, rType.Name)
+	if err != nil {
+		return nil, err
+	}
+	err = model.ValidateRuleValue(ruleType, value)
+	if err != nil {
+		return nil, err
 	}
 
 	//4. Add a record in the rules
@@ -705,10 +714,13 @@ func (a *Adapter) UpdateRule(ID int, uiItemID int, ruleTypeID int, value interfa
 	}
 
 	//6. Validate the value data for the rule type
-	ruleType := *model.NewRuleType(rType.ID, rType.Name)
-	valid := ruleType.ValidData(value)
-	if !valid {
-		return nil, errors.New("the provided data is not valid for this rule type")
+	ruleType, err := model.NewRuleType(rType.ID, rType.Name)
+	if err != nil {
+		return nil, err
+	}
+	err = model.ValidateRuleValue(ruleType, value)
+	if err != nil {
+		return nil, err
 	}
 
 	//7. update the item
@@ -797,8 +809,11 @@ func (a *Adapter) ReadRuleTypes() ([]model.RuleType, error) {
 	list := make([]model.RuleType, len(ruleTypesList))
 
 	for i, ruleType := range ruleTypesList {
-		ruleTypeEntity := model.NewRuleType(ruleType.ID, ruleType.Name)
-		list[i] = *ruleTypeEntity
+		ruleTypeEntity, err := model.NewRuleType(ruleType.ID, ruleType.Name)
+		if err != nil {
+			return nil, err
+		}
+		list[i] = ruleTypeEntity
 	}
 	return list, nil
 }
@@ -866,7 +881,13 @@ func (a *Adapter) readFullUIContent() ([]model.ContentItem, error) {
 		if ciuiItems != nil {
 			for index, ciuiItem := range ciuiItems {
 				uiItem, _ := a.findUIItem(ciuiItem.UIItemID, uiItemsList)
-				rules := a.getRules(uiItem.ID, rulesList, ruleTypesList, rulesUIItems)
+				if uiItem == nil {
+					return nil, fmt.Errorf("there is no ui item %d", ciuiItem.UIItemID)
+				}
+				rules, err := a.getRules(uiItem.ID, rulesList, ruleTypesList, rulesUIItems)
+				if err != nil {
+					return nil, err
+				}
 				uiItems[index] = model.UIItem{ID: uiItem.ID, Name: uiItem.Name, Order: uiItem.Order, Rules: rules}
 			}
 		}
@@ -965,19 +986,33 @@ func (a *Adapter) findRuleUIItemRel(ruleID int, uiItemID int, list []ruleUIItem)
 	return nil, -1
 }
 
-func (a *Adapter) getRules(uiItemID int, rules []rule, rulesTypes []ruleType, rulesUIItems []ruleUIItem) *[]model.Rule {
+func (a *Adapter) getRules(uiItemID int, rules []rule, rulesTypes []ruleType, rulesUIItems []ruleUIItem) (*[]model.Rule, error) {
 	var rulesResult []model.Rule
 	for _, ruleItemID := range rulesUIItems {
 		if uiItemID == ruleItemID.UIItemID {
 			rule, _ := a.findRule(ruleItemID.RuleID, rules)
-			ruleType := a.findRuleType(rule.RuleTypeID, rulesTypes)
+			if rule == nil {
+				return nil, fmt.Errorf("there is no rule %d for ui item %d", ruleItemID.RuleID, uiItemID)
+			}
 
-			ruleTypeEntity := *model.NewRuleType(ruleType.ID, ruleType.Name)
+			ruleTypeEntity, err := a.newRuleType(rule.RuleTypeID, rulesTypes)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d - %s", rule.ID, err.Error())
+			}
 			ruleEntity := model.Rule{ID: rule.ID, RuleType: ruleTypeEntity, Value: rule.Value}
 			rulesResult = append(rulesResult, ruleEntity)
 		}
 	}
-	return &rulesResult
+	return &rulesResult, nil
+}
+
+//newRuleType creates the rule type entity for the stored rule type id from the registry
+func (a *Adapter) newRuleType(ruleTypeID int, ruleTypes []ruleType) (model.RuleType, error) {
+	rType := a.findRuleType(ruleTypeID, ruleTypes)
+	if rType == nil {
+		return nil, fmt.Errorf("there is no rule type %d", ruleTypeID)
+	}
+	return model.NewRuleType(rType.ID, rType.Name)
 }
 
 func (a *Adapter) findRule(id int, rules []rule) (*rule, int) {
@@ -19,6 +19,7 @@ package file
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"talent-chooser/core/model"
@@ -94,14 +95,20 @@ func (fa Adapter) ReadUIContent() (*model.UIContent, error) {
 		if ciuiItems != nil {
 			for index, ciuiItem := range ciuiItems {
 				uiItem := fa.findUIItem(ciuiItem.UIItemID, uiItemsList)
-				rules := fa.getRules(uiItem.ID, rulesList, ruleTypesList, rulesUIItems)
+				if uiItem == nil {
+					return nil, fmt.Errorf("there is no ui item %d", ciuiItem.UIItemID)
+				}
+				rules, err := fa.getRules(uiItem.ID, rulesList, ruleTypesList, rulesUIItems)
+				if err != nil {
+					return nil, err
+				}
 				uiItems[index] = model.UIItem{ID: uiItem.ID, Name: uiItem.Name, Order: uiItem.Order, Rules: rules}
 			}
 		}
 
 		uiData[id] = model.ContentItem{Name: name, UIItems: uiItems}
 	}
-	uiContent := model.NewUIContent(uiData)
+	uiContent := model.NewUIContent(uiData, nil, nil)
 	return &uiContent, nil
 }
 
@@ -115,32 +122,29 @@ func (fa Adapter) getContentItemUIItems(contentItemID int, list []contentItemUII
 	return result
 }
 
-func (fa Adapter) getRules(uiItemID int, rules []rule, rulesTypes []ruleType, rulesUIItems []ruleUIItem) *[]model.Rule {
+func (fa Adapter) getRules(uiItemID int, rules []rule, rulesTypes []ruleType, rulesUIItems []ruleUIItem) (*[]model.Rule, error) {
 	var rulesResult []model.Rule
 	for _, ruleItemID := range rulesUIItems {
 		if uiItemID == ruleItemID.UIItemID {
 			rule := fa.findRule(ruleItemID.RuleID, rules)
+			if rule == nil {
+				return nil, fmt.Errorf("there is no rule %d for ui item %d", ruleItemID.RuleID, uiItemID)
+			}
 			ruleType := fa.findRuleType(rule.RuleTypeID, rulesTypes)
+			if ruleType == nil {
+				return nil, fmt.Errorf("rule %d - there is no rule type %d", rule.ID, rule.RuleTypeID)
+			}
 
-			var ruleTypeEntity model.RuleType
-			switch ruleType.Name {
-			case "roles":
-				ruleTypeEntity = model.NewRolesRuleType(ruleType.ID, ruleType.Name)
-			case "privacy":
-				ruleTypeEntity = model.NewPrivacyRuleType(ruleType.ID, ruleType.Name)
-			case "auth":
-				ruleTypeEntity = model.NewAuthRuleType(ruleType.ID, ruleType.Name)
-			case "illini_cash":
-				ruleTypeEntity = model.NewIlliniCashRuleType(ruleType.ID, ruleType.Name)
-			case "enable":
-				ruleTypeEntity = model.NewEnableRuleType(ruleType.ID, ruleType.Name)
+			ruleTypeEntity, err := model.NewRuleType(ruleType.ID, ruleType.Name)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d - %s", rule.ID, err.Error())
 			}
 
 			ruleEntity := model.Rule{ID: rule.ID, RuleType: ruleTypeEntity, Value: rule.Value}
 			rulesResult = append(rulesResult, ruleEntity)
 		}
 	}
-	return &rulesResult
+	return &rulesResult, nil
 }
 
 func (fa Adapter) readRuleTypesList() []ruleType {
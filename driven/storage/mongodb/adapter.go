@@ -20,8 +20,10 @@ package mongodb
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"strconv"
+	"strings"
 	"sync"
 	"talent-chooser/core"
 	"talent-chooser/core/model"
@@ -29,6 +31,7 @@ import (
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 //TODO refactor implementation...
@@ -49,6 +52,9 @@ type data struct {
 	Rules               []rule              `json:"rules"`
 	RulesUIItems        []ruleUIItem        `json:"rules_ui_items"`
 	UIItems             []uiItem            `json:"ui_items"`
+	UUIDLists           []uuidList          `json:"uuid_lists,omitempty"`
+	UIItemsChildren     []uiItemChild       `json:"ui_items_children,omitempty"`
+	UIItemsStrings      []uiItemStrings     `json:"ui_items_strings,omitempty"`
 }
 
 type storageItem interface {
@@ -56,8 +62,10 @@ type storageItem interface {
 }
 
 type contentItem struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
+	ID            int                    `json:"id"`
+	Name          string                 `json:"name"`
+	Payload       interface{}            `json:"payload,omitempty"`
+	PayloadSchema map[string]interface{} `json:"payload_schema,omitempty"`
 }
 
 func (ca contentItem) GetID() int {
@@ -65,9 +73,12 @@ func (ca contentItem) GetID() int {
 }
 
 type uiItem struct {
-	ID    int    `json:"id"`
-	Name  string `json:"name"`
-	Order int    `json:"order"`
+	ID             int         `json:"id"`
+	Name           string      `json:"name"`
+	Order          int         `json:"order"`
+	DataCategories []string    `json:"data_categories,omitempty"`
+	MatchMode      string      `json:"match_mode,omitempty"`
+	Payload        interface{} `json:"payload,omitempty"`
 }
 
 func (ua uiItem) GetID() int {
@@ -75,15 +86,32 @@ func (ua uiItem) GetID() int {
 }
 
 type contentItemUIItem struct {
-	ID            int `json:"id"`
-	ContentItemID int `json:"content_item_id"`
-	UIItemID      int `json:"ui_item_id"`
+	ID            int  `json:"id"`
+	ContentItemID int  `json:"content_item_id"`
+	UIItemID      int  `json:"ui_item_id"`
+	Order         *int `json:"order,omitempty"` //the order of a linked ui item in the content item, the ui item order is used when not set
 }
 
 func (caua contentItemUIItem) GetID() int {
 	return caua.ID
 }
 
+type uiItemChild struct {
+	ID             int `json:"id"`
+	ParentUIItemID int `json:"parent_ui_item_id"`
+	UIItemID       int `json:"ui_item_id"`
+}
+
+func (uic uiItemChild) GetID() int {
+	return uic.ID
+}
+
+type uiItemStrings struct {
+	UIItemID int               `json:"ui_item_id"`
+	Locale   string            `json:"locale"`
+	Strings  map[string]string `json:"strings"`
+}
+
 type ruleType struct {
 	ID   int    `json:"id"`
 	Name string `json:"name"`
@@ -91,8 +119,11 @@ type ruleType struct {
 
 type rule struct {
 	ID         int         `json:"id"`
+	Name       string      `json:"name,omitempty"`
 	RuleTypeID int         `json:"rule_type_id"`
 	Value      interface{} `json:"value"`
+	OnMissing  string      `json:"on_missing,omitempty"`
+	Negate     bool        `json:"negate,omitempty"`
 }
 
 func (r rule) GetID() int {
@@ -109,17 +140,41 @@ func (r ruleUIItem) GetID() int {
 	return r.ID
 }
 
+type uuidList struct {
+	ID    int      `json:"id"`
+	Name  string   `json:"name"`
+	UUIDs []string `json:"uuids"`
+}
+
+func (ul uuidList) GetID() int {
+	return ul.ID
+}
+
+//maxUIItemDepth limits the nested ui items levels, it protects from a cycle in the stored relations
+const maxUIItemDepth = 16
+
 //Adapter implements the Storage interface
 type Adapter struct {
-	db *database
+	db      *database
+	tchdata dataCollection
 
 	mu *sync.Mutex //TODO
 }
 
+//dataCollection is the part of the tchdata collection which the data versions are read from and saved to
+type dataCollection interface {
+	Find(filter interface{}, result interface{}, findOptions *options.FindOptions) error
+	ReplaceOne(filter interface{}, replacement interface{}, replaceOptions *options.ReplaceOptions) error
+}
+
 //Start starts the storage
 func (a *Adapter) Start() error {
 	err := a.db.start()
-	return err
+	if err != nil {
+		return err
+	}
+	a.tchdata = a.db.tchdata
+	return nil
 }
 
 //SetStorageListener sets listener for the storage
@@ -145,13 +200,7 @@ func (a *Adapter) ReadUIContent() (map[string]*model.UIContent, error) {
 		log.Printf("ReadUIContent -> Error reading the content items %s\n", err.Error())
 		return nil, err
 	}
-
-	result := map[string]*model.UIContent{}
-	for version, item := range uiContents {
-		uiContent := model.NewUIContent(item)
-		result[version] = &uiContent
-	}
-	return result, nil
+	return uiContents, nil
 }
 
 //ReadContentItems reads the content items from the storage
@@ -197,17 +246,60 @@ func (a *Adapter) ReadContentItem(dataVersion string, ID int) (*model.ContentIte
 			if ciuiItems != nil {
 				for index, ciuiItem := range ciuiItems {
 					uiItem, _ := a.findUIItem(ciuiItem.UIItemID, uiItemsList)
-					uiItems[index] = model.UIItem{ID: uiItem.ID, Name: uiItem.Name, Order: uiItem.Order, Rules: nil}
+					if uiItem == nil {
+						return nil, fmt.Errorf("there is no ui item %d", ciuiItem.UIItemID)
+					}
+					children, err := a.getChildren(uiItem.ID, data, false, 1)
+					if err != nil {
+						return nil, err
+					}
+					uiItems[index] = model.UIItem{ID: uiItem.ID, Name: uiItem.Name, Order: a.linkOrder(ciuiItem, *uiItem), Rules: nil, DataCategories: uiItem.DataCategories, MatchMode: uiItem.MatchMode, Payload: uiItem.Payload, Children: children}
 				}
 			}
-			return &model.ContentItem{ID: ID, Name: name, UIItems: uiItems}, nil
+			return &model.ContentItem{ID: ID, Name: name, UIItems: uiItems, Payload: contentItem.Payload, PayloadSchema: contentItem.PayloadSchema}, nil
 		}
 	}
 	return nil, errors.New("There is no a content item with the provided id")
 }
 
+//ReadUIItemContentItems reads the content items which contain the ui item directly or through its parents ui items
+func (a *Adapter) ReadUIItemContentItems(dataVersion string, uiItemID int) ([]model.ContentItem, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	data, err := a.readData(dataVersion)
+	if err != nil {
+		log.Print(err.Error())
+		return nil, err
+	}
+	if data == nil {
+		log.Println("ReadUIItemContentItems - data is nil")
+		return nil, errors.New("ReadUIItemContentItems - data is nil")
+	}
+
+	//1. find the top parent ui item
+	topID, _, err := a.findTopUIItem(uiItemID, data.UIItemsChildren)
+	if err != nil {
+		return nil, err
+	}
+
+	//2. find the content items the top parent ui item is linked to
+	var result []model.ContentItem
+	for _, ciuiItem := range a.getUIItemContentItems(topID, data.ContentItemsUIItems) {
+		contentItem, _ := a.findContentItem(ciuiItem.ContentItemID, data.ContentItems)
+		if contentItem == nil {
+			return nil, fmt.Errorf("there is no content item %d", ciuiItem.ContentItemID)
+		}
+		result = append(result, model.ContentItem{ID: contentItem.ID, Name: contentItem.Name, Payload: contentItem.Payload, PayloadSchema: contentItem.PayloadSchema})
+	}
+	if len(result) == 0 {
+		return nil, errors.New("there is no a content item for the provided ui item id")
+	}
+	return result, nil
+}
+
 //CreateContentItem creates a content item
-func (a *Adapter) CreateContentItem(dataVersion string, name string) (*model.ContentItem, error) {
+func (a *Adapter) CreateContentItem(dataVersion string, name string, payload interface{}, payloadSchema map[string]interface{}) (*model.ContentItem, error) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
@@ -236,7 +328,7 @@ func (a *Adapter) CreateContentItem(dataVersion string, name string) (*model.Con
 	}
 
 	//3. create a new item
-	newItem := contentItem{ID: biggestID + 1, Name: name}
+	newItem := contentItem{ID: biggestID + 1, Name: name, Payload: payload, PayloadSchema: payloadSchema}
 	//4. add it to the list
 	contentItemsList = append(contentItemsList, newItem)
 	//5. write the list
@@ -246,11 +338,11 @@ func (a *Adapter) CreateContentItem(dataVersion string, name string) (*model.Con
 		return nil, err
 	}
 	//6. return the new created content item
-	return &model.ContentItem{ID: newItem.ID, Name: newItem.Name}, nil
+	return &model.ContentItem{ID: newItem.ID, Name: newItem.Name, Payload: newItem.Payload, PayloadSchema: newItem.PayloadSchema}, nil
 }
 
 //UpdateContentItem updates the content item
-func (a *Adapter) UpdateContentItem(dataVersion string, ID int, name string) (*model.ContentItem, error) {
+func (a *Adapter) UpdateContentItem(dataVersion string, ID int, name string, payload interface{}, payloadSchema map[string]interface{}) (*model.ContentItem, error) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
@@ -275,6 +367,8 @@ func (a *Adapter) UpdateContentItem(dataVersion string, ID int, name string) (*m
 
 	//3. update the item
 	founded.Name = name
+	founded.Payload = payload
+	founded.PayloadSchema = payloadSchema
 
 	//4. replace the updated item in the list
 	contentItemsList[index] = *founded
@@ -286,7 +380,7 @@ func (a *Adapter) UpdateContentItem(dataVersion string, ID int, name string) (*m
 		return nil, err
 	}
 
-	return &model.ContentItem{ID: founded.ID, Name: founded.Name}, nil
+	return &model.ContentItem{ID: founded.ID, Name: founded.Name, Payload: founded.Payload, PayloadSchema: founded.PayloadSchema}, nil
 }
 
 //DeleteContentItem deletes the content item
@@ -367,13 +461,29 @@ func (a *Adapter) ReadUIItem(dataVersion string, contentItemID int, ID int) (*mo
 	rulesList := data.Rules
 	rulesUIItems := data.RulesUIItems
 
-	rules := a.getRules(uiItem.ID, rulesList, ruleTypesList, rulesUIItems)
+	rules, err := a.getRules(uiItem.ID, rulesList, ruleTypesList, rulesUIItems)
+	if err != nil {
+		return nil, err
+	}
+
+	//4. read children
+	children, err := a.getChildren(uiItem.ID, data, false, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	//5. a linked ui item has its own order in the content item
+	order := uiItem.Order
+	relItem, _ := a.findContentItemUIItemRel(contentItemID, ID, data.ContentItemsUIItems)
+	if relItem != nil {
+		order = a.linkOrder(*relItem, *uiItem)
+	}
 
-	return &model.UIItem{ID: uiItem.ID, Name: uiItem.Name, Order: uiItem.Order, Rules: rules}, nil
+	return &model.UIItem{ID: uiItem.ID, Name: uiItem.Name, Order: order, Rules: rules, DataCategories: uiItem.DataCategories, MatchMode: uiItem.MatchMode, Payload: uiItem.Payload, Children: children}, nil
 }
 
 //CreateUIItem create ui item for a specific content item
-func (a *Adapter) CreateUIItem(dataVersion string, contentItemID int, name string, order int) (*model.UIItem, error) {
+func (a *Adapter) CreateUIItem(dataVersion string, contentItemID int, name string, order int, dataCategories []string, matchMode string, payload interface{}) (*model.UIItem, error) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
@@ -409,7 +519,7 @@ func (a *Adapter) CreateUIItem(dataVersion string, contentItemID int, name strin
 		return nil, err
 	}
 	uiItemID := uiItemBiggestID + 1
-	newItem := uiItem{ID: uiItemID, Name: name, Order: order}
+	newItem := uiItem{ID: uiItemID, Name: name, Order: order, DataCategories: dataCategories, MatchMode: matchMode, Payload: payload}
 	uiItemsList = append(uiItemsList, newItem)
 
 	//4. add a record in the relation file
@@ -433,11 +543,11 @@ func (a *Adapter) CreateUIItem(dataVersion string, contentItemID int, name strin
 		return nil, err
 	}
 
-	return &model.UIItem{ID: newItem.ID, Name: newItem.Name, Order: newItem.Order}, nil
+	return &model.UIItem{ID: newItem.ID, Name: newItem.Name, Order: newItem.Order, DataCategories: newItem.DataCategories, MatchMode: newItem.MatchMode, Payload: newItem.Payload}, nil
 }
 
 //UpdateUIItem updates ui item for a specific content item
-func (a *Adapter) UpdateUIItem(dataVersion string, contentItemID int, ID int, name string, order int) (*model.UIItem, error) {
+func (a *Adapter) UpdateUIItem(dataVersion string, contentItemID int, ID int, name string, order int, dataCategories []string, matchMode string, payload interface{}) (*model.UIItem, error) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
@@ -463,7 +573,7 @@ func (a *Adapter) UpdateUIItem(dataVersion string, contentItemID int, ID int, na
 	uiItemsList := data.UIItems
 
 	//3. find if there is a such ui item for the provided content item
-	foundedRelItem, _ := a.findContentItemUIItemRel(contentItemID, ID, contentItemsUIItemsList)
+	foundedRelItem, relIndex := a.findContentItemUIItemRel(contentItemID, ID, contentItemsUIItemsList)
 	if foundedRelItem == nil {
 		return nil, errors.New("there is no associated ui item with the provided content item id")
 	}
@@ -473,21 +583,30 @@ func (a *Adapter) UpdateUIItem(dataVersion string, contentItemID int, ID int, na
 		return nil, errors.New("there is no ui item for the provided id")
 	}
 
-	//5. update the item
+	//5. update the item, the order of a linked ui item is kept in the link
 	foundedUIItem.Name = name
-	foundedUIItem.Order = order
+	if foundedRelItem.Order != nil {
+		foundedRelItem.Order = &order
+		contentItemsUIItemsList[relIndex] = *foundedRelItem
+	} else {
+		foundedUIItem.Order = order
+	}
+	foundedUIItem.DataCategories = dataCategories
+	foundedUIItem.MatchMode = matchMode
+	foundedUIItem.Payload = payload
 
 	//6. replace the updated item in the list
 	uiItemsList[uiItemIndex] = *foundedUIItem
 
-	//7. write the list
+	//7. write the lists
 	data.UIItems = uiItemsList
+	data.ContentItemsUIItems = contentItemsUIItemsList
 	err = a.saveData(dataVersion, data)
 	if err != nil {
 		return nil, err
 	}
 
-	return &model.UIItem{ID: foundedUIItem.ID, Name: foundedUIItem.Name, Order: foundedUIItem.Order}, nil
+	return &model.UIItem{ID: foundedUIItem.ID, Name: foundedUIItem.Name, Order: a.linkOrder(*foundedRelItem, *foundedUIItem), DataCategories: foundedUIItem.DataCategories, MatchMode: foundedUIItem.MatchMode, Payload: foundedUIItem.Payload}, nil
 }
 
 //DeleteUIItem deltes ui item for a specific content item
@@ -529,7 +648,7 @@ func (a *Adapter) DeleteUIItem(dataVersion string, contentItemID int, ID int) er
 
 	//5. check if we can delete it from the ui items file and the rel files
 	ruiList := data.RulesUIItems
-	canDelete, reason := a.canDeleteUIItem(contentItemID, ID, contentItemsUIItemsList, ruiList)
+	canDelete, reason := a.canDeleteUIItem(contentItemID, ID, contentItemsUIItemsList, ruiList, data.UIItemsChildren, contentItemsList)
 	if !canDelete {
 		return errors.New(reason)
 	}
@@ -541,6 +660,7 @@ func (a *Adapter) DeleteUIItem(dataVersion string, contentItemID int, ID int) er
 	//7. upload the files
 	data.UIItems = uiItemsList
 	data.ContentItemsUIItems = contentItemsUIItemsList
+	data.UIItemsStrings = a.removeUIItemStrings(ID, data.UIItemsStrings)
 	err = a.saveData(dataVersion, data)
 	if err != nil {
 		return err
@@ -548,8 +668,8 @@ func (a *Adapter) DeleteUIItem(dataVersion string, contentItemID int, ID int) er
 	return nil
 }
 
-//ReadRule reads a rule for a specific ui item
-func (a *Adapter) ReadRule(dataVersion string, uiItemID int, ID int) (*model.Rule, error) {
+//LinkUIItem links an existing ui item to one more content item with its own order in it
+func (a *Adapter) LinkUIItem(dataVersion string, contentItemID int, ID int, order int) (*model.UIItem, error) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
@@ -559,34 +679,96 @@ func (a *Adapter) ReadRule(dataVersion string, uiItemID int, ID int) (*model.Rul
 		return nil, err
 	}
 	if data == nil {
-		log.Println("ReadRule - data is nil")
-		return nil, errors.New("ReadRule - data is nil")
+		log.Println("LinkUIItem - data is nil")
+		return nil, errors.New("LinkUIItem - data is nil")
 	}
 
-	//1. check if there is a ui item with the provided id
-	uiItemsList := data.UIItems
-	uiItem, _ := a.findUIItem(uiItemID, uiItemsList)
+	//1. check if thre is a content item with the provided id
+	contentItem, _ := a.findContentItem(contentItemID, data.ContentItems)
+	if contentItem == nil {
+		return nil, errors.New("there is no a content item with the provided id")
+	}
+
+	//2. check if thre is an ui item with the provided id, the children ui items belong to their parents
+	uiItem, _ := a.findUIItem(ID, data.UIItems)
 	if uiItem == nil {
 		return nil, errors.New("there is no a ui item with the provided id")
 	}
+	if a.findUIItemParentRel(ID, data.UIItemsChildren) != nil {
+		return nil, errors.New("a child ui item cannot be linked to a content item")
+	}
 
-	//2. check if there is a rule with the provided id
-	rulesList := data.Rules
-	rule, _ := a.findRule(ID, rulesList)
-	if rule == nil {
-		return nil, errors.New("there is no a rule with the provided id")
+	//3. check if it is already linked
+	contentItemsUIItemsList := data.ContentItemsUIItems
+	foundedRelItem, _ := a.findContentItemUIItemRel(contentItemID, ID, contentItemsUIItemsList)
+	if foundedRelItem != nil {
+		return nil, errors.New("the ui item is already linked to the content item")
 	}
 
-	//3. read rule types
-	ruleTypesList := data.RuleTypes
-	rType := a.findRuleType(rule.RuleTypeID, ruleTypesList)
-	ruleType := *model.NewRuleType(rType.ID, rType.Name)
+	//4. add a record in the relation file
+	relStorageItems := make([]storageItem, len(contentItemsUIItemsList))
+	for index, item := range contentItemsUIItemsList {
+		relStorageItems[index] = item
+	}
+	relBiggestID, err := a.findBiggestID(relStorageItems)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+	relItem := contentItemUIItem{ID: relBiggestID + 1, ContentItemID: contentItemID, UIItemID: ID, Order: &order}
+	contentItemsUIItemsList = append(contentItemsUIItemsList, relItem)
+
+	//5. write the list
+	data.ContentItemsUIItems = contentItemsUIItemsList
+	err = a.saveData(dataVersion, data)
+	if err != nil {
+		return nil, err
+	}
 
-	return &model.Rule{ID: rule.ID, RuleType: ruleType, Value: rule.Value}, nil
+	return &model.UIItem{ID: uiItem.ID, Name: uiItem.Name, Order: order, DataCategories: uiItem.DataCategories, MatchMode: uiItem.MatchMode, Payload: uiItem.Payload}, nil
 }
 
-//CreateRule creates a rule for a specific ui item
-func (a *Adapter) CreateRule(dataVersion string, uiItemID int, ruleTypeID int, value interface{}) (*model.Rule, error) {
+//UnlinkUIItem unlinks a ui item from a content item, the ui item must stay linked to at least one content item
+func (a *Adapter) UnlinkUIItem(dataVersion string, contentItemID int, ID int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	data, err := a.readData(dataVersion)
+	if err != nil {
+		log.Print(err.Error())
+		return err
+	}
+	if data == nil {
+		log.Println("UnlinkUIItem - data is nil")
+		return errors.New("UnlinkUIItem - data is nil")
+	}
+
+	//1. find the link
+	contentItemsUIItemsList := data.ContentItemsUIItems
+	foundedRelItem, relIndex := a.findContentItemUIItemRel(contentItemID, ID, contentItemsUIItemsList)
+	if foundedRelItem == nil {
+		return errors.New("the ui item is not linked to the content item")
+	}
+
+	//2. the last link can be removed only by deleting the ui item
+	if len(a.getUIItemContentItems(ID, contentItemsUIItemsList)) == 1 {
+		return errors.New("the ui item is not linked to other content items, it must be deleted instead")
+	}
+
+	//3. remove it from the rel file
+	contentItemsUIItemsList = append(contentItemsUIItemsList[:relIndex], contentItemsUIItemsList[relIndex+1:]...)
+
+	//4. write the list
+	data.ContentItemsUIItems = contentItemsUIItemsList
+	err = a.saveData(dataVersion, data)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+//ReadChildUIItem reads a child ui item of a specific ui item
+func (a *Adapter) ReadChildUIItem(dataVersion string, parentID int, ID int) (*model.UIItem, error) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
@@ -596,54 +778,82 @@ func (a *Adapter) CreateRule(dataVersion string, uiItemID int, ruleTypeID int, v
 		return nil, err
 	}
 	if data == nil {
-		log.Println("CreateRule - data is nil")
-		return nil, errors.New("CreateRule - data is nil")
+		log.Println("ReadChildUIItem - data is nil")
+		return nil, errors.New("ReadChildUIItem - data is nil")
 	}
 
-	//1. first check if there is ui item for the provided ui item id
-	uiItemsList := data.UIItems
-	uiItem, _ := a.findUIItem(uiItemID, uiItemsList)
+	//1. check if the ui item is a child of the provided parent
+	foundedRelItem, _ := a.findUIItemChildRel(parentID, ID, data.UIItemsChildren)
+	if foundedRelItem == nil {
+		return nil, errors.New("there is no child ui item with the provided id for the provided parent id")
+	}
+
+	//2. check if thre is an ui item with the provided id
+	uiItem, _ := a.findUIItem(ID, data.UIItems)
 	if uiItem == nil {
 		return nil, errors.New("there is no a ui item with the provided id")
 	}
 
-	//2. check if there is rule type for the provided rule type id
-	ruleTypesList := data.RuleTypes
-	rType := a.findRuleType(ruleTypeID, ruleTypesList)
-	if rType == nil {
-		return nil, errors.New("there is no a rule type with the provided id")
+	//3. read rules and children
+	rules, err := a.getRules(uiItem.ID, data.Rules, data.RuleTypes, data.RulesUIItems)
+	if err != nil {
+		return nil, err
+	}
+	children, err := a.getChildren(uiItem.ID, data, false, 1)
+	if err != nil {
+		return nil, err
 	}
 
-	//3. Validate the value data for the rule type
-	ruleType := *model.NewRuleType(rType.ID, rType.Name)
-	valid := ruleType.ValidData(value)
-	if !valid {
-		return nil, errors.New("the provided data is not valid for this rule type")
+	return &model.UIItem{ID: uiItem.ID, Name: uiItem.Name, Order: uiItem.Order, Rules: rules, DataCategories: uiItem.DataCategories, MatchMode: uiItem.MatchMode, Payload: uiItem.Payload, Children: children}, nil
+}
+
+//CreateChildUIItem creates a child ui item for a specific ui item
+func (a *Adapter) CreateChildUIItem(dataVersion string, parentID int, name string, order int, dataCategories []string, matchMode string, payload interface{}) (*model.UIItem, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	data, err := a.readData(dataVersion)
+	if err != nil {
+		log.Print(err.Error())
+		return nil, err
+	}
+	if data == nil {
+		log.Println("CreateChildUIItem - data is nil")
+		return nil, errors.New("CreateChildUIItem - data is nil")
 	}
 
-	//4. Add a record in the rules
-	rulesList := data.Rules
+	//1. check if thre is a parent ui item with the provided id
+	uiItemsList := data.UIItems
+	parent, _ := a.findUIItem(parentID, uiItemsList)
+	if parent == nil {
+		return nil, errors.New("there is no a parent ui item with the provided id")
+	}
+	_, parentLevel, err := a.findTopUIItem(parentID, data.UIItemsChildren)
 	if err != nil {
-		log.Println(err.Error())
 		return nil, err
 	}
-	uiStorageItems := make([]storageItem, len(rulesList))
-	for index, item := range rulesList {
+	if parentLevel+1 >= maxUIItemDepth {
+		return nil, fmt.Errorf("ui items cannot be nested in more than %d levels", maxUIItemDepth-1)
+	}
+
+	//2. add the new ui item in the ui items list
+	uiStorageItems := make([]storageItem, len(uiItemsList))
+	for index, item := range uiItemsList {
 		uiStorageItems[index] = item
 	}
-	rulesListBiggestID, err := a.findBiggestID(uiStorageItems)
+	uiItemBiggestID, err := a.findBiggestID(uiStorageItems)
 	if err != nil {
 		log.Println(err.Error())
 		return nil, err
 	}
-	ruleID := rulesListBiggestID + 1
-	newRule := rule{ID: ruleID, RuleTypeID: ruleTypeID, Value: value}
-	rulesList = append(rulesList, newRule)
+	uiItemID := uiItemBiggestID + 1
+	newItem := uiItem{ID: uiItemID, Name: name, Order: order, DataCategories: dataCategories, MatchMode: matchMode, Payload: payload}
+	uiItemsList = append(uiItemsList, newItem)
 
-	//5. Add a record in the relations file
-	rulesUIItemsList := data.RulesUIItems
-	relStorageItems := make([]storageItem, len(rulesUIItemsList))
-	for index, item := range rulesUIItemsList {
+	//3. add a record in the children relation
+	childrenList := data.UIItemsChildren
+	relStorageItems := make([]storageItem, len(childrenList))
+	for index, item := range childrenList {
 		relStorageItems[index] = item
 	}
 	relBiggestID, err := a.findBiggestID(relStorageItems)
@@ -651,24 +861,22 @@ func (a *Adapter) CreateRule(dataVersion string, uiItemID int, ruleTypeID int, v
 		log.Println(err.Error())
 		return nil, err
 	}
-	relItem := ruleUIItem{ID: relBiggestID + 1, UIItemID: uiItemID, RuleID: ruleID}
-	rulesUIItemsList = append(rulesUIItemsList, relItem)
-
-	//6. upload the files
-	data.Rules = rulesList
-	data.RulesUIItems = rulesUIItemsList
+	relItem := uiItemChild{ID: relBiggestID + 1, ParentUIItemID: parentID, UIItemID: uiItemID}
+	childrenList = append(childrenList, relItem)
 
+	//4. save the data
+	data.UIItems = uiItemsList
+	data.UIItemsChildren = childrenList
 	err = a.saveData(dataVersion, data)
 	if err != nil {
 		return nil, err
 	}
 
-	rule := model.Rule{ID: ruleID, RuleType: ruleType, Value: value}
-	return &rule, nil
+	return &model.UIItem{ID: newItem.ID, Name: newItem.Name, Order: newItem.Order, DataCategories: newItem.DataCategories, MatchMode: newItem.MatchMode, Payload: newItem.Payload}, nil
 }
 
-//UpdateRule creates a rule for a specific ui item
-func (a *Adapter) UpdateRule(dataVersion string, ID int, uiItemID int, ruleTypeID int, value interface{}) (*model.Rule, error) {
+//UpdateChildUIItem updates a child ui item of a specific ui item
+func (a *Adapter) UpdateChildUIItem(dataVersion string, parentID int, ID int, name string, order int, dataCategories []string, matchMode string, payload interface{}) (*model.UIItem, error) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
@@ -678,65 +886,44 @@ func (a *Adapter) UpdateRule(dataVersion string, ID int, uiItemID int, ruleTypeI
 		return nil, err
 	}
 	if data == nil {
-		log.Println("UpdateRule - data is nil")
-		return nil, errors.New("UpdateRule - data is nil")
-	}
-
-	//1. check if there is a ui item with the provided id
-	uiItemsList := data.UIItems
-	contentItem, _ := a.findUIItem(uiItemID, uiItemsList)
-	if contentItem == nil {
-		return nil, errors.New("there is no a ui item with the provided id")
+		log.Println("UpdateChildUIItem - data is nil")
+		return nil, errors.New("UpdateChildUIItem - data is nil")
 	}
 
-	//2. download the rules ui items files
-	rulesUIItemsList := data.RulesUIItems
-	rulesList := data.Rules
-
-	//3. find if there is a such rule for the provided ui item
-	foundedRelItem, _ := a.findRuleUIItemRel(ID, uiItemID, rulesUIItemsList)
+	//1. find if the ui item is a child of the provided parent
+	foundedRelItem, _ := a.findUIItemChildRel(parentID, ID, data.UIItemsChildren)
 	if foundedRelItem == nil {
-		return nil, errors.New("there is no associated rule with the provided ui item id")
-	}
-	//4. find if there is rule for the provided id
-	foundedRule, ruleIndex := a.findRule(ID, rulesList)
-	if foundedRule == nil {
-		return nil, errors.New("there is no rule for the provided id")
-	}
-
-	//5. check if there is rule type for the provided rule type id
-	ruleTypesList := data.RuleTypes
-	rType := a.findRuleType(ruleTypeID, ruleTypesList)
-	if rType == nil {
-		return nil, errors.New("there is no a rule type with the provided id")
+		return nil, errors.New("there is no child ui item with the provided id for the provided parent id")
 	}
-
-	//6. Validate the value data for the rule type
-	ruleType := *model.NewRuleType(rType.ID, rType.Name)
-	valid := ruleType.ValidData(value)
-	if !valid {
-		return nil, errors.New("the provided data is not valid for this rule type")
+	//2. find if there is ui item for the provided id
+	uiItemsList := data.UIItems
+	foundedUIItem, uiItemIndex := a.findUIItem(ID, uiItemsList)
+	if foundedUIItem == nil {
+		return nil, errors.New("there is no ui item for the provided id")
 	}
 
-	//7. update the item
-	foundedRule.RuleTypeID = ruleTypeID
-	foundedRule.Value = value
+	//3. update the item
+	foundedUIItem.Name = name
+	foundedUIItem.Order = order
+	foundedUIItem.DataCategories = dataCategories
+	foundedUIItem.MatchMode = matchMode
+	foundedUIItem.Payload = payload
 
-	//8. replace the updated item in the list
-	rulesList[ruleIndex] = *foundedRule
+	//4. replace the updated item in the list
+	uiItemsList[uiItemIndex] = *foundedUIItem
 
-	//9. write the list
-	data.Rules = rulesList
+	//5. write the list
+	data.UIItems = uiItemsList
 	err = a.saveData(dataVersion, data)
 	if err != nil {
 		return nil, err
 	}
 
-	return &model.Rule{ID: ID, RuleType: ruleType, Value: value}, nil
+	return &model.UIItem{ID: foundedUIItem.ID, Name: foundedUIItem.Name, Order: foundedUIItem.Order, DataCategories: foundedUIItem.DataCategories, MatchMode: foundedUIItem.MatchMode, Payload: foundedUIItem.Payload}, nil
 }
 
-//DeleteRule deletes a rule for a specific ui item
-func (a *Adapter) DeleteRule(dataVersion string, uiItemID int, ID int) error {
+//DeleteChildUIItem deletes a child ui item of a specific ui item
+func (a *Adapter) DeleteChildUIItem(dataVersion string, parentID int, ID int) error {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
@@ -746,40 +933,853 @@ func (a *Adapter) DeleteRule(dataVersion string, uiItemID int, ID int) error {
 		return err
 	}
 	if data == nil {
-		log.Println("DeleteRule - data is nil")
-		return errors.New("DeleteRule - data is nil")
+		log.Println("DeleteChildUIItem - data is nil")
+		return errors.New("DeleteChildUIItem - data is nil")
 	}
 
-	//1. check if thre is a ui item with the provided id
+	//1. find if the ui item is a child of the provided parent
+	childrenList := data.UIItemsChildren
+	foundedRelItem, relIndex := a.findUIItemChildRel(parentID, ID, childrenList)
+	if foundedRelItem == nil {
+		return errors.New("there is no child ui item with the provided id for the provided parent id")
+	}
+	//2. find if there is ui item for the provided id
 	uiItemsList := data.UIItems
-	uiItem, _ := a.findUIItem(uiItemID, uiItemsList)
-	if uiItem == nil {
-		return errors.New("there is no a ui item with the provided id")
+	foundedUIItem, uiItemIndex := a.findUIItem(ID, uiItemsList)
+	if foundedUIItem == nil {
+		return errors.New("there is no ui item for the provided id")
 	}
 
-	//2. download the rules ui items files
-	rulesUIItemsList := data.RulesUIItems
-	rulesList := data.Rules
+	//3. check if we can delete it, a child ui item is not associated with content items
+	canDelete, reason := a.canDeleteUIItem(0, ID, data.ContentItemsUIItems, data.RulesUIItems, childrenList, data.ContentItems)
+	if !canDelete {
+		return errors.New(reason)
+	}
+
+	//4. remove it from the ui items and the children relation
+	childrenList = append(childrenList[:relIndex], childrenList[relIndex+1:]...)
+	uiItemsList = append(uiItemsList[:uiItemIndex], uiItemsList[uiItemIndex+1:]...)
+
+	//5. save the data
+	data.UIItems = uiItemsList
+	data.UIItemsChildren = childrenList
+	data.UIItemsStrings = a.removeUIItemStrings(ID, data.UIItemsStrings)
+	err = a.saveData(dataVersion, data)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+//ReadRule reads a rule for a specific ui item
+func (a *Adapter) ReadRule(dataVersion string, uiItemID int, ID int) (*model.Rule, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	data, err := a.readData(dataVersion)
+	if err != nil {
+		log.Print(err.Error())
+		return nil, err
+	}
+	if data == nil {
+		log.Println("ReadRule - data is nil")
+		return nil, errors.New("ReadRule - data is nil")
+	}
+
+	//1. check if there is a ui item with the provided id
+	uiItemsList := data.UIItems
+	uiItem, _ := a.findUIItem(uiItemID, uiItemsList)
+	if uiItem == nil {
+		return nil, errors.New("there is no a ui item with the provided id")
+	}
+
+	//2. check if there is a rule with the provided id
+	rulesList := data.Rules
+	rule, _ := a.findRule(ID, rulesList)
+	if rule == nil {
+		return nil, errors.New("there is no a rule with the provided id")
+	}
+
+	//3. read rule types
+	ruleTypesList := data.RuleTypes
+	ruleType, err := a.newRuleType(rule.RuleTypeID, ruleTypesList)
+	if err != nil {
+		return nil, err
+	}
+
+	result := a.toRule(*rule, ruleType)
+	return &result, nil
+}
+
+//CreateRule creates a rule for a specific ui item
+func (a *Adapter) CreateRule(dataVersion string, uiItemID int, ruleTypeID int, value interface{}, onMissing string, negate bool) (*model.Rule, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	data, err := a.readData(dataVersion)
+	if err != nil {
+		log.Print(err.Error())
+		return nil, err
+	}
+	if data == nil {
+		log.Println("CreateRule - data is nil")
+		return nil, errors.New("CreateRule - data is nil")
+	}
+
+	//1. first check if there is ui item for the provided ui item id
+	uiItemsList := data.UIItems
+	uiItem, _ := a.findUIItem(uiItemID, uiItemsList)
+	if uiItem == nil {
+		return nil, errors.New("there is no a ui item with the provided id")
+	}
+
+	//2. check if there is rule type for the provided rule type id
+	ruleTypesList := data.RuleTypes
+	rType := a.findRuleType(ruleTypeID, ruleTypesList)
+	if rType == nil {
+		return nil, errors.New("there is no a rule type with the provided id")
+	}
+
+	//3. Validate the value data for the rule type
+	ruleType, err := model.NewRuleType(rType.ID, rType.Name)
+	if err != nil {
+		return nil, err
+	}
+	err = model.ValidateRuleValue(ruleType, value)
+	if err != nil {
+		return nil, err
+	}
+
+	//4. Add a record in the rules
+	rulesList := data.Rules
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+	uiStorageItems := make([]storageItem, len(rulesList))
+	for index, item := range rulesList {
+		uiStorageItems[index] = item
+	}
+	rulesListBiggestID, err := a.findBiggestID(uiStorageItems)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+	ruleID := rulesListBiggestID + 1
+	newRule := rule{ID: ruleID, RuleTypeID: ruleTypeID, Value: value, OnMissing: onMissing, Negate: negate}
+	rulesList = append(rulesList, newRule)
+
+	//5. Add a record in the relations file
+	rulesUIItemsList := data.RulesUIItems
+	relStorageItems := make([]storageItem, len(rulesUIItemsList))
+	for index, item := range rulesUIItemsList {
+		relStorageItems[index] = item
+	}
+	relBiggestID, err := a.findBiggestID(relStorageItems)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+	relItem := ruleUIItem{ID: relBiggestID + 1, UIItemID: uiItemID, RuleID: ruleID}
+	rulesUIItemsList = append(rulesUIItemsList, relItem)
+
+	//6. upload the files
+	data.Rules = rulesList
+	data.RulesUIItems = rulesUIItemsList
+
+	err = a.saveData(dataVersion, data)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := model.Rule{ID: ruleID, RuleType: ruleType, Value: value, OnMissing: onMissing, Negate: negate}
+	return &rule, nil
+}
+
+//UpdateRule creates a rule for a specific ui item
+func (a *Adapter) UpdateRule(dataVersion string, ID int, uiItemID int, ruleTypeID int, value interface{}, onMissing string, negate bool) (*model.Rule, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	data, err := a.readData(dataVersion)
+	if err != nil {
+		log.Print(err.Error())
+		return nil, err
+	}
+	if data == nil {
+		log.Println("UpdateRule - data is nil")
+		return nil, errors.New("UpdateRule - data is nil")
+	}
+
+	//1. check if there is a ui item with the provided id
+	uiItemsList := data.UIItems
+	contentItem, _ := a.findUIItem(uiItemID, uiItemsList)
+	if contentItem == nil {
+		return nil, errors.New("there is no a ui item with the provided id")
+	}
+
+	//2. download the rules ui items files
+	rulesUIItemsList := data.RulesUIItems
+	rulesList := data.Rules
+
+	//3. find if there is a such rule for the provided ui item
+	foundedRelItem, _ := a.findRuleUIItemRel(ID, uiItemID, rulesUIItemsList)
+	if foundedRelItem == nil {
+		return nil, errors.New("there is no associated rule with the provided ui item id")
+	}
+	//4. find if there is rule for the provided id
+	foundedRule, ruleIndex := a.findRule(ID, rulesList)
+	if foundedRule == nil {
+		return nil, errors.New("there is no rule for the provided id")
+	}
+
+	//5. check if there is rule type for the provided rule type id
+	ruleTypesList := data.RuleTypes
+	rType := a.findRuleType(ruleTypeID, ruleTypesList)
+	if rType == nil {
+		return nil, errors.New("there is no a rule type with the provided id")
+	}
+
+	//6. Validate the value data for the rule type
+	ruleType, err := model.NewRuleType(rType.ID, rType.Name)
+	if err != nil {
+		return nil, err
+	}
+	err = model.ValidateRuleValue(ruleType, value)
+	if err != nil {
+		return nil, err
+	}
+
+	//7. update the item
+	foundedRule.RuleTypeID = ruleTypeID
+	foundedRule.Value = value
+	foundedRule.OnMissing = onMissing
+	foundedRule.Negate = negate
+
+	//8. replace the updated item in the list
+	rulesList[ruleIndex] = *foundedRule
+
+	//9. write the list
+	data.Rules = rulesList
+	err = a.saveData(dataVersion, data)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := a.toRule(*foundedRule, ruleType)
+	return &rule, nil
+}
+
+//DeleteRule deletes a rule for a specific ui item
+func (a *Adapter) DeleteRule(dataVersion string, uiItemID int, ID int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	data, err := a.readData(dataVersion)
+	if err != nil {
+		log.Print(err.Error())
+		return err
+	}
+	if data == nil {
+		log.Println("DeleteRule - data is nil")
+		return errors.New("DeleteRule - data is nil")
+	}
+
+	//1. check if thre is a ui item with the provided id
+	uiItemsList := data.UIItems
+	uiItem, _ := a.findUIItem(uiItemID, uiItemsList)
+	if uiItem == nil {
+		return errors.New("there is no a ui item with the provided id")
+	}
+
+	//2. download the rules ui items files
+	rulesUIItemsList := data.RulesUIItems
+	rulesList := data.Rules
 
 	//3. find if there is a such rule for the provided ui item
 	foundedRelItem, relIndex := a.findRuleUIItemRel(ID, uiItemID, rulesUIItemsList)
 	if foundedRelItem == nil {
 		return errors.New("there is no associated rule with the provided ui item id")
 	}
-	//4. find if there is rule for the provided id
-	foundedRule, ruleIndex := a.findRule(ID, rulesList)
-	if foundedRule == nil {
-		return errors.New("there is no rule for the provided id")
+	//4. find if there is rule for the provided id
+	foundedRule, ruleIndex := a.findRule(ID, rulesList)
+	if foundedRule == nil {
+		return errors.New("there is no rule for the provided id")
+	}
+
+	//5. a named or a shared rule cannot be deleted through a ui item, it must be detached
+	if len(foundedRule.Name) > 0 || len(a.getRuleUIItems(ID, rulesUIItemsList)) > 1 {
+		return errors.New("the rule is shared between ui items, it can only be detached")
+	}
+
+	//6. remove it from the rules and the rel files
+	rulesUIItemsList = append(rulesUIItemsList[:relIndex], rulesUIItemsList[relIndex+1:]...)
+	rulesList = append(rulesList[:ruleIndex], rulesList[ruleIndex+1:]...)
+
+	//7. upload the files
+	data.Rules = rulesList
+	data.RulesUIItems = rulesUIItemsList
+
+	err = a.saveData(dataVersion, data)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+//ReadNamedRules reads all named rules
+func (a *Adapter) ReadNamedRules(dataVersion string) ([]model.Rule, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	data, err := a.readData(dataVersion)
+	if err != nil {
+		log.Print(err.Error())
+		return nil, err
+	}
+	if data == nil {
+		log.Println("ReadNamedRules - data is nil")
+		return nil, errors.New("ReadNamedRules - data is nil")
+	}
+
+	result := []model.Rule{}
+	for _, rule := range data.Rules {
+		if len(rule.Name) == 0 {
+			continue
+		}
+		ruleType, err := a.newRuleType(rule.RuleTypeID, data.RuleTypes)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d - %s", rule.ID, err.Error())
+		}
+		result = append(result, a.toRule(rule, ruleType))
+	}
+	return result, nil
+}
+
+//ReadNamedRule reads a named rule
+func (a *Adapter) ReadNamedRule(dataVersion string, ID int) (*model.Rule, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	data, err := a.readData(dataVersion)
+	if err != nil {
+		log.Print(err.Error())
+		return nil, err
+	}
+	if data == nil {
+		log.Println("ReadNamedRule - data is nil")
+		return nil, errors.New("ReadNamedRule - data is nil")
+	}
+
+	//1. find the named rule
+	founded, _ := a.findRule(ID, data.Rules)
+	if founded == nil || len(founded.Name) == 0 {
+		return nil, errors.New("there is no a named rule with the provided id")
+	}
+
+	//2. read rule types
+	ruleType, err := a.newRuleType(founded.RuleTypeID, data.RuleTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := a.toRule(*founded, ruleType)
+	return &rule, nil
+}
+
+//CreateNamedRule creates a named rule which is not bound to any ui item
+func (a *Adapter) CreateNamedRule(dataVersion string, name string, ruleTypeID int, value interface{}, onMissing string, negate bool) (*model.Rule, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	data, err := a.readData(dataVersion)
+	if err != nil {
+		log.Print(err.Error())
+		return nil, err
+	}
+	if data == nil {
+		log.Println("CreateNamedRule - data is nil")
+		return nil, errors.New("CreateNamedRule - data is nil")
+	}
+
+	//1. the name must be unique between the named rules
+	rulesList := data.Rules
+	if a.findRuleByName(name, rulesList) != nil {
+		return nil, fmt.Errorf("there is already a rule with name %s", name)
+	}
+
+	//2. check if there is rule type for the provided rule type id and validate the value for it
+	rType := a.findRuleType(ruleTypeID, data.RuleTypes)
+	if rType == nil {
+		return nil, errors.New("there is no a rule type with the provided id")
+	}
+	ruleType, err := model.NewRuleType(rType.ID, rType.Name)
+	if err != nil {
+		return nil, err
+	}
+	err = model.ValidateRuleValue(ruleType, value)
+	if err != nil {
+		return nil, err
+	}
+
+	//3. find biggest id
+	storageItems := make([]storageItem, len(rulesList))
+	for index, item := range rulesList {
+		storageItems[index] = item
+	}
+	biggestID, err := a.findBiggestID(storageItems)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+
+	//4. create a new item and add it to the list
+	newRule := rule{ID: biggestID + 1, Name: name, RuleTypeID: ruleTypeID, Value: value, OnMissing: onMissing, Negate: negate}
+	rulesList = append(rulesList, newRule)
+
+	//5. write the list
+	data.Rules = rulesList
+	err = a.saveData(dataVersion, data)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := a.toRule(newRule, ruleType)
+	return &rule, nil
+}
+
+//UpdateNamedRule updates a named rule, the change applies to all ui items it is attached to
+func (a *Adapter) UpdateNamedRule(dataVersion string, ID int, name string, ruleTypeID int, value interface{}, onMissing string, negate bool) (*model.Rule, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	data, err := a.readData(dataVersion)
+	if err != nil {
+		log.Print(err.Error())
+		return nil, err
+	}
+	if data == nil {
+		log.Println("UpdateNamedRule - data is nil")
+		return nil, errors.New("UpdateNamedRule - data is nil")
+	}
+
+	//1. find the named rule
+	rulesList := data.Rules
+	founded, index := a.findRule(ID, rulesList)
+	if founded == nil || len(founded.Name) == 0 {
+		return nil, errors.New("there is no a named rule with the provided id")
+	}
+
+	//2. the name must stay unique between the named rules
+	if founded.Name != name && a.findRuleByName(name, rulesList) != nil {
+		return nil, fmt.Errorf("there is already a rule with name %s", name)
+	}
+
+	//3. check if there is rule type for the provided rule type id and validate the value for it
+	rType := a.findRuleType(ruleTypeID, data.RuleTypes)
+	if rType == nil {
+		return nil, errors.New("there is no a rule type with the provided id")
+	}
+	ruleType, err := model.NewRuleType(rType.ID, rType.Name)
+	if err != nil {
+		return nil, err
+	}
+	err = model.ValidateRuleValue(ruleType, value)
+	if err != nil {
+		return nil, err
+	}
+
+	//4. update the item and replace it in the list
+	founded.Name = name
+	founded.RuleTypeID = ruleTypeID
+	founded.Value = value
+	founded.OnMissing = onMissing
+	founded.Negate = negate
+	rulesList[index] = *founded
+
+	//5. write the list
+	data.Rules = rulesList
+	err = a.saveData(dataVersion, data)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := a.toRule(*founded, ruleType)
+	return &rule, nil
+}
+
+//DeleteNamedRule deletes a named rule, it must not be attached to any ui item
+func (a *Adapter) DeleteNamedRule(dataVersion string, ID int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	data, err := a.readData(dataVersion)
+	if err != nil {
+		log.Print(err.Error())
+		return err
+	}
+	if data == nil {
+		log.Println("DeleteNamedRule - data is nil")
+		return errors.New("DeleteNamedRule - data is nil")
+	}
+
+	//1. find the named rule
+	rulesList := data.Rules
+	founded, index := a.findRule(ID, rulesList)
+	if founded == nil || len(founded.Name) == 0 {
+		return errors.New("there is no a named rule with the provided id")
+	}
+
+	//2. check if there are ui items which use it
+	usage := a.getRuleUIItems(ID, data.RulesUIItems)
+	if len(usage) > 0 {
+		return fmt.Errorf("cannot be deleted because it is attached to ui items %s", a.uiItemsIDs(usage))
+	}
+
+	//3. remove it from the list
+	rulesList = append(rulesList[:index], rulesList[index+1:]...)
+
+	//4. write the list
+	data.Rules = rulesList
+	err = a.saveData(dataVersion, data)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+//ReadRuleUsage reads the ui items which use a rule
+func (a *Adapter) ReadRuleUsage(dataVersion string, ID int) ([]model.UIItem, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	data, err := a.readData(dataVersion)
+	if err != nil {
+		log.Print(err.Error())
+		return nil, err
+	}
+	if data == nil {
+		log.Println("ReadRuleUsage - data is nil")
+		return nil, errors.New("ReadRuleUsage - data is nil")
+	}
+
+	//1. check if there is a rule with the provided id
+	founded, _ := a.findRule(ID, data.Rules)
+	if founded == nil {
+		return nil, errors.New("there is no a rule with the provided id")
+	}
+
+	//2. find the ui items
+	result := []model.UIItem{}
+	for _, rel := range a.getRuleUIItems(ID, data.RulesUIItems) {
+		uiItem, _ := a.findUIItem(rel.UIItemID, data.UIItems)
+		if uiItem == nil {
+			return nil, fmt.Errorf("there is no ui item %d for rule %d", rel.UIItemID, ID)
+		}
+		result = append(result, model.UIItem{ID: uiItem.ID, Name: uiItem.Name, Order: uiItem.Order, DataCategories: uiItem.DataCategories,
+			MatchMode: uiItem.MatchMode, Payload: uiItem.Payload})
+	}
+	return result, nil
+}
+
+//AttachRule attaches a named rule to a ui item
+func (a *Adapter) AttachRule(dataVersion string, uiItemID int, ID int) (*model.Rule, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	data, err := a.readData(dataVersion)
+	if err != nil {
+		log.Print(err.Error())
+		return nil, err
+	}
+	if data == nil {
+		log.Println("AttachRule - data is nil")
+		return nil, errors.New("AttachRule - data is nil")
+	}
+
+	//1. check if there is a ui item with the provided id
+	uiItem, _ := a.findUIItem(uiItemID, data.UIItems)
+	if uiItem == nil {
+		return nil, errors.New("there is no a ui item with the provided id")
+	}
+
+	//2. only named rules can be attached as the other rules belong to a single ui item
+	founded, _ := a.findRule(ID, data.Rules)
+	if founded == nil || len(founded.Name) == 0 {
+		return nil, errors.New("there is no a named rule with the provided id")
+	}
+	ruleType, err := a.newRuleType(founded.RuleTypeID, data.RuleTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	//3. check if it is already attached
+	rulesUIItemsList := data.RulesUIItems
+	foundedRelItem, _ := a.findRuleUIItemRel(ID, uiItemID, rulesUIItemsList)
+	if foundedRelItem != nil {
+		return nil, errors.New("the rule is already attached to the ui item")
+	}
+
+	//4. add a record in the relations file
+	relStorageItems := make([]storageItem, len(rulesUIItemsList))
+	for index, item := range rulesUIItemsList {
+		relStorageItems[index] = item
+	}
+	relBiggestID, err := a.findBiggestID(relStorageItems)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+	relItem := ruleUIItem{ID: relBiggestID + 1, UIItemID: uiItemID, RuleID: ID}
+	rulesUIItemsList = append(rulesUIItemsList, relItem)
+
+	//5. write the list
+	data.RulesUIItems = rulesUIItemsList
+	err = a.saveData(dataVersion, data)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := a.toRule(*founded, ruleType)
+	return &rule, nil
+}
+
+//DetachRule detaches a named rule from a ui item, the rule itself is kept
+func (a *Adapter) DetachRule(dataVersion string, uiItemID int, ID int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	data, err := a.readData(dataVersion)
+	if err != nil {
+		log.Print(err.Error())
+		return err
+	}
+	if data == nil {
+		log.Println("DetachRule - data is nil")
+		return errors.New("DetachRule - data is nil")
+	}
+
+	//1. check if there is a ui item with the provided id
+	uiItem, _ := a.findUIItem(uiItemID, data.UIItems)
+	if uiItem == nil {
+		return errors.New("there is no a ui item with the provided id")
+	}
+
+	//2. check if there is a named rule with the provided id
+	founded, _ := a.findRule(ID, data.Rules)
+	if founded == nil || len(founded.Name) == 0 {
+		return errors.New("there is no a named rule with the provided id")
+	}
+
+	//3. find the relation
+	rulesUIItemsList := data.RulesUIItems
+	foundedRelItem, relIndex := a.findRuleUIItemRel(ID, uiItemID, rulesUIItemsList)
+	if foundedRelItem == nil {
+		return errors.New("the rule is not attached to the ui item")
+	}
+
+	//4. remove it from the rel file
+	rulesUIItemsList = append(rulesUIItemsList[:relIndex], rulesUIItemsList[relIndex+1:]...)
+
+	//5. write the list
+	data.RulesUIItems = rulesUIItemsList
+	err = a.saveData(dataVersion, data)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+//ReadRuleTypes reads all rule types
+func (a *Adapter) ReadRuleTypes(dataVersion string) ([]model.RuleType, error) {
+	data, err := a.readData(dataVersion)
+	if err != nil {
+		log.Print(err.Error())
+		return nil, err
+	}
+	if data == nil {
+		log.Println("ReadRuleTypes - data is nil")
+		return nil, errors.New("ReadRuleTypes - data is nil")
+	}
+
+	ruleTypesList := data.RuleTypes
+
+	list := make([]model.RuleType, len(ruleTypesList))
+
+	for i, ruleType := range ruleTypesList {
+		ruleTypeEntity, err := model.NewRuleType(ruleType.ID, ruleType.Name)
+		if err != nil {
+			return nil, err
+		}
+		list[i] = ruleTypeEntity
+	}
+	return list, nil
+}
+
+//ReadUUIDLists reads all uuid lists
+func (a *Adapter) ReadUUIDLists(dataVersion string) ([]model.UUIDList, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	data, err := a.readData(dataVersion)
+	if err != nil {
+		log.Print(err.Error())
+		return nil, err
 	}
+	if data == nil {
+		log.Println("ReadUUIDLists - data is nil")
+		return nil, errors.New("ReadUUIDLists - data is nil")
+	}
+	return a.toUUIDLists(data.UUIDLists), nil
+}
 
-	//5. remove it from the rules and the rel files
-	rulesUIItemsList = append(rulesUIItemsList[:relIndex], rulesUIItemsList[relIndex+1:]...)
-	rulesList = append(rulesList[:ruleIndex], rulesList[ruleIndex+1:]...)
+//ReadUUIDList reads an uuid list
+func (a *Adapter) ReadUUIDList(dataVersion string, ID int) (*model.UUIDList, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 
-	//6. upload the files
-	data.Rules = rulesList
-	data.RulesUIItems = rulesUIItemsList
+	data, err := a.readData(dataVersion)
+	if err != nil {
+		log.Print(err.Error())
+		return nil, err
+	}
+	if data == nil {
+		log.Println("ReadUUIDList - data is nil")
+		return nil, errors.New("ReadUUIDList - data is nil")
+	}
+
+	founded, _ := a.findUUIDList(ID, data.UUIDLists)
+	if founded == nil {
+		return nil, errors.New("there is no an uuid list with the provided id")
+	}
+	return &model.UUIDList{ID: founded.ID, Name: founded.Name, UUIDs: founded.UUIDs}, nil
+}
+
+//CreateUUIDList creates an uuid list
+func (a *Adapter) CreateUUIDList(dataVersion string, name string, uuids []string) (*model.UUIDList, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	data, err := a.readData(dataVersion)
+	if err != nil {
+		log.Print(err.Error())
+		return nil, err
+	}
+	if data == nil {
+		log.Println("CreateUUIDList - data is nil")
+		return nil, errors.New("CreateUUIDList - data is nil")
+	}
+
+	//1. get uuid lists
+	uuidLists := data.UUIDLists
+
+	//2. the name must be unique as the rules reference the lists by name
+	if a.findUUIDListByName(name, uuidLists) != nil {
+		return nil, fmt.Errorf("there is already an uuid list with name %s", name)
+	}
+
+	//3. find biggest id
+	storageItems := make([]storageItem, len(uuidLists))
+	for index, item := range uuidLists {
+		storageItems[index] = item
+	}
+	biggestID, err := a.findBiggestID(storageItems)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+
+	//4. create a new item and add it to the list
+	newItem := uuidList{ID: biggestID + 1, Name: name, UUIDs: uuids}
+	uuidLists = append(uuidLists, newItem)
+
+	//5. write the list
+	data.UUIDLists = uuidLists
+	err = a.saveData(dataVersion, data)
+	if err != nil {
+		return nil, err
+	}
+	return &model.UUIDList{ID: newItem.ID, Name: newItem.Name, UUIDs: newItem.UUIDs}, nil
+}
+
+//UpdateUUIDList updates an uuid list
+func (a *Adapter) UpdateUUIDList(dataVersion string, ID int, name string, uuids []string) (*model.UUIDList, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	data, err := a.readData(dataVersion)
+	if err != nil {
+		log.Print(err.Error())
+		return nil, err
+	}
+	if data == nil {
+		log.Println("UpdateUUIDList - data is nil")
+		return nil, errors.New("UpdateUUIDList - data is nil")
+	}
+
+	//1. find the item
+	uuidLists := data.UUIDLists
+	founded, index := a.findUUIDList(ID, uuidLists)
+	if founded == nil {
+		return nil, errors.New("there is no an uuid list with the provided id")
+	}
+
+	//2. a list can be renamed only if there is no other list with the same name and no rule references it
+	if founded.Name != name {
+		if a.findUUIDListByName(name, uuidLists) != nil {
+			return nil, fmt.Errorf("there is already an uuid list with name %s", name)
+		}
+		if a.isUUIDListUsed(founded.Name, data) {
+			return nil, errors.New("cannot be renamed because there are rules which reference it")
+		}
+	}
+
+	//3. update the item and replace it in the list
+	founded.Name = name
+	founded.UUIDs = uuids
+	uuidLists[index] = *founded
+
+	//4. write the list
+	data.UUIDLists = uuidLists
+	err = a.saveData(dataVersion, data)
+	if err != nil {
+		return nil, err
+	}
+	return &model.UUIDList{ID: founded.ID, Name: founded.Name, UUIDs: founded.UUIDs}, nil
+}
+
+//DeleteUUIDList deletes an uuid list
+func (a *Adapter) DeleteUUIDList(dataVersion string, ID int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	data, err := a.readData(dataVersion)
+	if err != nil {
+		log.Print(err.Error())
+		return err
+	}
+	if data == nil {
+		log.Println("DeleteUUIDList - data is nil")
+		return errors.New("DeleteUUIDList - data is nil")
+	}
+
+	//1. find the item
+	uuidLists := data.UUIDLists
+	founded, index := a.findUUIDList(ID, uuidLists)
+	if founded == nil {
+		return errors.New("there is no an uuid list with the provided id")
+	}
+
+	//2. check if there are rules which reference it
+	if a.isUUIDListUsed(founded.Name, data) {
+		return errors.New("cannot be deleted because there are rules which reference it")
+	}
+
+	//3. remove it from the list
+	uuidLists = append(uuidLists[:index], uuidLists[index+1:]...)
 
+	//4. write the list
+	data.UUIDLists = uuidLists
 	err = a.saveData(dataVersion, data)
 	if err != nil {
 		return err
@@ -787,30 +1787,71 @@ func (a *Adapter) DeleteRule(dataVersion string, uiItemID int, ID int) error {
 	return nil
 }
 
-//ReadRuleTypes reads all rule types
-func (a *Adapter) ReadRuleTypes(dataVersion string) ([]model.RuleType, error) {
+//ReadUIItemsStrings reads the localized strings tables of all ui items
+func (a *Adapter) ReadUIItemsStrings(dataVersion string) ([]model.UIItemStrings, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
 	data, err := a.readData(dataVersion)
 	if err != nil {
 		log.Print(err.Error())
 		return nil, err
 	}
 	if data == nil {
-		log.Println("ReadRuleTypes - data is nil")
-		return nil, errors.New("ReadRuleTypes - data is nil")
+		log.Println("ReadUIItemsStrings - data is nil")
+		return nil, errors.New("ReadUIItemsStrings - data is nil")
 	}
+	return a.toUIItemsStrings(data.UIItemsStrings), nil
+}
 
-	ruleTypesList := data.RuleTypes
+//UpdateUIItemsStrings replaces the localized strings tables of the provided ui items and locales, an empty table is removed
+func (a *Adapter) UpdateUIItemsStrings(dataVersion string, tables []model.UIItemStrings) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 
-	list := make([]model.RuleType, len(ruleTypesList))
+	data, err := a.readData(dataVersion)
+	if err != nil {
+		log.Print(err.Error())
+		return err
+	}
+	if data == nil {
+		log.Println("UpdateUIItemsStrings - data is nil")
+		return errors.New("UpdateUIItemsStrings - data is nil")
+	}
 
-	for i, ruleType := range ruleTypesList {
-		ruleTypeEntity := model.NewRuleType(ruleType.ID, ruleType.Name)
-		list[i] = *ruleTypeEntity
+	stringsList := data.UIItemsStrings
+	for _, table := range tables {
+		//1. check if there is an ui item with the provided id
+		uiItem, _ := a.findUIItem(table.UIItemID, data.UIItems)
+		if uiItem == nil {
+			return fmt.Errorf("there is no ui item %d", table.UIItemID)
+		}
+
+		//2. remove the current table
+		for index, item := range stringsList {
+			if item.UIItemID == table.UIItemID && item.Locale == table.Locale {
+				stringsList = append(stringsList[:index], stringsList[index+1:]...)
+				break
+			}
+		}
+
+		//3. add the new table
+		if len(table.Strings) > 0 {
+			stringsList = append(stringsList, uiItemStrings{UIItemID: table.UIItemID, Locale: table.Locale, Strings: table.Strings})
+		}
 	}
-	return list, nil
+
+	//4. save the data
+	data.UIItemsStrings = stringsList
+	err = a.saveData(dataVersion, data)
+	if err != nil {
+		return err
+	}
+	return nil
 }
 
-func (a *Adapter) canDeleteUIItem(contentItemID int, uiItemID int, ciuiList []contentItemUIItem, ruiList []ruleUIItem) (bool, string) {
+func (a *Adapter) canDeleteUIItem(contentItemID int, uiItemID int, ciuiList []contentItemUIItem, ruiList []ruleUIItem, childrenList []uiItemChild,
+	contentItemsList []contentItem) (bool, string) {
 	//1. check if there is associated rules
 	for _, rui := range ruiList {
 		if rui.UIItemID == uiItemID {
@@ -818,12 +1859,27 @@ func (a *Adapter) canDeleteUIItem(contentItemID int, uiItemID int, ciuiList []co
 		}
 	}
 
-	//2. check if there is another associated content items except the one we need to delete to
-	for _, ciui := range ciuiList {
-		if ciui.UIItemID == uiItemID && ciui.ContentItemID != contentItemID {
-			return false, "there is associated another content items with this ui item"
+	//2. check if there is children
+	for _, child := range childrenList {
+		if child.ParentUIItemID == uiItemID {
+			return false, "there is children ui items in this ui item"
+		}
+	}
+
+	//3. check if there is another associated content items except the one we need to delete to
+	var links []string
+	for _, ciui := range a.getUIItemContentItems(uiItemID, ciuiList) {
+		if ciui.ContentItemID != contentItemID {
+			link := strconv.Itoa(ciui.ContentItemID)
+			if contentItem, _ := a.findContentItem(ciui.ContentItemID, contentItemsList); contentItem != nil {
+				link = fmt.Sprintf("%d (%s)", contentItem.ID, contentItem.Name)
+			}
+			links = append(links, link)
 		}
 	}
+	if len(links) > 0 {
+		return false, fmt.Sprintf("the ui item is still linked to content items %s, unlink it from them first", strings.Join(links, ", "))
+	}
 	return true, ""
 }
 
@@ -843,7 +1899,7 @@ func (a *Adapter) findBiggestID(list []storageItem) (int, error) {
 	return biggest, nil
 }
 
-func (a *Adapter) readFullUIContent() (map[string][]model.ContentItem, error) {
+func (a *Adapter) readFullUIContent() (map[string]*model.UIContent, error) {
 	data, err := a.readFullData()
 	if err != nil {
 		log.Print(err.Error())
@@ -854,7 +1910,7 @@ func (a *Adapter) readFullUIContent() (map[string][]model.ContentItem, error) {
 		return nil, errors.New("readFullUIContent - data is nil")
 	}
 
-	result := map[string][]model.ContentItem{}
+	result := map[string]*model.UIContent{}
 	for version, item := range data {
 		uiItemsList := item.UIItems
 		contentItemsList := item.ContentItems
@@ -875,13 +1931,24 @@ func (a *Adapter) readFullUIContent() (map[string][]model.ContentItem, error) {
 			if ciuiItems != nil {
 				for index, ciuiItem := range ciuiItems {
 					uiItem, _ := a.findUIItem(ciuiItem.UIItemID, uiItemsList)
-					rules := a.getRules(uiItem.ID, rulesList, ruleTypesList, rulesUIItems)
-					uiItems[index] = model.UIItem{ID: uiItem.ID, Name: uiItem.Name, Order: uiItem.Order, Rules: rules}
+					if uiItem == nil {
+						return nil, fmt.Errorf("%s - there is no ui item %d", version, ciuiItem.UIItemID)
+					}
+					rules, err := a.getRules(uiItem.ID, rulesList, ruleTypesList, rulesUIItems)
+					if err != nil {
+						return nil, fmt.Errorf("%s - %s", version, err.Error())
+					}
+					children, err := a.getChildren(uiItem.ID, item, true, 1)
+					if err != nil {
+						return nil, fmt.Errorf("%s - %s", version, err.Error())
+					}
+					uiItems[index] = model.UIItem{ID: uiItem.ID, Name: uiItem.Name, Order: a.linkOrder(ciuiItem, *uiItem), Rules: rules, DataCategories: uiItem.DataCategories, MatchMode: uiItem.MatchMode, Payload: uiItem.Payload, Children: children}
 				}
 			}
-			contentItems[i] = model.ContentItem{ID: id, Name: name, UIItems: uiItems}
+			contentItems[i] = model.ContentItem{ID: id, Name: name, UIItems: uiItems, Payload: contentItem.Payload, PayloadSchema: contentItem.PayloadSchema}
 		}
-		result[version] = contentItems
+		uiContent := model.NewUIContent(contentItems, a.toUUIDLists(item.UUIDLists), a.toUIItemsStrings(item.UIItemsStrings))
+		result[version] = &uiContent
 	}
 	return result, nil
 }
@@ -913,10 +1980,13 @@ func (a *Adapter) readContentItems(dataVersion string) ([]model.ContentItem, err
 		if ciuiItems != nil {
 			for index, ciuiItem := range ciuiItems {
 				uiItem, _ := a.findUIItem(ciuiItem.UIItemID, uiItemsList)
-				uiItems[index] = model.UIItem{ID: uiItem.ID, Name: uiItem.Name, Order: uiItem.Order, Rules: nil}
+				if uiItem == nil {
+					return nil, fmt.Errorf("there is no ui item %d", ciuiItem.UIItemID)
+				}
+				uiItems[index] = model.UIItem{ID: uiItem.ID, Name: uiItem.Name, Order: a.linkOrder(ciuiItem, *uiItem), Rules: nil, DataCategories: uiItem.DataCategories, MatchMode: uiItem.MatchMode, Payload: uiItem.Payload}
 			}
 		}
-		contentItems[i] = model.ContentItem{ID: id, Name: name, UIItems: uiItems}
+		contentItems[i] = model.ContentItem{ID: id, Name: name, UIItems: uiItems, Payload: contentItem.Payload, PayloadSchema: contentItem.PayloadSchema}
 	}
 	return contentItems, nil
 }
@@ -931,6 +2001,96 @@ func (a *Adapter) getContentItemUIItems(contentItemID int, list []contentItemUII
 	return result
 }
 
+func (a *Adapter) getUIItemContentItems(uiItemID int, list []contentItemUIItem) []contentItemUIItem {
+	var result []contentItemUIItem
+	for _, item := range list {
+		if uiItemID == item.UIItemID {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+//linkOrder gives the order of the ui item in the content item of the link
+func (a *Adapter) linkOrder(rel contentItemUIItem, uiItem uiItem) int {
+	if rel.Order != nil {
+		return *rel.Order
+	}
+	return uiItem.Order
+}
+
+//getChildren gives the nested ui items of an ui item, the rules are read only if withRules is set
+func (a *Adapter) getChildren(parentID int, data *data, withRules bool, depth int) ([]model.UIItem, error) {
+	if depth > maxUIItemDepth {
+		return nil, fmt.Errorf("ui item %d is nested in more than %d levels", parentID, maxUIItemDepth)
+	}
+	childrenRels := a.getUIItemChildren(parentID, data.UIItemsChildren)
+	children := make([]model.UIItem, len(childrenRels))
+	for index, childRel := range childrenRels {
+		uiItem, _ := a.findUIItem(childRel.UIItemID, data.UIItems)
+		if uiItem == nil {
+			return nil, fmt.Errorf("there is no ui item %d", childRel.UIItemID)
+		}
+		var rules *[]model.Rule
+		if withRules {
+			var err error
+			rules, err = a.getRules(uiItem.ID, data.Rules, data.RuleTypes, data.RulesUIItems)
+			if err != nil {
+				return nil, err
+			}
+		}
+		grandchildren, err := a.getChildren(uiItem.ID, data, withRules, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		children[index] = model.UIItem{ID: uiItem.ID, Name: uiItem.Name, Order: uiItem.Order, Rules: rules, DataCategories: uiItem.DataCategories, MatchMode: uiItem.MatchMode, Payload: uiItem.Payload, Children: grandchildren}
+	}
+	return children, nil
+}
+
+func (a *Adapter) getUIItemChildren(parentID int, list []uiItemChild) []uiItemChild {
+	var result []uiItemChild
+	for _, item := range list {
+		if parentID == item.ParentUIItemID {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func (a *Adapter) findUIItemChildRel(parentID int, uiItemID int, list []uiItemChild) (*uiItemChild, int) {
+	for index, item := range list {
+		if item.ParentUIItemID == parentID && item.UIItemID == uiItemID {
+			return &item, index
+		}
+	}
+	return nil, -1
+}
+
+func (a *Adapter) findUIItemParentRel(uiItemID int, list []uiItemChild) *uiItemChild {
+	for _, item := range list {
+		if item.UIItemID == uiItemID {
+			return &item
+		}
+	}
+	return nil
+}
+
+//findTopUIItem gives the top parent of an ui item and the level the ui item is nested in it, a top ui item is on level 0
+func (a *Adapter) findTopUIItem(uiItemID int, list []uiItemChild) (int, int, error) {
+	topID := uiItemID
+	for level := 0; ; level++ {
+		if level > maxUIItemDepth {
+			return 0, 0, fmt.Errorf("ui item %d is nested in more than %d levels", uiItemID, maxUIItemDepth)
+		}
+		parentRel := a.findUIItemParentRel(topID, list)
+		if parentRel == nil {
+			return topID, level, nil
+		}
+		topID = parentRel.ParentUIItemID
+	}
+}
+
 func (a *Adapter) findUIItem(id int, uiItems []uiItem) (*uiItem, int) {
 	for index, uiItem := range uiItems {
 		if id == uiItem.ID {
@@ -976,19 +2136,33 @@ func (a *Adapter) findRuleUIItemRel(ruleID int, uiItemID int, list []ruleUIItem)
 	return nil, -1
 }
 
-func (a *Adapter) getRules(uiItemID int, rules []rule, rulesTypes []ruleType, rulesUIItems []ruleUIItem) *[]model.Rule {
+func (a *Adapter) getRules(uiItemID int, rules []rule, rulesTypes []ruleType, rulesUIItems []ruleUIItem) (*[]model.Rule, error) {
 	var rulesResult []model.Rule
 	for _, ruleItemID := range rulesUIItems {
 		if uiItemID == ruleItemID.UIItemID {
 			rule, _ := a.findRule(ruleItemID.RuleID, rules)
-			ruleType := a.findRuleType(rule.RuleTypeID, rulesTypes)
+			if rule == nil {
+				return nil, fmt.Errorf("there is no rule %d for ui item %d", ruleItemID.RuleID, uiItemID)
+			}
 
-			ruleTypeEntity := *model.NewRuleType(ruleType.ID, ruleType.Name)
-			ruleEntity := model.Rule{ID: rule.ID, RuleType: ruleTypeEntity, Value: rule.Value}
+			ruleTypeEntity, err := a.newRuleType(rule.RuleTypeID, rulesTypes)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d - %s", rule.ID, err.Error())
+			}
+			ruleEntity := a.toRule(*rule, ruleTypeEntity)
 			rulesResult = append(rulesResult, ruleEntity)
 		}
 	}
-	return &rulesResult
+	return &rulesResult, nil
+}
+
+//newRuleType creates the rule type entity for the stored rule type id from the registry
+func (a *Adapter) newRuleType(ruleTypeID int, ruleTypes []ruleType) (model.RuleType, error) {
+	rType := a.findRuleType(ruleTypeID, ruleTypes)
+	if rType == nil {
+		return nil, fmt.Errorf("there is no rule type %d", ruleTypeID)
+	}
+	return model.NewRuleType(rType.ID, rType.Name)
 }
 
 func (a *Adapter) findRule(id int, rules []rule) (*rule, int) {
@@ -1000,6 +2174,37 @@ func (a *Adapter) findRule(id int, rules []rule) (*rule, int) {
 	return nil, -1
 }
 
+func (a *Adapter) findRuleByName(name string, rules []rule) *rule {
+	for _, rule := range rules {
+		if name == rule.Name {
+			return &rule
+		}
+	}
+	return nil
+}
+
+func (a *Adapter) getRuleUIItems(ruleID int, list []ruleUIItem) []ruleUIItem {
+	var result []ruleUIItem
+	for _, item := range list {
+		if item.RuleID == ruleID {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func (a *Adapter) uiItemsIDs(list []ruleUIItem) string {
+	ids := make([]string, len(list))
+	for index, item := range list {
+		ids[index] = strconv.Itoa(item.UIItemID)
+	}
+	return strings.Join(ids, ", ")
+}
+
+func (a *Adapter) toRule(rule rule, ruleType model.RuleType) model.Rule {
+	return model.Rule{ID: rule.ID, Name: rule.Name, RuleType: ruleType, Value: rule.Value, OnMissing: rule.OnMissing, Negate: rule.Negate}
+}
+
 func (a *Adapter) findRuleType(id int, ruleTypes []ruleType) *ruleType {
 	for _, ruleType := range ruleTypes {
 		if id == ruleType.ID {
@@ -1009,10 +2214,70 @@ func (a *Adapter) findRuleType(id int, ruleTypes []ruleType) *ruleType {
 	return nil
 }
 
+func (a *Adapter) findUUIDList(id int, uuidLists []uuidList) (*uuidList, int) {
+	for index, item := range uuidLists {
+		if id == item.ID {
+			return &item, index
+		}
+	}
+	return nil, -1
+}
+
+func (a *Adapter) findUUIDListByName(name string, uuidLists []uuidList) *uuidList {
+	for _, item := range uuidLists {
+		if name == item.Name {
+			return &item
+		}
+	}
+	return nil
+}
+
+//isUUIDListUsed checks if there is an uuid_list rule or a composite rule leaf which references the list
+func (a *Adapter) isUUIDListUsed(name string, data *data) bool {
+	for _, rule := range data.Rules {
+		rType := a.findRuleType(rule.RuleTypeID, data.RuleTypes)
+		if rType == nil {
+			continue
+		}
+		for _, listName := range model.ReferencedUUIDLists(rType.Name, rule.Value) {
+			if listName == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (a *Adapter) toUIItemsStrings(list []uiItemStrings) []model.UIItemStrings {
+	result := make([]model.UIItemStrings, len(list))
+	for index, item := range list {
+		result[index] = model.UIItemStrings{UIItemID: item.UIItemID, Locale: item.Locale, Strings: item.Strings}
+	}
+	return result
+}
+
+func (a *Adapter) removeUIItemStrings(uiItemID int, list []uiItemStrings) []uiItemStrings {
+	var result []uiItemStrings
+	for _, item := range list {
+		if item.UIItemID != uiItemID {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func (a *Adapter) toUUIDLists(uuidLists []uuidList) []model.UUIDList {
+	result := make([]model.UUIDList, len(uuidLists))
+	for index, item := range uuidLists {
+		result[index] = model.UUIDList{ID: item.ID, Name: item.Name, UUIDs: item.UUIDs}
+	}
+	return result
+}
+
 func (a *Adapter) readData(dataVersion string) (*data, error) {
 	filter := bson.D{primitive.E{Key: "version", Value: dataVersion}}
 	var dataItems []*dataItem
-	err := a.db.tchdata.Find(filter, &dataItems, nil)
+	err := a.tchdata.Find(filter, &dataItems, nil)
 	if err != nil {
 		log.Printf("Cannot find data item for %s - %s\n", dataVersion, err)
 		return nil, err
@@ -1034,7 +2299,7 @@ func (a *Adapter) readData(dataVersion string) (*data, error) {
 func (a *Adapter) readFullData() (map[string]*data, error) {
 	filter := bson.D{}
 	var results []*dataItem
-	err := a.db.tchdata.Find(filter, &results, nil)
+	err := a.tchdata.Find(filter, &results, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -1057,7 +2322,7 @@ func (a *Adapter) saveData(dataVersion string, data *data) error {
 	//1. find the item
 	filter := bson.D{primitive.E{Key: "version", Value: dataVersion}}
 	var dataItems []*dataItem
-	err := a.db.tchdata.Find(filter, &dataItems, nil)
+	err := a.tchdata.Find(filter, &dataItems, nil)
 	if err != nil {
 		log.Printf("Cannot find data item for %s - %s\n", dataVersion, err)
 		return err
@@ -1079,7 +2344,7 @@ func (a *Adapter) saveData(dataVersion string, data *data) error {
 	dataItem.Data = string(d)
 
 	//4. save it
-	err = a.db.tchdata.ReplaceOne(filter, dataItem, nil)
+	err = a.tchdata.ReplaceOne(filter, dataItem, nil)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,428 @@
+/*
+ *   Copyright (c) 2020 Board of Trustees of the University of Illinois.
+ *   All rights reserved.
+
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+
+ *   http://www.apache.org/licenses/LICENSE-2.0
+
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package mongodb
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+//memoryCollection keeps the data versions in memory instead of the tchdata collection
+type memoryCollection struct {
+	items map[string]string
+}
+
+func (mc *memoryCollection) Find(filter interface{}, result interface{}, findOptions *options.FindOptions) error {
+	version := ""
+	for _, element := range filter.(bson.D) {
+		if element.Key == "version" {
+			version = element.Value.(string)
+		}
+	}
+	dataItems := result.(*[]*dataItem)
+	for itemVersion, itemData := range mc.items {
+		if version == "" || version == itemVersion {
+			*dataItems = append(*dataItems, &dataItem{Version: itemVersion, Data: itemData})
+		}
+	}
+	return nil
+}
+
+func (mc *memoryCollection) ReplaceOne(filter interface{}, replacement interface{}, replaceOptions *options.ReplaceOptions) error {
+	item := replacement.(*dataItem)
+	mc.items[item.Version] = item.Data
+	return nil
+}
+
+const testDataVersion = "1.0"
+
+func newTestAdapter(t *testing.T, initial data) *Adapter {
+	d, err := json.Marshal(initial)
+	if err != nil {
+		t.Fatalf("Cannot marshal the data - %s", err.Error())
+	}
+	collection := &memoryCollection{items: map[string]string{testDataVersion: string(d)}}
+	return &Adapter{tchdata: collection, mu: &sync.Mutex{}}
+}
+
+func expectError(t *testing.T, err error, contains string) {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("Expected an error containing \"%s\"", contains)
+	}
+	if !strings.Contains(err.Error(), contains) {
+		t.Fatalf("Expected an error containing \"%s\" but got \"%s\"", contains, err.Error())
+	}
+}
+
+func TestUUIDListGuards(t *testing.T) {
+	adapter := newTestAdapter(t, data{
+		RuleTypes: []ruleType{{ID: 1, Name: "uuid_list"}, {ID: 2, Name: "composite"}},
+		Rules: []rule{
+			{ID: 1, RuleTypeID: 1, Value: map[string]interface{}{"list": "pilot"}},
+			{ID: 2, RuleTypeID: 2, Value: map[string]interface{}{"or": []interface{}{
+				map[string]interface{}{"auth": map[string]interface{}{"shibbolethLoggedIn": true}},
+				map[string]interface{}{"not": map[string]interface{}{"uuid_list": map[string]interface{}{"list": "staff"}}}}}},
+		},
+		UUIDLists: []uuidList{{ID: 1, Name: "pilot", UUIDs: []string{"uuid-1"}},
+			{ID: 2, Name: "staff", UUIDs: []string{"uuid-2"}},
+			{ID: 3, Name: "unused", UUIDs: []string{"uuid-3"}}},
+	})
+
+	//lists referenced directly or from a composite leaf cannot be renamed or deleted
+	_, err := adapter.UpdateUUIDList(testDataVersion, 1, "pilot2", []string{"uuid-1"})
+	expectError(t, err, "cannot be renamed")
+	_, err = adapter.UpdateUUIDList(testDataVersion, 2, "staff2", []string{"uuid-2"})
+	expectError(t, err, "cannot be renamed")
+	err = adapter.DeleteUUIDList(testDataVersion, 1)
+	expectError(t, err, "cannot be deleted")
+	err = adapter.DeleteUUIDList(testDataVersion, 2)
+	expectError(t, err, "cannot be deleted")
+	_, err = adapter.UpdateUUIDList(testDataVersion, 3, "pilot", []string{"uuid-3"})
+	expectError(t, err, "already an uuid list with name pilot")
+
+	//the uuids of a referenced list can still be changed
+	updated, err := adapter.UpdateUUIDList(testDataVersion, 1, "pilot", []string{"uuid-1", "uuid-4"})
+	if err != nil {
+		t.Fatalf("Cannot update the uuids of a referenced list - %s", err.Error())
+	}
+	if len(updated.UUIDs) != 2 {
+		t.Errorf("Wrong uuids %v", updated.UUIDs)
+	}
+
+	//a not referenced list can be renamed and deleted
+	_, err = adapter.UpdateUUIDList(testDataVersion, 3, "unused2", []string{"uuid-3"})
+	if err != nil {
+		t.Fatalf("Cannot rename a not referenced list - %s", err.Error())
+	}
+	err = adapter.DeleteUUIDList(testDataVersion, 3)
+	if err != nil {
+		t.Fatalf("Cannot delete a not referenced list - %s", err.Error())
+	}
+	uuidLists, err := adapter.ReadUUIDLists(testDataVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uuidLists) != 2 {
+		t.Errorf("There should be 2 uuid lists but there are %d", len(uuidLists))
+	}
+}
+
+func TestChildUIItems(t *testing.T) {
+	adapter := newTestAdapter(t, data{
+		ContentItems:        []contentItem{{ID: 1, Name: "browse"}},
+		UIItems:             []uiItem{{ID: 1, Name: "parent", Order: 1}},
+		ContentItemsUIItems: []contentItemUIItem{{ID: 1, ContentItemID: 1, UIItemID: 1}},
+	})
+
+	child, err := adapter.CreateChildUIItem(testDataVersion, 1, "child", 1, nil, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grandchild, err := adapter.CreateChildUIItem(testDataVersion, child.ID, "grandchild", 1, nil, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = adapter.CreateChildUIItem(testDataVersion, 10, "orphan", 1, nil, "", nil)
+	expectError(t, err, "there is no a parent ui item")
+
+	parent, err := adapter.ReadUIItem(testDataVersion, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parent.Children) != 1 || parent.Children[0].ID != child.ID ||
+		len(parent.Children[0].Children) != 1 || parent.Children[0].Children[0].ID != grandchild.ID {
+		t.Fatalf("Wrong children %v", parent.Children)
+	}
+
+	//a child ui item is updated only through its parent
+	updated, err := adapter.UpdateChildUIItem(testDataVersion, 1, child.ID, "renamed", 2, nil, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Name != "renamed" || updated.Order != 2 {
+		t.Errorf("Wrong updated child %v", updated)
+	}
+	_, err = adapter.UpdateChildUIItem(testDataVersion, 1, grandchild.ID, "grandchild", 1, nil, "", nil)
+	expectError(t, err, "there is no child ui item")
+	readChild, err := adapter.ReadChildUIItem(testDataVersion, 1, child.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if readChild.Name != "renamed" || len(readChild.Children) != 1 {
+		t.Errorf("Wrong read child %v", readChild)
+	}
+
+	//the ui items which still have children cannot be deleted
+	err = adapter.DeleteUIItem(testDataVersion, 1, 1)
+	expectError(t, err, "there is children ui items")
+	err = adapter.DeleteChildUIItem(testDataVersion, 1, child.ID)
+	expectError(t, err, "there is children ui items")
+	err = adapter.DeleteChildUIItem(testDataVersion, 1, grandchild.ID)
+	expectError(t, err, "there is no child ui item")
+
+	for _, item := range []struct{ parentID, ID int }{{child.ID, grandchild.ID}, {1, child.ID}} {
+		err = adapter.DeleteChildUIItem(testDataVersion, item.parentID, item.ID)
+		if err != nil {
+			t.Fatalf("Cannot delete child ui item %d - %s", item.ID, err.Error())
+		}
+	}
+	err = adapter.DeleteUIItem(testDataVersion, 1, 1)
+	if err != nil {
+		t.Fatalf("Cannot delete the parent ui item without children - %s", err.Error())
+	}
+}
+
+func TestChildUIItemsDepth(t *testing.T) {
+	adapter := newTestAdapter(t, data{
+		ContentItems:        []contentItem{{ID: 1, Name: "browse"}},
+		UIItems:             []uiItem{{ID: 1, Name: "level 0"}},
+		ContentItemsUIItems: []contentItemUIItem{{ID: 1, ContentItemID: 1, UIItemID: 1}},
+	})
+
+	parentID := 1
+	for level := 1; level < maxUIItemDepth; level++ {
+		child, err := adapter.CreateChildUIItem(testDataVersion, parentID, fmt.Sprintf("level %d", level), 1, nil, "", nil)
+		if err != nil {
+			t.Fatalf("Cannot create a child ui item on level %d - %s", level, err.Error())
+		}
+		parentID = child.ID
+	}
+	_, err := adapter.CreateChildUIItem(testDataVersion, parentID, "too deep", 1, nil, "", nil)
+	expectError(t, err, "cannot be nested in more than")
+
+	//the deepest allowed nesting can be read
+	_, err = adapter.ReadUIItem(testDataVersion, 1, 1)
+	if err != nil {
+		t.Fatalf("Cannot read the nested ui items - %s", err.Error())
+	}
+	_, err = adapter.ReadUIContent()
+	if err != nil {
+		t.Fatalf("Cannot read the ui content - %s", err.Error())
+	}
+
+	//a cycle in the stored relations is reported instead of being followed
+	cycle := newTestAdapter(t, data{
+		ContentItems:        []contentItem{{ID: 1, Name: "browse"}},
+		UIItems:             []uiItem{{ID: 1, Name: "first"}, {ID: 2, Name: "second"}},
+		ContentItemsUIItems: []contentItemUIItem{{ID: 1, ContentItemID: 1, UIItemID: 1}},
+		UIItemsChildren:     []uiItemChild{{ID: 1, ParentUIItemID: 1, UIItemID: 2}, {ID: 2, ParentUIItemID: 2, UIItemID: 1}},
+	})
+	_, err = cycle.ReadUIItem(testDataVersion, 1, 1)
+	expectError(t, err, "is nested in more than")
+	_, err = cycle.CreateChildUIItem(testDataVersion, 2, "third", 1, nil, "", nil)
+	expectError(t, err, "is nested in more than")
+}
+
+func TestNamedRules(t *testing.T) {
+	adapter := newTestAdapter(t, data{
+		ContentItems: []contentItem{{ID: 1, Name: "browse"}},
+		UIItems:      []uiItem{{ID: 1, Name: "first", Order: 1}, {ID: 2, Name: "second", Order: 2}},
+		ContentItemsUIItems: []contentItemUIItem{{ID: 1, ContentItemID: 1, UIItemID: 1},
+			{ID: 2, ContentItemID: 1, UIItemID: 2}},
+		RuleTypes: []ruleType{{ID: 1, Name: "enable"}},
+	})
+
+	named, err := adapter.CreateNamedRule(testDataVersion, "students", 1, true, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = adapter.CreateNamedRule(testDataVersion, "students", 1, false, "", false)
+	expectError(t, err, "there is already a rule with name students")
+
+	//the same named rule is attached to two ui items
+	for _, uiItemID := range []int{1, 2} {
+		_, err = adapter.AttachRule(testDataVersion, uiItemID, named.ID)
+		if err != nil {
+			t.Fatalf("Cannot attach the rule to ui item %d - %s", uiItemID, err.Error())
+		}
+		uiItem, err := adapter.ReadUIItem(testDataVersion, 1, uiItemID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if uiItem.Rules == nil || len(*uiItem.Rules) != 1 || (*uiItem.Rules)[0].ID != named.ID {
+			t.Errorf("Ui item %d should have the named rule %v", uiItemID, uiItem.Rules)
+		}
+	}
+	_, err = adapter.AttachRule(testDataVersion, 1, named.ID)
+	expectError(t, err, "already attached")
+
+	usage, err := adapter.ReadRuleUsage(testDataVersion, named.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(usage) != 2 || usage[0].ID != 1 || usage[0].Name != "first" || usage[1].ID != 2 || usage[1].Name != "second" {
+		t.Errorf("Wrong rule usage %v", usage)
+	}
+
+	//a shared rule cannot be deleted through a ui item or while it is attached
+	err = adapter.DeleteRule(testDataVersion, 1, named.ID)
+	expectError(t, err, "it can only be detached")
+	err = adapter.DeleteNamedRule(testDataVersion, named.ID)
+	expectError(t, err, "attached to ui items 1, 2")
+
+	//detaching from one ui item keeps the other link
+	err = adapter.DetachRule(testDataVersion, 1, named.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = adapter.DetachRule(testDataVersion, 1, named.ID)
+	expectError(t, err, "not attached")
+	usage, err = adapter.ReadRuleUsage(testDataVersion, named.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(usage) != 1 || usage[0].ID != 2 {
+		t.Errorf("Wrong rule usage after detaching %v", usage)
+	}
+	second, err := adapter.ReadUIItem(testDataVersion, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Rules == nil || len(*second.Rules) != 1 {
+		t.Errorf("The second ui item should keep the named rule %v", second.Rules)
+	}
+
+	//an ui item own rule is deleted through the ui item
+	own, err := adapter.CreateRule(testDataVersion, 1, 1, false, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = adapter.DeleteRule(testDataVersion, 1, own.ID)
+	if err != nil {
+		t.Fatalf("Cannot delete the own rule - %s", err.Error())
+	}
+
+	err = adapter.DetachRule(testDataVersion, 2, named.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = adapter.DeleteNamedRule(testDataVersion, named.ID)
+	if err != nil {
+		t.Fatalf("Cannot delete the not attached named rule - %s", err.Error())
+	}
+}
+
+func TestLinkUIItems(t *testing.T) {
+	adapter := newTestAdapter(t, data{
+		ContentItems: []contentItem{{ID: 1, Name: "browse"}, {ID: 2, Name: "home"}},
+		UIItems:      []uiItem{{ID: 1, Name: "shared", Order: 5}, {ID: 2, Name: "parent", Order: 1}, {ID: 3, Name: "child", Order: 1}},
+		ContentItemsUIItems: []contentItemUIItem{{ID: 1, ContentItemID: 1, UIItemID: 1},
+			{ID: 2, ContentItemID: 1, UIItemID: 2}},
+		UIItemsChildren: []uiItemChild{{ID: 1, ParentUIItemID: 2, UIItemID: 3}},
+	})
+	orderIn := func(contentItemID int, uiItemID int) int {
+		contentItem, err := adapter.ReadContentItem(testDataVersion, contentItemID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, uiItem := range contentItem.UIItems {
+			if uiItem.ID == uiItemID {
+				return uiItem.Order
+			}
+		}
+		t.Fatalf("Ui item %d is not in content item %d", uiItemID, contentItemID)
+		return 0
+	}
+
+	linked, err := adapter.LinkUIItem(testDataVersion, 2, 1, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if linked.Order != 7 {
+		t.Errorf("The linked ui item should have the link order but has %d", linked.Order)
+	}
+	_, err = adapter.LinkUIItem(testDataVersion, 2, 1, 7)
+	expectError(t, err, "already linked")
+	_, err = adapter.LinkUIItem(testDataVersion, 2, 3, 1)
+	expectError(t, err, "a child ui item cannot be linked")
+	_, err = adapter.LinkUIItem(testDataVersion, 9, 1, 1)
+	expectError(t, err, "there is no a content item")
+
+	//every link keeps its own order, the first one uses the ui item order
+	if orderIn(1, 1) != 5 || orderIn(2, 1) != 7 {
+		t.Errorf("Wrong orders %d and %d", orderIn(1, 1), orderIn(2, 1))
+	}
+	contentItems, err := adapter.ReadContentItems(testDataVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contentItems) != 2 || len(contentItems[1].UIItems) != 1 || contentItems[1].UIItems[0].Order != 7 {
+		t.Errorf("Wrong content items %v", contentItems)
+	}
+
+	//updating through a link changes the order of the link only, the other fields are shared
+	updated, err := adapter.UpdateUIItem(testDataVersion, 2, 1, "renamed", 8, nil, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Order != 8 || orderIn(2, 1) != 8 || orderIn(1, 1) != 5 {
+		t.Errorf("Wrong orders after updating the link %d, %d and %d", updated.Order, orderIn(2, 1), orderIn(1, 1))
+	}
+	_, err = adapter.UpdateUIItem(testDataVersion, 1, 1, "renamed", 6, nil, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if orderIn(1, 1) != 6 || orderIn(2, 1) != 8 {
+		t.Errorf("Wrong orders after updating the ui item %d and %d", orderIn(1, 1), orderIn(2, 1))
+	}
+	uiItem, err := adapter.ReadUIItem(testDataVersion, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uiItem.Name != "renamed" {
+		t.Errorf("The name should be shared between the links but it is %s", uiItem.Name)
+	}
+
+	//a linked ui item cannot be deleted until it is unlinked from the other content items
+	err = adapter.DeleteUIItem(testDataVersion, 1, 1)
+	expectError(t, err, "the ui item is still linked to content items 2 (home), unlink it from them first")
+	err = adapter.UnlinkUIItem(testDataVersion, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = adapter.UnlinkUIItem(testDataVersion, 1, 1)
+	expectError(t, err, "not linked")
+	err = adapter.UnlinkUIItem(testDataVersion, 2, 1)
+	expectError(t, err, "it must be deleted instead")
+	if orderIn(2, 1) != 8 {
+		t.Errorf("The remaining link should keep its order but has %d", orderIn(2, 1))
+	}
+	err = adapter.DeleteUIItem(testDataVersion, 2, 1)
+	if err != nil {
+		t.Fatalf("Cannot delete the ui item with a single link - %s", err.Error())
+	}
+}
+
+func TestReadContentItemsMissingUIItem(t *testing.T) {
+	adapter := newTestAdapter(t, data{
+		ContentItems:        []contentItem{{ID: 1, Name: "browse"}},
+		ContentItemsUIItems: []contentItemUIItem{{ID: 1, ContentItemID: 1, UIItemID: 9}},
+	})
+
+	_, err := adapter.ReadContentItem(testDataVersion, 1)
+	expectError(t, err, "there is no ui item 9")
+	_, err = adapter.ReadContentItems(testDataVersion)
+	expectError(t, err, "there is no ui item 9")
+}
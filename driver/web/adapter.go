@@ -20,6 +20,7 @@ package web
 import (
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"talent-chooser/core"
 	"talent-chooser/driver/web/rest"
@@ -107,7 +108,9 @@ func (we Adapter) Start() {
 	restSubrouter.HandleFunc("/version", we.wrapFunc(we.apisHandler.Version)).Methods("GET")
 	restSubrouter.HandleFunc("/ui-content", we.apiKeysAuthWrapFunc(we.apisHandler.GetUIContent)).Methods("GET")
 	restSubrouter.HandleFunc("/v2/ui-content", we.apiKeysAuthWrapFunc(we.apisHandler.GetUIContentV2)).Methods("GET")
-	restSubrouter.HandleFunc("/v3/ui-content", we.apiKeysAuthWrapFunc(we.apisHandler.GetUIContentV3)).Methods("GET")
+	restSubrouter.HandleFunc("/v3/ui-content", we.apiKeysAuthWrapFunc(we.explainAuthWrapFunc(we.apisHandler.GetUIContentV3))).Methods("GET")
+	restSubrouter.HandleFunc("/v4/ui-content", we.apiKeysAuthWrapFunc(we.explainAuthWrapFunc(we.apisHandler.GetUIContentV4))).Methods("GET")
+	restSubrouter.HandleFunc("/v5/ui-content", we.apiKeysAuthWrapFunc(we.explainAuthWrapFunc(we.apisHandler.GetUIContentV5))).Methods("GET")
 
 	// handle admin rest apis
 	adminrestSubrouter := router.PathPrefix("/talent-chooser/admin").Subrouter()
@@ -129,14 +132,41 @@ func (we Adapter) Start() {
 	adminrestSubrouter.HandleFunc("/content-items/{content-item-id}/ui-items", we.jwtAuthWrapFunc(we.adminApisHandler.CreateUIItem)).Methods("POST")
 	adminrestSubrouter.HandleFunc("/content-items/{content-item-id}/ui-items/{id}", we.jwtAuthWrapFunc(we.adminApisHandler.UpdateUIItem)).Methods("PUT")
 	adminrestSubrouter.HandleFunc("/content-items/{content-item-id}/ui-items/{id}", we.jwtAuthWrapFunc(we.adminApisHandler.DeleteUIItem)).Methods("DELETE")
+	adminrestSubrouter.HandleFunc("/content-items/{content-item-id}/linked-ui-items/{id}", we.jwtAuthWrapFunc(we.adminApisHandler.LinkUIItem)).Methods("PUT")
+	adminrestSubrouter.HandleFunc("/content-items/{content-item-id}/linked-ui-items/{id}", we.jwtAuthWrapFunc(we.adminApisHandler.UnlinkUIItem)).Methods("DELETE")
+
+	adminrestSubrouter.HandleFunc("/ui-items/{parent-id}/children/{id}", we.jwtAuthWrapFunc(we.adminApisHandler.GetChildUIItem)).Methods("GET")
+	adminrestSubrouter.HandleFunc("/ui-items/{parent-id}/children", we.jwtAuthWrapFunc(we.adminApisHandler.CreateChildUIItem)).Methods("POST")
+	adminrestSubrouter.HandleFunc("/ui-items/{parent-id}/children/{id}", we.jwtAuthWrapFunc(we.adminApisHandler.UpdateChildUIItem)).Methods("PUT")
+	adminrestSubrouter.HandleFunc("/ui-items/{parent-id}/children/{id}", we.jwtAuthWrapFunc(we.adminApisHandler.DeleteChildUIItem)).Methods("DELETE")
 
 	adminrestSubrouter.HandleFunc("/ui-items/{ui-item-id}/rules/{id}", we.jwtAuthWrapFunc(we.adminApisHandler.GetRule)).Methods("GET")
 	adminrestSubrouter.HandleFunc("/ui-items/{ui-item-id}/rules", we.jwtAuthWrapFunc(we.adminApisHandler.CreateRule)).Methods("POST")
 	adminrestSubrouter.HandleFunc("/ui-items/{ui-item-id}/rules/{id}", we.jwtAuthWrapFunc(we.adminApisHandler.UpdateRule)).Methods("PUT")
 	adminrestSubrouter.HandleFunc("/ui-items/{ui-item-id}/rules/{id}", we.jwtAuthWrapFunc(we.adminApisHandler.DeleteRule)).Methods("DELETE")
 
+	adminrestSubrouter.HandleFunc("/ui-items/{ui-item-id}/named-rules/{id}", we.jwtAuthWrapFunc(we.adminApisHandler.AttachRule)).Methods("PUT")
+	adminrestSubrouter.HandleFunc("/ui-items/{ui-item-id}/named-rules/{id}", we.jwtAuthWrapFunc(we.adminApisHandler.DetachRule)).Methods("DELETE")
+
+	adminrestSubrouter.HandleFunc("/rules", we.jwtAuthWrapFunc(we.adminApisHandler.GetNamedRules)).Methods("GET")
+	adminrestSubrouter.HandleFunc("/rules/{id}", we.jwtAuthWrapFunc(we.adminApisHandler.GetNamedRule)).Methods("GET")
+	adminrestSubrouter.HandleFunc("/rules", we.jwtAuthWrapFunc(we.adminApisHandler.CreateNamedRule)).Methods("POST")
+	adminrestSubrouter.HandleFunc("/rules/{id}", we.jwtAuthWrapFunc(we.adminApisHandler.UpdateNamedRule)).Methods("PUT")
+	adminrestSubrouter.HandleFunc("/rules/{id}", we.jwtAuthWrapFunc(we.adminApisHandler.DeleteNamedRule)).Methods("DELETE")
+	adminrestSubrouter.HandleFunc("/rules/{id}/usage", we.jwtAuthWrapFunc(we.adminApisHandler.GetRuleUsage)).Methods("GET")
+
 	adminrestSubrouter.HandleFunc("/rule-types", we.jwtAuthWrapFunc(we.adminApisHandler.GetRuleTypes)).Methods("GET")
 
+	adminrestSubrouter.HandleFunc("/uuid-lists", we.jwtAuthWrapFunc(we.adminApisHandler.GetUUIDLists)).Methods("GET")
+	adminrestSubrouter.HandleFunc("/uuid-lists/{id}", we.jwtAuthWrapFunc(we.adminApisHandler.GetUUIDList)).Methods("GET")
+	adminrestSubrouter.HandleFunc("/uuid-lists", we.jwtAuthWrapFunc(we.adminApisHandler.CreateUUIDList)).Methods("POST")
+	adminrestSubrouter.HandleFunc("/uuid-lists/{id}", we.jwtAuthWrapFunc(we.adminApisHandler.UpdateUUIDList)).Methods("PUT")
+	adminrestSubrouter.HandleFunc("/uuid-lists/{id}", we.jwtAuthWrapFunc(we.adminApisHandler.DeleteUUIDList)).Methods("DELETE")
+
+	adminrestSubrouter.HandleFunc("/strings", we.jwtAuthWrapFunc(we.adminApisHandler.GetUIItemsStrings)).Methods("GET")
+	adminrestSubrouter.HandleFunc("/strings", we.jwtAuthWrapFunc(we.adminApisHandler.UpdateUIItemsStrings)).Methods("PUT")
+	adminrestSubrouter.HandleFunc("/strings/missing", we.jwtAuthWrapFunc(we.adminApisHandler.GetMissingUIItemsStrings)).Methods("GET")
+
 	log.Fatal(http.ListenAndServe(":80", router))
 }
 
@@ -171,6 +201,26 @@ func (we Adapter) apiKeysAuthWrapFunc(handler http.HandlerFunc) http.HandlerFunc
 	}
 }
 
+//explainAuthWrapFunc allows the explain mode only for the admins as it exposes the rules
+func (we Adapter) explainAuthWrapFunc(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		explain, err := rest.IsExplainRequest(req)
+		if err != nil {
+			log.Println(err.Error())
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if explain && !we.auth.jwtAuth.isValid(req) {
+			//the rules are never given to not authenticated requests
+			log.Println("401 - Unauthorized explain request")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		handler(w, req)
+	}
+}
+
 func (we Adapter) jwtAuthWrapFunc(handler http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		utils.LogRequest(req)
@@ -187,11 +237,11 @@ func (we Adapter) jwtAuthWrapFunc(handler http.HandlerFunc) http.HandlerFunc {
 //NewWebAdapter creates new WebAdapter instance
 func NewWebAdapter(appKeys []string, jwtKey string, app *core.Application,
 	host string, oidcProvider string, oidcClientID string, oidcClientSecret string,
-	redirectURL string) Adapter {
+	redirectURL string, adminGroup string, trustedProxies []*net.IPNet, ruleHeaders []string) Adapter {
 
-	auth := NewAuth(app, host, oidcProvider, oidcClientID, oidcClientSecret, redirectURL, jwtKey, appKeys)
+	auth := NewAuth(app, host, oidcProvider, oidcClientID, oidcClientSecret, redirectURL, jwtKey, appKeys, adminGroup)
 
-	apisHandler := rest.NewApisHandler(app)
+	apisHandler := rest.NewApisHandler(app, trustedProxies, ruleHeaders)
 	adminApisHandler := rest.NewAdminApisHandler(app)
 
 	return Adapter{host: host, auth: auth, apisHandler: apisHandler, adminApisHandler: adminApisHandler, app: app}
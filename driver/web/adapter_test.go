@@ -0,0 +1,69 @@
+/*
+ *   Copyright (c) 2020 Board of Trustees of the University of Illinois.
+ *   All rights reserved.
+
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+
+ *   http://www.apache.org/licenses/LICENSE-2.0
+
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExplainAuthWrapFunc(t *testing.T) {
+	jwtAuth := newJWTAuth("test-key")
+	we := Adapter{auth: &Auth{jwtAuth: jwtAuth}}
+	token, _, err := jwtAuth.createToken("admin")
+	if err != nil {
+		t.Fatalf("Cannot create a token %s", err.Error())
+	}
+
+	items := []struct {
+		query  string
+		token  string
+		status int
+		called bool
+	}{
+		{"", "", http.StatusOK, true},
+		{"?explain=false", "", http.StatusOK, true},
+		{"?explain=0", "", http.StatusOK, true},
+		{"?explain=true", "", http.StatusUnauthorized, false},
+		{"?explain=1", "", http.StatusUnauthorized, false},
+		{"?explain=true", "not a token", http.StatusUnauthorized, false},
+		{"?explain=true", token, http.StatusOK, true},
+		{"?explain=TRUE", token, http.StatusOK, true},
+		{"?explain=yes", token, http.StatusBadRequest, false},
+		{"?explain=yes", "", http.StatusBadRequest, false},
+	}
+	for _, item := range items {
+		called := false
+		handler := we.explainAuthWrapFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+			w.WriteHeader(http.StatusOK)
+		})
+
+		req := httptest.NewRequest("GET", "/talent-chooser/api/v3/ui-content"+item.query, nil)
+		if len(item.token) > 0 {
+			req.AddCookie(&http.Cookie{Name: "tch-token", Value: item.token})
+		}
+		recorder := httptest.NewRecorder()
+		handler(recorder, req)
+
+		if recorder.Code != item.status || called != item.called {
+			t.Errorf("%s with token %t - status %d and called %t", item.query, len(item.token) > 0, recorder.Code, called)
+		}
+	}
+}
@@ -42,6 +42,7 @@ type Auth struct {
 	app *core.Application
 
 	host         string
+	adminGroup   string
 	oidcProvider *oidc.Provider
 	oauth2Config oauth2.Config
 	statesLock   *sync.Mutex
@@ -211,7 +212,7 @@ func (auth *Auth) callback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	//Check if member of urn:mace:uiuc.edu:urbana:authman:app-rokwire-service-policy-rokwire admin app
+	//Check if member of the configured admin group
 	isMember := auth.isMemberOf(claims.UIuceduIsMemberOf)
 	if !isMember {
 		log.Printf("403 - Forbidden access for user %s\n", claims.Username)
@@ -230,7 +231,7 @@ func (auth *Auth) isMemberOf(groups *[]string) bool {
 		return false
 	}
 	for _, group := range *groups {
-		if group == "urn:mace:uiuc.edu:urbana:authman:app-rokwire-service-policy-rokwire admin app" {
+		if group == auth.adminGroup {
 			return true
 		}
 	}
@@ -281,7 +282,7 @@ func (auth *Auth) apiKeyCheck(w http.ResponseWriter, r *http.Request) bool {
 
 //NewAuth creates new auth handler
 func NewAuth(app *core.Application, host string, oidcProvider string, oidcClientID string,
-	oidcClientSecret string, redirectURL string, jwtKey string, appKeys []string) *Auth {
+	oidcClientSecret string, redirectURL string, jwtKey string, appKeys []string, adminGroup string) *Auth {
 
 	provider, err := oidc.NewProvider(context.Background(), oidcProvider)
 	if err != nil {
@@ -304,7 +305,7 @@ func NewAuth(app *core.Application, host string, oidcProvider string, oidcClient
 	statesLock := &sync.Mutex{}
 	states := []*state{}
 
-	auth := Auth{app: app, host: host, oidcProvider: provider, oauth2Config: oauth2Config,
+	auth := Auth{app: app, host: host, adminGroup: adminGroup, oidcProvider: provider, oauth2Config: oauth2Config,
 		states: states, statesLock: statesLock,
 		apiKeysAuth: newAPIKeysAuth(appKeys), jwtAuth: newJWTAuth(jwtKey)}
 	return &auth
@@ -429,6 +430,19 @@ func (jwtAuth *JWTAuth) check(w http.ResponseWriter, r *http.Request) bool {
 	return true
 }
 
+//isValid checks if the request has a valid jwt token without writing a response
+func (jwtAuth *JWTAuth) isValid(r *http.Request) bool {
+	token, err := r.Cookie("tch-token")
+	if token == nil || err != nil {
+		return false
+	}
+	claims := &Claims{}
+	tkn, err := jwt.ParseWithClaims(token.Value, claims, func(token *jwt.Token) (interface{}, error) {
+		return jwtAuth.jwtKey, nil
+	})
+	return err == nil && tkn.Valid
+}
+
 //NewJWTAuth creates new jwt auth
 func newJWTAuth(jwtKey string) *JWTAuth {
 	jwtAuth := JWTAuth{jwtKey: []byte(jwtKey)}
@@ -24,7 +24,9 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"talent-chooser/core"
+	"talent-chooser/core/model"
 
 	"github.com/gorilla/mux"
 )
@@ -34,31 +36,86 @@ type setDataVersion struct {
 }
 
 type createContentItem struct {
-	Name string `json:"name"`
+	Name          string                 `json:"name"`
+	Payload       interface{}            `json:"payload"`
+	PayloadSchema map[string]interface{} `json:"payload-schema"`
 }
 
 type updateContentItem struct {
-	Name string `json:"name"`
+	Name          string                 `json:"name"`
+	Payload       interface{}            `json:"payload"`
+	PayloadSchema map[string]interface{} `json:"payload-schema"`
 }
 
 type createUIItem struct {
-	Name  string `json:"name"`
-	Order int    `json:"order"`
+	Name           string      `json:"name"`
+	Order          int         `json:"order"`
+	DataCategories []string    `json:"data-categories"`
+	MatchMode      string      `json:"match-mode"`
+	Payload        interface{} `json:"payload"`
 }
 
 type updateUIItem struct {
-	Name  string `json:"name"`
-	Order int    `json:"order"`
+	Name           string      `json:"name"`
+	Order          int         `json:"order"`
+	DataCategories []string    `json:"data-categories"`
+	MatchMode      string      `json:"match-mode"`
+	Payload        interface{} `json:"payload"`
+}
+
+type linkUIItem struct {
+	Order int `json:"order"`
 }
 
 type createRule struct {
 	RuleTypeID int         `json:"rule-type-id"`
 	Value      interface{} `json:"value"`
+	OnMissing  string      `json:"on-missing"`
+	Negate     bool        `json:"negate"`
 }
 
 type updateRule struct {
 	RuleTypeID int         `json:"rule-type-id"`
 	Value      interface{} `json:"value"`
+	OnMissing  string      `json:"on-missing"`
+	Negate     bool        `json:"negate"`
+}
+
+type createNamedRule struct {
+	Name       string      `json:"name"`
+	RuleTypeID int         `json:"rule-type-id"`
+	Value      interface{} `json:"value"`
+	OnMissing  string      `json:"on-missing"`
+	Negate     bool        `json:"negate"`
+}
+
+type updateNamedRule struct {
+	Name       string      `json:"name"`
+	RuleTypeID int         `json:"rule-type-id"`
+	Value      interface{} `json:"value"`
+	OnMissing  string      `json:"on-missing"`
+	Negate     bool        `json:"negate"`
+}
+
+type createUUIDList struct {
+	Name  string   `json:"name"`
+	UUIDs []string `json:"uuids"`
+}
+
+type updateUUIDList struct {
+	Name  string   `json:"name"`
+	UUIDs []string `json:"uuids"`
+}
+
+type ruleTypeResponse struct {
+	ID     int                    `json:"id"`
+	Name   string                 `json:"name"`
+	Schema map[string]interface{} `json:"schema"`
+}
+
+type validationErrorResponse struct {
+	Message string                  `json:"message"`
+	Errors  []model.ValidationError `json:"errors"`
 }
 
 //AdminApisHandler handles the admin rest APIs implementation
@@ -248,9 +305,13 @@ func (h AdminApisHandler) CreateContentItem(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	contentItem, err := h.app.Administration.CreateContentItem(*versionCookie, name)
+	contentItem, err := h.app.Administration.CreateContentItem(*versionCookie, name, requestData.Payload, requestData.PayloadSchema)
 	if err != nil {
 		log.Println("Error on creating the content item")
+		if validationErrors, ok := err.(model.ValidationErrors); ok {
+			writeValidationErrors(w, "the provided content item is not valid", validationErrors)
+			return
+		}
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
@@ -308,9 +369,13 @@ func (h AdminApisHandler) UpdateContentItem(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	contentItem, err := h.app.Administration.UpdateContentItem(*versionCookie, numberID, name)
+	contentItem, err := h.app.Administration.UpdateContentItem(*versionCookie, numberID, name, requestData.Payload, requestData.PayloadSchema)
 	if err != nil {
 		log.Println("Error on updating the content item")
+		if validationErrors, ok := err.(model.ValidationErrors); ok {
+			writeValidationErrors(w, "the provided content item is not valid", validationErrors)
+			return
+		}
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
@@ -455,9 +520,13 @@ func (h AdminApisHandler) CreateUIItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	uiItem, err := h.app.Administration.CreateUIItem(*versionCookie, contentItemNumberID, name, order)
+	uiItem, err := h.app.Administration.CreateUIItem(*versionCookie, contentItemNumberID, name, order, requestData.DataCategories, requestData.MatchMode, requestData.Payload)
 	if err != nil {
-		log.Println("Error on creating the ui item")
+		log.Printf("Error on creating the ui item - %s\n", err.Error())
+		if validationErrors, ok := err.(model.ValidationErrors); ok {
+			writeValidationErrors(w, "the provided ui item is not valid", validationErrors)
+			return
+		}
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
@@ -527,9 +596,13 @@ func (h AdminApisHandler) UpdateUIItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	uiItem, err := h.app.Administration.UpdateUIItem(*versionCookie, contentItemNumberID, numberID, name, order)
+	uiItem, err := h.app.Administration.UpdateUIItem(*versionCookie, contentItemNumberID, numberID, name, order, requestData.DataCategories, requestData.MatchMode, requestData.Payload)
 	if err != nil {
 		log.Printf("Error on updating the ui item %s", err.Error())
+		if validationErrors, ok := err.(model.ValidationErrors); ok {
+			writeValidationErrors(w, "the provided ui item is not valid", validationErrors)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -586,8 +659,8 @@ func (h AdminApisHandler) DeleteUIItem(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("Successfully deleted an item"))
 }
 
-//GetRule gets a rule for a specific ui item
-func (h AdminApisHandler) GetRule(w http.ResponseWriter, r *http.Request) {
+// LinkUIItem links an existing ui item to a specific content item
+func (h AdminApisHandler) LinkUIItem(w http.ResponseWriter, r *http.Request) {
 	versionCookie := getDataVersionCookie(r)
 	if versionCookie == nil {
 		log.Println("Version cookie error")
@@ -596,17 +669,17 @@ func (h AdminApisHandler) GetRule(w http.ResponseWriter, r *http.Request) {
 	}
 
 	params := mux.Vars(r)
-	uiItemID := params["ui-item-id"]
+	contentItemID := params["content-item-id"]
 	ID := params["id"]
-	if len(uiItemID) <= 0 || len(ID) <= 0 {
-		log.Println("UI item id and id are required")
-		http.Error(w, "UI item id and id are required", http.StatusBadRequest)
+	if len(contentItemID) <= 0 || len(ID) <= 0 {
+		log.Println("Content item id and id are required")
+		http.Error(w, "Content item id and id are required", http.StatusBadRequest)
 		return
 	}
-	uiItemNumberID, err := strconv.Atoi(uiItemID)
+	contentItemNumberID, err := strconv.Atoi(contentItemID)
 	if err != nil {
-		log.Println("The ui item id must be number")
-		http.Error(w, "The ui item id must be number", http.StatusBadRequest)
+		log.Println("The content item id must be number")
+		http.Error(w, "The content item id must be number", http.StatusBadRequest)
 		return
 	}
 	numberID, err := strconv.Atoi(ID)
@@ -615,17 +688,125 @@ func (h AdminApisHandler) GetRule(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "The id must be number", http.StatusBadRequest)
 		return
 	}
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("Error on marshal the link ui item - %s\n", err.Error())
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
 
-	rule, err := h.app.Administration.GetRule(*versionCookie, uiItemNumberID, numberID)
+	var requestData linkUIItem
+	err = json.Unmarshal(data, &requestData)
+	if err != nil {
+		log.Printf("Error on unmarshal the link ui item request data - %s\n", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	uiItem, err := h.app.Administration.LinkUIItem(*versionCookie, contentItemNumberID, numberID, requestData.Order)
+	if err != nil {
+		log.Printf("Error on linking the ui item - %s\n", err.Error())
+		if validationErrors, ok := err.(model.ValidationErrors); ok {
+			writeValidationErrors(w, "the ui item payloads are not valid for the content item", validationErrors)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data, err = json.Marshal(uiItem)
+	if err != nil {
+		log.Println("Error on marshal the linked ui item")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+//UnlinkUIItem unlinks a ui item from a specific content item
+func (h AdminApisHandler) UnlinkUIItem(w http.ResponseWriter, r *http.Request) {
+	versionCookie := getDataVersionCookie(r)
+	if versionCookie == nil {
+		log.Println("Version cookie error")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	params := mux.Vars(r)
+	contentItemID := params["content-item-id"]
+	ID := params["id"]
+	if len(contentItemID) <= 0 || len(ID) <= 0 {
+		log.Println("Content item id and id are required")
+		http.Error(w, "Content item id and id are required", http.StatusBadRequest)
+		return
+	}
+	contentItemNumberID, err := strconv.Atoi(contentItemID)
+	if err != nil {
+		log.Println("The content item id must be number")
+		http.Error(w, "The content item id must be number", http.StatusBadRequest)
+		return
+	}
+	numberID, err := strconv.Atoi(ID)
+	if err != nil {
+		log.Println("The id must be number")
+		http.Error(w, "The id must be number", http.StatusBadRequest)
+		return
+	}
+
+	err = h.app.Administration.UnlinkUIItem(*versionCookie, contentItemNumberID, numberID)
 	if err != nil {
 		log.Println(err.Error())
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	data, err := json.Marshal(rule)
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Successfully unlinked an item"))
+}
+
+// GetChildUIItem gets a child ui item of a specific ui item
+func (h AdminApisHandler) GetChildUIItem(w http.ResponseWriter, r *http.Request) {
+	versionCookie := getDataVersionCookie(r)
+	if versionCookie == nil {
+		log.Println("Version cookie error")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	params := mux.Vars(r)
+	parentID := params["parent-id"]
+	ID := params["id"]
+	if len(parentID) <= 0 || len(ID) <= 0 {
+		log.Println("Parent id and id are required")
+		http.Error(w, "Parent id and id are required", http.StatusBadRequest)
+		return
+	}
+	parentNumberID, err := strconv.Atoi(parentID)
 	if err != nil {
-		log.Println("Error on marshal the rule item when get")
+		log.Println("The parent id must be number")
+		http.Error(w, "The parent id must be number", http.StatusBadRequest)
+		return
+	}
+	numberID, err := strconv.Atoi(ID)
+	if err != nil {
+		log.Println("The id must be number")
+		http.Error(w, "The id must be number", http.StatusBadRequest)
+		return
+	}
+
+	uiItem, err := h.app.Administration.GetChildUIItem(*versionCookie, parentNumberID, numberID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data, err := json.Marshal(uiItem)
+	if err != nil {
+		log.Println("Error on marshal the child ui item when get")
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
@@ -635,8 +816,8 @@ func (h AdminApisHandler) GetRule(w http.ResponseWriter, r *http.Request) {
 	w.Write(data)
 }
 
-//CreateRule creates a rule for a specific ui item
-func (h AdminApisHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
+//CreateChildUIItem creates a child ui item for a specific ui item
+func (h AdminApisHandler) CreateChildUIItem(w http.ResponseWriter, r *http.Request) {
 	versionCookie := getDataVersionCookie(r)
 	if versionCookie == nil {
 		log.Println("Version cookie error")
@@ -645,54 +826,57 @@ func (h AdminApisHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
 	}
 
 	params := mux.Vars(r)
-	uiItemID := params["ui-item-id"]
-	if len(uiItemID) <= 0 {
-		log.Println("ui item id is required")
-		http.Error(w, "ui item id is required", http.StatusBadRequest)
+	parentID := params["parent-id"]
+	if len(parentID) <= 0 {
+		log.Println("Parent id is required")
+		http.Error(w, "Parent id is required", http.StatusBadRequest)
 		return
 	}
-	uiItemNumberID, err := strconv.Atoi(uiItemID)
+	parentNumberID, err := strconv.Atoi(parentID)
 	if err != nil {
-		log.Println("The ui item id must be number")
-		http.Error(w, "The ui item id must be number", http.StatusBadRequest)
+		log.Println("The parent id must be number")
+		http.Error(w, "The parent id must be number", http.StatusBadRequest)
 		return
 	}
 	data, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		log.Printf("Error on marshal the create rule item - %s\n", err.Error())
+		log.Printf("Error on marshal the create child ui item - %s\n", err.Error())
 		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 
-	var requestData createRule
+	var requestData createUIItem
 	err = json.Unmarshal(data, &requestData)
 	if err != nil {
-		log.Printf("Error on unmarshal the create rule item request data - %s\n", err.Error())
+		log.Printf("Error on unmarshal the create child ui item request data - %s\n", err.Error())
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	ruleTypeID := requestData.RuleTypeID
-	if ruleTypeID <= 0 {
-		http.Error(w, "Rule type should be possitive", http.StatusBadRequest)
+	name := requestData.Name
+	if len(name) == 0 {
+		http.Error(w, "Name cannot be empty", http.StatusBadRequest)
 		return
 	}
-	value := requestData.Value
-	if value == nil {
-		http.Error(w, "Value should not be empty", http.StatusBadRequest)
+	order := requestData.Order
+	if order < 1 {
+		http.Error(w, "Order must be positive", http.StatusBadRequest)
 		return
 	}
 
-	rule, err := h.app.Administration.CreateRule(*versionCookie, uiItemNumberID, ruleTypeID, value)
+	uiItem, err := h.app.Administration.CreateChildUIItem(*versionCookie, parentNumberID, name, order, requestData.DataCategories, requestData.MatchMode, requestData.Payload)
 	if err != nil {
-		log.Println("Error on creating the rule item")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		log.Printf("Error on creating the child ui item - %s\n", err.Error())
+		if validationErrors, ok := err.(model.ValidationErrors); ok {
+			writeValidationErrors(w, "the provided ui item is not valid", validationErrors)
+			return
+		}
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
-
-	data, err = json.Marshal(rule)
+	data, err = json.Marshal(uiItem)
 	if err != nil {
-		log.Println("Error on marshal the rule item")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		log.Println("Error on marshal the ui item")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 
@@ -701,8 +885,8 @@ func (h AdminApisHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
 	w.Write(data)
 }
 
-//UpdateRule updates a rule for a specific ui item
-func (h AdminApisHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+//UpdateChildUIItem updates a child ui item of a specific ui item
+func (h AdminApisHandler) UpdateChildUIItem(w http.ResponseWriter, r *http.Request) {
 	versionCookie := getDataVersionCookie(r)
 	if versionCookie == nil {
 		log.Println("Version cookie error")
@@ -711,17 +895,17 @@ func (h AdminApisHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
 	}
 
 	params := mux.Vars(r)
-	uiItemID := params["ui-item-id"]
+	parentID := params["parent-id"]
 	ID := params["id"]
-	if len(uiItemID) <= 0 || len(ID) <= 0 {
-		log.Println("ui item id and id are required")
-		http.Error(w, "ui item id and id are required", http.StatusBadRequest)
+	if len(parentID) <= 0 || len(ID) <= 0 {
+		log.Println("Parent id and id are required")
+		http.Error(w, "Parent id and id are required", http.StatusBadRequest)
 		return
 	}
-	uiItemNumberID, err := strconv.Atoi(uiItemID)
+	parentNumberID, err := strconv.Atoi(parentID)
 	if err != nil {
-		log.Println("The ui item id must be number")
-		http.Error(w, "The ui item id must be number", http.StatusBadRequest)
+		log.Println("The parent id must be number")
+		http.Error(w, "The parent id must be number", http.StatusBadRequest)
 		return
 	}
 	numberID, err := strconv.Atoi(ID)
@@ -732,40 +916,43 @@ func (h AdminApisHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
 	}
 	data, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		log.Printf("Error on marshal the update rule item - %s\n", err.Error())
+		log.Printf("Error on marshal the update child ui item - %s\n", err.Error())
 		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 
-	var requestData updateRule
+	var requestData updateUIItem
 	err = json.Unmarshal(data, &requestData)
 	if err != nil {
-		log.Printf("Error on unmarshal the create rule item request data - %s\n", err.Error())
+		log.Printf("Error on unmarshal the update child ui item request data - %s\n", err.Error())
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	ruleTypeID := requestData.RuleTypeID
-	if ruleTypeID <= 0 {
-		http.Error(w, "Rule type should be possitive", http.StatusBadRequest)
+	name := requestData.Name
+	if len(name) == 0 {
+		http.Error(w, "Name cannot be empty", http.StatusBadRequest)
 		return
 	}
-	value := requestData.Value
-	if value == nil {
-		http.Error(w, "Value should not be empty", http.StatusBadRequest)
+	order := requestData.Order
+	if order < 1 {
+		http.Error(w, "Order must be positive", http.StatusBadRequest)
 		return
 	}
 
-	rule, err := h.app.Administration.UpdateRule(*versionCookie, numberID, uiItemNumberID, ruleTypeID, value)
+	uiItem, err := h.app.Administration.UpdateChildUIItem(*versionCookie, parentNumberID, numberID, name, order, requestData.DataCategories, requestData.MatchMode, requestData.Payload)
 	if err != nil {
-		log.Println("Error on updating the rule item")
+		log.Printf("Error on updating the child ui item %s", err.Error())
+		if validationErrors, ok := err.(model.ValidationErrors); ok {
+			writeValidationErrors(w, "the provided ui item is not valid", validationErrors)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-
-	data, err = json.Marshal(rule)
+	data, err = json.Marshal(uiItem)
 	if err != nil {
-		log.Println("Error on marshal the rule item")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		log.Println("Error on marshal the ui item")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 
@@ -774,8 +961,8 @@ func (h AdminApisHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
 	w.Write(data)
 }
 
-//DeleteRule deletes a rule for a specific ui item
-func (h AdminApisHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+//DeleteChildUIItem deletes a child ui item of a specific ui item
+func (h AdminApisHandler) DeleteChildUIItem(w http.ResponseWriter, r *http.Request) {
 	versionCookie := getDataVersionCookie(r)
 	if versionCookie == nil {
 		log.Println("Version cookie error")
@@ -784,17 +971,17 @@ func (h AdminApisHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
 	}
 
 	params := mux.Vars(r)
-	uiItemID := params["ui-item-id"]
+	parentID := params["parent-id"]
 	ID := params["id"]
-	if len(uiItemID) <= 0 || len(ID) <= 0 {
-		log.Println("UI item id and id are required")
-		http.Error(w, "UI item id and id are required", http.StatusBadRequest)
+	if len(parentID) <= 0 || len(ID) <= 0 {
+		log.Println("Parent id and id are required")
+		http.Error(w, "Parent id and id are required", http.StatusBadRequest)
 		return
 	}
-	uiItemNumberID, err := strconv.Atoi(uiItemID)
+	parentNumberID, err := strconv.Atoi(parentID)
 	if err != nil {
-		log.Println("The ui item id must be number")
-		http.Error(w, "The ui item id must be number", http.StatusBadRequest)
+		log.Println("The parent id must be number")
+		http.Error(w, "The parent id must be number", http.StatusBadRequest)
 		return
 	}
 	numberID, err := strconv.Atoi(ID)
@@ -804,7 +991,7 @@ func (h AdminApisHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.app.Administration.DeleteRule(*versionCookie, uiItemNumberID, numberID)
+	err = h.app.Administration.DeleteChildUIItem(*versionCookie, parentNumberID, numberID)
 	if err != nil {
 		log.Println(err.Error())
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -815,8 +1002,8 @@ func (h AdminApisHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("Successfully deleted an item"))
 }
 
-//GetRuleTypes gets the rule types
-func (h AdminApisHandler) GetRuleTypes(w http.ResponseWriter, r *http.Request) {
+//GetRule gets a rule for a specific ui item
+func (h AdminApisHandler) GetRule(w http.ResponseWriter, r *http.Request) {
 	versionCookie := getDataVersionCookie(r)
 	if versionCookie == nil {
 		log.Println("Version cookie error")
@@ -824,15 +1011,37 @@ func (h AdminApisHandler) GetRuleTypes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ruleTypes, err := h.app.Administration.GetRuleTypes(*versionCookie)
+	params := mux.Vars(r)
+	uiItemID := params["ui-item-id"]
+	ID := params["id"]
+	if len(uiItemID) <= 0 || len(ID) <= 0 {
+		log.Println("UI item id and id are required")
+		http.Error(w, "UI item id and id are required", http.StatusBadRequest)
+		return
+	}
+	uiItemNumberID, err := strconv.Atoi(uiItemID)
 	if err != nil {
-		log.Println("Error on getting the rule types")
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		log.Println("The ui item id must be number")
+		http.Error(w, "The ui item id must be number", http.StatusBadRequest)
 		return
 	}
-	data, err := json.Marshal(ruleTypes)
+	numberID, err := strconv.Atoi(ID)
 	if err != nil {
-		log.Println("Error on marshal the rule types")
+		log.Println("The id must be number")
+		http.Error(w, "The id must be number", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.app.Administration.GetRule(*versionCookie, uiItemNumberID, numberID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data, err := json.Marshal(rule)
+	if err != nil {
+		log.Println("Error on marshal the rule item when get")
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
@@ -842,9 +1051,901 @@ func (h AdminApisHandler) GetRuleTypes(w http.ResponseWriter, r *http.Request) {
 	w.Write(data)
 }
 
-//NewAdminApisHandler creates new admin rest Handler instance
-func NewAdminApisHandler(app *core.Application) AdminApisHandler {
-	return AdminApisHandler{app: app}
+//CreateRule creates a rule for a specific ui item
+func (h AdminApisHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	versionCookie := getDataVersionCookie(r)
+	if versionCookie == nil {
+		log.Println("Version cookie error")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	params := mux.Vars(r)
+	uiItemID := params["ui-item-id"]
+	if len(uiItemID) <= 0 {
+		log.Println("ui item id is required")
+		http.Error(w, "ui item id is required", http.StatusBadRequest)
+		return
+	}
+	uiItemNumberID, err := strconv.Atoi(uiItemID)
+	if err != nil {
+		log.Println("The ui item id must be number")
+		http.Error(w, "The ui item id must be number", http.StatusBadRequest)
+		return
+	}
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("Error on marshal the create rule item - %s\n", err.Error())
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	var requestData createRule
+	err = json.Unmarshal(data, &requestData)
+	if err != nil {
+		log.Printf("Error on unmarshal the create rule item request data - %s\n", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ruleTypeID := requestData.RuleTypeID
+	if ruleTypeID <= 0 {
+		http.Error(w, "Rule type should be possitive", http.StatusBadRequest)
+		return
+	}
+	value := requestData.Value
+	if value == nil {
+		http.Error(w, "Value should not be empty", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.app.Administration.CreateRule(*versionCookie, uiItemNumberID, ruleTypeID, value, requestData.OnMissing, requestData.Negate)
+	if err != nil {
+		log.Printf("Error on creating the rule item - %s\n", err.Error())
+		if validationErrors, ok := err.(model.ValidationErrors); ok {
+			writeValidationErrors(w, "the provided data is not valid for this rule type", validationErrors)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data, err = json.Marshal(rule)
+	if err != nil {
+		log.Println("Error on marshal the rule item")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+//UpdateRule updates a rule for a specific ui item
+func (h AdminApisHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	versionCookie := getDataVersionCookie(r)
+	if versionCookie == nil {
+		log.Println("Version cookie error")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	params := mux.Vars(r)
+	uiItemID := params["ui-item-id"]
+	ID := params["id"]
+	if len(uiItemID) <= 0 || len(ID) <= 0 {
+		log.Println("ui item id and id are required")
+		http.Error(w, "ui item id and id are required", http.StatusBadRequest)
+		return
+	}
+	uiItemNumberID, err := strconv.Atoi(uiItemID)
+	if err != nil {
+		log.Println("The ui item id must be number")
+		http.Error(w, "The ui item id must be number", http.StatusBadRequest)
+		return
+	}
+	numberID, err := strconv.Atoi(ID)
+	if err != nil {
+		log.Println("The id must be number")
+		http.Error(w, "The id must be number", http.StatusBadRequest)
+		return
+	}
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("Error on marshal the update rule item - %s\n", err.Error())
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	var requestData updateRule
+	err = json.Unmarshal(data, &requestData)
+	if err != nil {
+		log.Printf("Error on unmarshal the create rule item request data - %s\n", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ruleTypeID := requestData.RuleTypeID
+	if ruleTypeID <= 0 {
+		http.Error(w, "Rule type should be possitive", http.StatusBadRequest)
+		return
+	}
+	value := requestData.Value
+	if value == nil {
+		http.Error(w, "Value should not be empty", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.app.Administration.UpdateRule(*versionCookie, numberID, uiItemNumberID, ruleTypeID, value, requestData.OnMissing, requestData.Negate)
+	if err != nil {
+		log.Printf("Error on updating the rule item - %s\n", err.Error())
+		if validationErrors, ok := err.(model.ValidationErrors); ok {
+			writeValidationErrors(w, "the provided data is not valid for this rule type", validationErrors)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data, err = json.Marshal(rule)
+	if err != nil {
+		log.Println("Error on marshal the rule item")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+//DeleteRule deletes a rule for a specific ui item
+func (h AdminApisHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	versionCookie := getDataVersionCookie(r)
+	if versionCookie == nil {
+		log.Println("Version cookie error")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	params := mux.Vars(r)
+	uiItemID := params["ui-item-id"]
+	ID := params["id"]
+	if len(uiItemID) <= 0 || len(ID) <= 0 {
+		log.Println("UI item id and id are required")
+		http.Error(w, "UI item id and id are required", http.StatusBadRequest)
+		return
+	}
+	uiItemNumberID, err := strconv.Atoi(uiItemID)
+	if err != nil {
+		log.Println("The ui item id must be number")
+		http.Error(w, "The ui item id must be number", http.StatusBadRequest)
+		return
+	}
+	numberID, err := strconv.Atoi(ID)
+	if err != nil {
+		log.Println("The id must be number")
+		http.Error(w, "The id must be number", http.StatusBadRequest)
+		return
+	}
+
+	err = h.app.Administration.DeleteRule(*versionCookie, uiItemNumberID, numberID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Successfully deleted an item"))
+}
+
+//GetRuleTypes gets the rule types
+func (h AdminApisHandler) GetRuleTypes(w http.ResponseWriter, r *http.Request) {
+	versionCookie := getDataVersionCookie(r)
+	if versionCookie == nil {
+		log.Println("Version cookie error")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	ruleTypes, err := h.app.Administration.GetRuleTypes(*versionCookie)
+	if err != nil {
+		log.Println("Error on getting the rule types")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	response := make([]ruleTypeResponse, len(ruleTypes))
+	for i, ruleType := range ruleTypes {
+		response[i] = ruleTypeResponse{ID: ruleType.GetID(), Name: ruleType.GetName()}
+		if registration, ok := model.GetRuleTypeRegistration(ruleType.GetName()); ok {
+			response[i].Schema = registration.Schema
+		}
+	}
+	data, err := json.Marshal(response)
+	if err != nil {
+		log.Println("Error on marshal the rule types")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+//GetNamedRules gets all named rules
+func (h AdminApisHandler) GetNamedRules(w http.ResponseWriter, r *http.Request) {
+	versionCookie := getDataVersionCookie(r)
+	if versionCookie == nil {
+		log.Println("Version cookie error")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	rules, err := h.app.Administration.GetNamedRules(*versionCookie)
+	if err != nil {
+		log.Println("Error on getting the named rules")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	data, err := json.Marshal(rules)
+	if err != nil {
+		log.Println("Error on marshal the named rules")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+//GetNamedRule gets named rule by id
+func (h AdminApisHandler) GetNamedRule(w http.ResponseWriter, r *http.Request) {
+	versionCookie := getDataVersionCookie(r)
+	if versionCookie == nil {
+		log.Println("Version cookie error")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	params := mux.Vars(r)
+	ID := params["id"]
+	if len(ID) <= 0 {
+		log.Println("Rule id is required")
+		http.Error(w, "Rule id is required", http.StatusBadRequest)
+		return
+	}
+	numberID, err := strconv.Atoi(ID)
+	if err != nil {
+		log.Println("The id must be number")
+		http.Error(w, "The id must be number", http.StatusBadRequest)
+		return
+	}
+	rule, err := h.app.Administration.GetNamedRule(*versionCookie, numberID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data, err := json.Marshal(rule)
+	if err != nil {
+		log.Println("Error on marshal the named rule when get")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+//CreateNamedRule creates a named rule which can be attached to many ui items
+func (h AdminApisHandler) CreateNamedRule(w http.ResponseWriter, r *http.Request) {
+	versionCookie := getDataVersionCookie(r)
+	if versionCookie == nil {
+		log.Println("Version cookie error")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("Error on marshal the create named rule - %s\n", err.Error())
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	var requestData createNamedRule
+	err = json.Unmarshal(data, &requestData)
+	if err != nil {
+		log.Printf("Error on unmarshal the create named rule request data - %s\n", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if requestData.Value == nil {
+		http.Error(w, "Value should not be empty", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.app.Administration.CreateNamedRule(*versionCookie, requestData.Name, requestData.RuleTypeID, requestData.Value,
+		requestData.OnMissing, requestData.Negate)
+	if err != nil {
+		log.Printf("Error on creating the named rule - %s\n", err.Error())
+		if validationErrors, ok := err.(model.ValidationErrors); ok {
+			writeValidationErrors(w, "the provided data is not valid for this rule type", validationErrors)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data, err = json.Marshal(rule)
+	if err != nil {
+		log.Println("Error on marshal the named rule")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+//UpdateNamedRule updates a named rule for all ui items it is attached to
+func (h AdminApisHandler) UpdateNamedRule(w http.ResponseWriter, r *http.Request) {
+	versionCookie := getDataVersionCookie(r)
+	if versionCookie == nil {
+		log.Println("Version cookie error")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	params := mux.Vars(r)
+	ID := params["id"]
+	if len(ID) <= 0 {
+		log.Println("Rule id is required")
+		http.Error(w, "Rule id is required", http.StatusBadRequest)
+		return
+	}
+	numberID, err := strconv.Atoi(ID)
+	if err != nil {
+		log.Println("The id must be number")
+		http.Error(w, "The id must be number", http.StatusBadRequest)
+		return
+	}
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("Error on marshal the update named rule - %s\n", err.Error())
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	var requestData updateNamedRule
+	err = json.Unmarshal(data, &requestData)
+	if err != nil {
+		log.Printf("Error on unmarshal the update named rule request data - %s\n", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if requestData.Value == nil {
+		http.Error(w, "Value should not be empty", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.app.Administration.UpdateNamedRule(*versionCookie, numberID, requestData.Name, requestData.RuleTypeID, requestData.Value,
+		requestData.OnMissing, requestData.Negate)
+	if err != nil {
+		log.Printf("Error on updating the named rule - %s\n", err.Error())
+		if validationErrors, ok := err.(model.ValidationErrors); ok {
+			writeValidationErrors(w, "the provided data is not valid for this rule type", validationErrors)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data, err = json.Marshal(rule)
+	if err != nil {
+		log.Println("Error on marshal the named rule")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+//DeleteNamedRule deletes a named rule which is not attached to any ui item
+func (h AdminApisHandler) DeleteNamedRule(w http.ResponseWriter, r *http.Request) {
+	versionCookie := getDataVersionCookie(r)
+	if versionCookie == nil {
+		log.Println("Version cookie error")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	params := mux.Vars(r)
+	ID := params["id"]
+	if len(ID) <= 0 {
+		log.Println("Rule id is required")
+		http.Error(w, "Rule id is required", http.StatusBadRequest)
+		return
+	}
+	numberID, err := strconv.Atoi(ID)
+	if err != nil {
+		log.Println("The id must be number")
+		http.Error(w, "The id must be number", http.StatusBadRequest)
+		return
+	}
+
+	err = h.app.Administration.DeleteNamedRule(*versionCookie, numberID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Successfully deleted an item"))
+}
+
+//GetRuleUsage gets the ui items which use a rule
+func (h AdminApisHandler) GetRuleUsage(w http.ResponseWriter, r *http.Request) {
+	versionCookie := getDataVersionCookie(r)
+	if versionCookie == nil {
+		log.Println("Version cookie error")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	params := mux.Vars(r)
+	ID := params["id"]
+	if len(ID) <= 0 {
+		log.Println("Rule id is required")
+		http.Error(w, "Rule id is required", http.StatusBadRequest)
+		return
+	}
+	numberID, err := strconv.Atoi(ID)
+	if err != nil {
+		log.Println("The id must be number")
+		http.Error(w, "The id must be number", http.StatusBadRequest)
+		return
+	}
+	uiItems, err := h.app.Administration.GetRuleUsage(*versionCookie, numberID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data, err := json.Marshal(uiItems)
+	if err != nil {
+		log.Println("Error on marshal the rule usage")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+//AttachRule attaches a named rule to a specific ui item
+func (h AdminApisHandler) AttachRule(w http.ResponseWriter, r *http.Request) {
+	versionCookie := getDataVersionCookie(r)
+	if versionCookie == nil {
+		log.Println("Version cookie error")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	params := mux.Vars(r)
+	uiItemID := params["ui-item-id"]
+	ID := params["id"]
+	if len(uiItemID) <= 0 || len(ID) <= 0 {
+		log.Println("UI item id and id are required")
+		http.Error(w, "UI item id and id are required", http.StatusBadRequest)
+		return
+	}
+	uiItemNumberID, err := strconv.Atoi(uiItemID)
+	if err != nil {
+		log.Println("The ui item id must be number")
+		http.Error(w, "The ui item id must be number", http.StatusBadRequest)
+		return
+	}
+	numberID, err := strconv.Atoi(ID)
+	if err != nil {
+		log.Println("The id must be number")
+		http.Error(w, "The id must be number", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.app.Administration.AttachRule(*versionCookie, uiItemNumberID, numberID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data, err := json.Marshal(rule)
+	if err != nil {
+		log.Println("Error on marshal the attached rule")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+//DetachRule detaches a named rule from a specific ui item
+func (h AdminApisHandler) DetachRule(w http.ResponseWriter, r *http.Request) {
+	versionCookie := getDataVersionCookie(r)
+	if versionCookie == nil {
+		log.Println("Version cookie error")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	params := mux.Vars(r)
+	uiItemID := params["ui-item-id"]
+	ID := params["id"]
+	if len(uiItemID) <= 0 || len(ID) <= 0 {
+		log.Println("UI item id and id are required")
+		http.Error(w, "UI item id and id are required", http.StatusBadRequest)
+		return
+	}
+	uiItemNumberID, err := strconv.Atoi(uiItemID)
+	if err != nil {
+		log.Println("The ui item id must be number")
+		http.Error(w, "The ui item id must be number", http.StatusBadRequest)
+		return
+	}
+	numberID, err := strconv.Atoi(ID)
+	if err != nil {
+		log.Println("The id must be number")
+		http.Error(w, "The id must be number", http.StatusBadRequest)
+		return
+	}
+
+	err = h.app.Administration.DetachRule(*versionCookie, uiItemNumberID, numberID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Successfully detached an item"))
+}
+
+//GetUUIDLists gets all uuid lists
+func (h AdminApisHandler) GetUUIDLists(w http.ResponseWriter, r *http.Request) {
+	versionCookie := getDataVersionCookie(r)
+	if versionCookie == nil {
+		log.Println("Version cookie error")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	uuidLists, err := h.app.Administration.GetUUIDLists(*versionCookie)
+	if err != nil {
+		log.Println("Error on getting the uuid lists")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	data, err := json.Marshal(uuidLists)
+	if err != nil {
+		log.Println("Error on marshal the uuid lists")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+//GetUUIDList gets uuid list by id
+func (h AdminApisHandler) GetUUIDList(w http.ResponseWriter, r *http.Request) {
+	versionCookie := getDataVersionCookie(r)
+	if versionCookie == nil {
+		log.Println("Version cookie error")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	params := mux.Vars(r)
+	ID := params["id"]
+	if len(ID) <= 0 {
+		log.Println("UUID list id is required")
+		http.Error(w, "UUID list id is required", http.StatusBadRequest)
+		return
+	}
+	numberID, err := strconv.Atoi(ID)
+	if err != nil {
+		log.Println("The id must be number")
+		http.Error(w, "The id must be number", http.StatusBadRequest)
+		return
+	}
+	uuidList, err := h.app.Administration.GetUUIDList(*versionCookie, numberID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data, err := json.Marshal(uuidList)
+	if err != nil {
+		log.Println("Error on marshal the uuid list when get")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+//CreateUUIDList creates an uuid list
+func (h AdminApisHandler) CreateUUIDList(w http.ResponseWriter, r *http.Request) {
+	versionCookie := getDataVersionCookie(r)
+	if versionCookie == nil {
+		log.Println("Version cookie error")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("Error on marshal the create uuid list - %s\n", err.Error())
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	var requestData createUUIDList
+	err = json.Unmarshal(data, &requestData)
+	if err != nil {
+		log.Printf("Error on unmarshal the create uuid list request data - %s\n", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	uuidList, err := h.app.Administration.CreateUUIDList(*versionCookie, requestData.Name, requestData.UUIDs)
+	if err != nil {
+		log.Printf("Error on creating the uuid list - %s\n", err.Error())
+		if validationErrors, ok := err.(model.ValidationErrors); ok {
+			writeValidationErrors(w, "the provided uuid list is not valid", validationErrors)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data, err = json.Marshal(uuidList)
+	if err != nil {
+		log.Println("Error on marshal the uuid list")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+//UpdateUUIDList updates an uuid list
+func (h AdminApisHandler) UpdateUUIDList(w http.ResponseWriter, r *http.Request) {
+	versionCookie := getDataVersionCookie(r)
+	if versionCookie == nil {
+		log.Println("Version cookie error")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	params := mux.Vars(r)
+	ID := params["id"]
+	if len(ID) <= 0 {
+		log.Println("UUID list id is required")
+		http.Error(w, "UUID list id is required", http.StatusBadRequest)
+		return
+	}
+	numberID, err := strconv.Atoi(ID)
+	if err != nil {
+		log.Println("The id must be number")
+		http.Error(w, "The id must be number", http.StatusBadRequest)
+		return
+	}
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("Error on marshal the update uuid list - %s\n", err.Error())
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	var requestData updateUUIDList
+	err = json.Unmarshal(data, &requestData)
+	if err != nil {
+		log.Printf("Error on unmarshal the update uuid list request data - %s\n", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	uuidList, err := h.app.Administration.UpdateUUIDList(*versionCookie, numberID, requestData.Name, requestData.UUIDs)
+	if err != nil {
+		log.Printf("Error on updating the uuid list - %s\n", err.Error())
+		if validationErrors, ok := err.(model.ValidationErrors); ok {
+			writeValidationErrors(w, "the provided uuid list is not valid", validationErrors)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data, err = json.Marshal(uuidList)
+	if err != nil {
+		log.Println("Error on marshal the uuid list")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+//DeleteUUIDList deletes an uuid list
+func (h AdminApisHandler) DeleteUUIDList(w http.ResponseWriter, r *http.Request) {
+	versionCookie := getDataVersionCookie(r)
+	if versionCookie == nil {
+		log.Println("Version cookie error")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	params := mux.Vars(r)
+	ID := params["id"]
+	if len(ID) <= 0 {
+		log.Println("UUID list id is required")
+		http.Error(w, "UUID list id is required", http.StatusBadRequest)
+		return
+	}
+	numberID, err := strconv.Atoi(ID)
+	if err != nil {
+		log.Println("The id must be number")
+		http.Error(w, "The id must be number", http.StatusBadRequest)
+		return
+	}
+
+	err = h.app.Administration.DeleteUUIDList(*versionCookie, numberID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Successfully deleted an item"))
+}
+
+//GetUIItemsStrings gets the localized strings tables of all ui items
+func (h AdminApisHandler) GetUIItemsStrings(w http.ResponseWriter, r *http.Request) {
+	versionCookie := getDataVersionCookie(r)
+	if versionCookie == nil {
+		log.Println("Version cookie error")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	tables, err := h.app.Administration.GetUIItemsStrings(*versionCookie)
+	if err != nil {
+		log.Println("Error on getting the ui items strings")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	data, err := json.Marshal(tables)
+	if err != nil {
+		log.Println("Error on marshal the ui items strings")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+//UpdateUIItemsStrings replaces the localized strings tables of the provided ui items and locales
+func (h AdminApisHandler) UpdateUIItemsStrings(w http.ResponseWriter, r *http.Request) {
+	versionCookie := getDataVersionCookie(r)
+	if versionCookie == nil {
+		log.Println("Version cookie error")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("Error on marshal the update ui items strings - %s\n", err.Error())
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	var requestData []model.UIItemStrings
+	err = json.Unmarshal(data, &requestData)
+	if err != nil {
+		log.Printf("Error on unmarshal the update ui items strings request data - %s\n", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.app.Administration.UpdateUIItemsStrings(*versionCookie, requestData)
+	if err != nil {
+		log.Printf("Error on updating the ui items strings - %s\n", err.Error())
+		if validationErrors, ok := err.(model.ValidationErrors); ok {
+			writeValidationErrors(w, "the provided strings are not valid", validationErrors)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Successfully updated"))
+}
+
+//GetMissingUIItemsStrings gets the keys which the ui items have in other locales but not in the requested locales.
+//The locales are given as comma separated "locales" query parameter, all locales are reported if it is not provided.
+func (h AdminApisHandler) GetMissingUIItemsStrings(w http.ResponseWriter, r *http.Request) {
+	versionCookie := getDataVersionCookie(r)
+	if versionCookie == nil {
+		log.Println("Version cookie error")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	var locales []string
+	for _, item := range strings.Split(r.URL.Query().Get("locales"), ",") {
+		if locale := strings.TrimSpace(item); len(locale) > 0 {
+			locales = append(locales, locale)
+		}
+	}
+
+	missing, err := h.app.Administration.GetMissingUIItemsStrings(*versionCookie, locales)
+	if err != nil {
+		log.Println("Error on getting the missing ui items strings")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	data, err := json.Marshal(missing)
+	if err != nil {
+		log.Println("Error on marshal the missing ui items strings")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+//NewAdminApisHandler creates new admin rest Handler instance
+func NewAdminApisHandler(app *core.Application) AdminApisHandler {
+	return AdminApisHandler{app: app}
+}
+
+//writeValidationErrors writes 400 with the field level errors
+func writeValidationErrors(w http.ResponseWriter, message string, validationErrors model.ValidationErrors) {
+	data, err := json.Marshal(validationErrorResponse{Message: message, Errors: validationErrors})
+	if err != nil {
+		log.Println("Error on marshal the validation errors")
+		http.Error(w, validationErrors.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	w.Write(data)
 }
 
 func getDataVersionCookie(r *http.Request) *string {
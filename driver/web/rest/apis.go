@@ -19,9 +19,13 @@ package rest
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
 	"talent-chooser/core"
 	"talent-chooser/core/model"
 )
@@ -29,6 +33,9 @@ import (
 //ApisHandler handles the rest APIs implementation
 type ApisHandler struct {
 	app *core.Application
+
+	trustedProxies []*net.IPNet
+	ruleHeaders    []string
 }
 
 type getUIContentData struct {
@@ -44,13 +51,15 @@ type getUIContentDataV2 struct {
 }
 
 type getUIContentDataV3 struct {
-	User       *userV3       `json:"user"`
-	AuthToken  *authTokenV3  `json:"auth_token"`
-	AuthUser   *authUserV3   `json:"auth_user"`
-	AuthCard   *authCardV3   `json:"card"`
-	IlliniCash *illiniCashV3 `json:"illini_cash"`
-	Platform   *platformV3   `json:"platform"`
-	Pii        *piiV3        `json:"pii"`
+	User       *userV3                `json:"user"`
+	AuthToken  *authTokenV3           `json:"auth_token"`
+	AuthUser   *authUserV3            `json:"auth_user"`
+	AuthCard   *authCardV3            `json:"card"`
+	IlliniCash *illiniCashV3          `json:"illini_cash"`
+	Platform   *platformV3            `json:"platform"`
+	Pii        *piiV3                 `json:"pii"`
+	Attributes map[string]interface{} `json:"attributes"`
+	Location   *locationV3            `json:"location"`
 } // @name getUIContentRequest
 
 type userV3 struct {
@@ -91,9 +100,15 @@ type illiniCashV3 struct {
 } // @name IliniCash
 
 type platformV3 struct {
-	OS *string `json:"os"`
+	OS         *string `json:"os"`
+	AppVersion *string `json:"app_version"`
 } // @name Platform
 
+type locationV3 struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+} // @name Location
+
 type piiV3 struct {
 	DocumentType *string `json:"documentType"`
 } // @name Pii
@@ -217,29 +232,145 @@ type getUIContentV3SwagReturn map[string][]string // @name UIContent
 // @Accept json
 // @Produce json
 // @Param data-version query string false "for example '2.2'"
+// @Param explain query boolean false "gives how every rule of every ui item was evaluated instead of the ui content, requires an admin session, true or false"
 // @Param data body getUIContentDataV3 true "body data"
 // @Success 200 {object} getUIContentV3SwagReturn
 // @Security RokwireAuth
 // @Router /api/v3/ui-content [get]
 func (h ApisHandler) GetUIContentV3(w http.ResponseWriter, r *http.Request) {
+	h.getUIContentWithParamsV3(w, r, "GetUIContentV3", func(dataVersion string, params *uiContentParamsV3) interface{} {
+		return h.app.Services.GetUIContentV3(params.user, dataVersion, params.auth, params.illiniCash, params.platform,
+			params.attributes, params.clientIP, params.headers, params.location)
+	})
+}
+
+type getUIContentV4SwagNode struct {
+	Name     string                   `json:"name"`
+	Children []getUIContentV4SwagNode `json:"children"`
+} // @name UIContentNode
+
+//Swag does not support map!
+type getUIContentV4SwagReturn map[string][]getUIContentV4SwagNode // @name UIContentTree
+
+//GetUIContentV4 gives the ui content based on the parameters keeping the nested ui items - V4
+// @Description Gives the ui content based on the parameters. The ui items are given with their nested ui items, the nested ui items of a not shown ui item are not shown.
+// @Tags APIs
+// @ID GetUIContentV4
+// @Accept json
+// @Produce json
+// @Param data-version query string false "for example '2.2'"
+// @Param explain query boolean false "gives how every rule of every ui item was evaluated instead of the ui content in the same shape as V3 with the nested ui items traces as children, requires an admin session, true or false"
+// @Param data body getUIContentDataV3 true "body data"
+// @Success 200 {object} getUIContentV4SwagReturn
+// @Security RokwireAuth
+// @Router /api/v4/ui-content [get]
+func (h ApisHandler) GetUIContentV4(w http.ResponseWriter, r *http.Request) {
+	h.getUIContentWithParamsV3(w, r, "GetUIContentV4", func(dataVersion string, params *uiContentParamsV3) interface{} {
+		return h.app.Services.GetUIContentV4(params.user, dataVersion, params.auth, params.illiniCash, params.platform,
+			params.attributes, params.clientIP, params.headers, params.location)
+	})
+}
+
+type getUIContentV5SwagUIItem struct {
+	Name     string                     `json:"name"`
+	Payload  interface{}                `json:"payload"`
+	Strings  map[string]string          `json:"strings"`
+	Children []getUIContentV5SwagUIItem `json:"children"`
+} // @name UIItemPayloadNode
+
+type getUIContentV5SwagContentItem struct {
+	Payload interface{}                `json:"payload"`
+	UIItems []getUIContentV5SwagUIItem `json:"ui-items"`
+} // @name ContentItemPayloadNode
+
+//Swag does not support map!
+type getUIContentV5SwagReturn map[string]getUIContentV5SwagContentItem // @name UIContentPayloads
+
+//GetUIContentV5 gives the ui content with the payloads based on the parameters - V5
+// @Description Gives the ui content based on the parameters. Every content item and ui item is given with its payload, the ui items are given with their nested ui items. The ui items strings are given for the most preferred Accept-Language locale which has them, falling back to the default locale.
+// @Tags APIs
+// @ID GetUIContentV5
+// @Accept json
+// @Produce json
+// @Param data-version query string false "for example '2.2'"
+// @Param explain query boolean false "gives how every rule of every ui item was evaluated instead of the ui content in the same shape as V3 with the nested ui items traces as children, requires an admin session, true or false"
+// @Param data body getUIContentDataV3 true "body data"
+// @Success 200 {object} getUIContentV5SwagReturn
+// @Security RokwireAuth
+// @Router /api/v5/ui-content [get]
+func (h ApisHandler) GetUIContentV5(w http.ResponseWriter, r *http.Request) {
+	h.getUIContentWithParamsV3(w, r, "GetUIContentV5", func(dataVersion string, params *uiContentParamsV3) interface{} {
+		return h.app.Services.GetUIContentV5(params.user, dataVersion, params.auth, params.illiniCash, params.platform,
+			params.attributes, params.clientIP, params.headers, params.location, params.locales)
+	})
+}
+
+//getUIContentWithParamsV3 handles the ui content requests which have the V3 parameters. The getUIContent function gives
+//the ui content of the requested version, the explain requests give the V3 explanation for every version.
+func (h ApisHandler) getUIContentWithParamsV3(w http.ResponseWriter, r *http.Request, name string,
+	getUIContent func(dataVersion string, params *uiContentParamsV3) interface{}) {
 	dataVersion := h.getDataVersion(r)
 	log.Println(dataVersion)
 
-	data, err := ioutil.ReadAll(r.Body)
+	params, err := h.readUIContentParamsV3(r)
 	if err != nil {
-		log.Printf("GetUIContentV3 -> error on marshal the ui flat data - %s\n", err.Error())
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		log.Printf("%s -> %s\n", name, err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	explain, err := IsExplainRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	var data []byte
+	if explain {
+		explanation := h.app.Services.ExplainUIContentV3(params.user, dataVersion, params.auth, params.illiniCash, params.platform,
+			params.attributes, params.clientIP, params.headers, params.location)
+		data, err = json.Marshal(explanation)
+	} else {
+		data, err = json.Marshal(getUIContent(dataVersion, params))
+	}
+	if err != nil {
+		log.Printf("%s -> error on marshal the ui content data\n", name)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+//uiContentParamsV3 are the rules input parameters of the V3, V4 and V5 ui content requests
+type uiContentParamsV3 struct {
+	user       *model.User
+	auth       *model.AuthV3
+	illiniCash *model.IlliniCash
+	platform   *model.Platform
+	attributes model.Attributes
+	clientIP   net.IP
+	headers    model.Headers
+	location   *model.Location
+	//locales are the Accept-Language locales ordered by preference, they are read even if the header is not passed to the rules
+	locales []string
+}
+
+//readUIContentParamsV3 reads the rules input parameters from the ui content request, the error is caused by a bad request
+func (h ApisHandler) readUIContentParamsV3(r *http.Request) (*uiContentParamsV3, error) {
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error on reading the request data - %s", err.Error())
+	}
+
 	var requestData getUIContentDataV3
 	//handle the params data if available
 	if len(data) > 0 {
 		err = json.Unmarshal(data, &requestData)
 		if err != nil {
-			log.Printf("GetUIContentV3 -> error on unmarshal the request data - %s\n", err.Error())
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
+			return nil, err
 		}
 	}
 
@@ -248,7 +379,11 @@ func (h ApisHandler) GetUIContentV3(w http.ResponseWriter, r *http.Request) {
 	reqUser := requestData.User
 	if reqUser != nil {
 		privacySettings := model.PrivacySettings{Level: reqUser.PrivacySettings.Level}
-		user = &model.User{UUID: *reqUser.UUID, PrivacySettings: privacySettings, Roles: reqUser.Roles}
+		uuid := ""
+		if reqUser.UUID != nil {
+			uuid = *reqUser.UUID
+		}
+		user = &model.User{UUID: uuid, PrivacySettings: privacySettings, Roles: reqUser.Roles}
 	}
 
 	//auth
@@ -289,23 +424,93 @@ func (h ApisHandler) GetUIContentV3(w http.ResponseWriter, r *http.Request) {
 	var platform *model.Platform
 	reqPlatform := requestData.Platform
 	if reqPlatform != nil {
-		platform = &model.Platform{OS: reqPlatform.OS}
+		platform = &model.Platform{OS: reqPlatform.OS, AppVersion: reqPlatform.AppVersion}
 	}
 
-	uiContent := h.app.Services.GetUIContentV3(user, dataVersion, auth, illiniCash, platform)
-	data, err = json.Marshal(uiContent)
+	//attributes
+	attributes := model.Attributes(requestData.Attributes)
+	err = attributes.Validate()
 	if err != nil {
-		log.Println("GetUIContentV3 -> error on marshal the ui flat data")
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-		return
+		return nil, err
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write(data)
+	//location
+	var location *model.Location
+	reqLocation := requestData.Location
+	if reqLocation != nil {
+		location = &model.Location{Latitude: reqLocation.Latitude, Longitude: reqLocation.Longitude}
+		err = location.Validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	//client ip
+	clientIP := h.getClientIP(r)
+
+	//headers
+	headers := h.getRuleHeaders(r)
+
+	//locales
+	locales := model.ParseAcceptLanguage(strings.Join(r.Header.Values("Accept-Language"), ","))
+
+	return &uiContentParamsV3{user: user, auth: auth, illiniCash: illiniCash, platform: platform, attributes: attributes,
+		clientIP: clientIP, headers: headers, location: location, locales: locales}, nil
+}
+
+//getClientIP gives the address of the client which made the request. The X-Forwarded-For header is used only when
+//the request comes from a trusted proxy, it is read from right to left until the first address which is not a trusted proxy.
+func (h ApisHandler) getClientIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	clientIP := net.ParseIP(host)
+	if clientIP == nil || !model.ContainsIP(h.trustedProxies, clientIP) {
+		return clientIP
+	}
+
+	forwardedFor := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwardedFor) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(forwardedFor[i]))
+		if ip == nil {
+			break //do not trust anything before a malformed entry
+		}
+		clientIP = ip
+		if !model.ContainsIP(h.trustedProxies, ip) {
+			break
+		}
+	}
+	return clientIP
+}
+
+//getRuleHeaders gives the configured request headers which are passed to the rules
+func (h ApisHandler) getRuleHeaders(r *http.Request) model.Headers {
+	headers := model.Headers{}
+	for _, name := range h.ruleHeaders {
+		values := r.Header.Values(name)
+		if len(values) > 0 {
+			headers[http.CanonicalHeaderKey(name)] = strings.Join(values, ", ")
+		}
+	}
+	return headers
+}
+
+//IsExplainRequest checks if the ui content request asks for the rules evaluation explanation, the explain value
+//must be a boolean
+func IsExplainRequest(r *http.Request) (bool, error) {
+	value := r.URL.Query().Get("explain")
+	if len(value) == 0 {
+		return false, nil
+	}
+	explain, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("explain must be true or false, %s is not", value)
+	}
+	return explain, nil
 }
 
 //NewApisHandler creates new rest Handler instance
-func NewApisHandler(app *core.Application) ApisHandler {
-	return ApisHandler{app: app}
+func NewApisHandler(app *core.Application, trustedProxies []*net.IPNet, ruleHeaders []string) ApisHandler {
+	return ApisHandler{app: app, trustedProxies: trustedProxies, ruleHeaders: ruleHeaders}
 }
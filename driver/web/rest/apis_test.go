@@ -0,0 +1,120 @@
+/*
+ *   Copyright (c) 2020 Board of Trustees of the University of Illinois.
+ *   All rights reserved.
+
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+
+ *   http://www.apache.org/licenses/LICENSE-2.0
+
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package rest
+
+import (
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetClientIP(t *testing.T) {
+	var trustedProxies []*net.IPNet
+	for _, cidr := range []string{"10.0.0.0/8", "2001:db8::/32"} {
+		_, ipNet, _ := net.ParseCIDR(cidr)
+		trustedProxies = append(trustedProxies, ipNet)
+	}
+	h := ApisHandler{trustedProxies: trustedProxies}
+
+	items := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		clientIP     string
+	}{
+		{"no proxy", "203.0.113.5:1234", nil, "203.0.113.5"},
+		{"untrusted remote addr with spoofed XFF", "203.0.113.5:1234", []string{"198.51.100.1"}, "203.0.113.5"},
+		{"trusted proxy", "10.0.0.1:1234", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"trusted proxy without XFF", "10.0.0.1:1234", nil, "10.0.0.1"},
+		{"chain of trusted proxies", "10.0.0.1:1234", []string{"198.51.100.1, 10.0.0.3, 10.0.0.2"}, "198.51.100.1"},
+		{"spoofed entry before the client", "10.0.0.1:1234", []string{"192.0.2.9, 198.51.100.1, 10.0.0.2"}, "198.51.100.1"},
+		{"malformed entry", "10.0.0.1:1234", []string{"198.51.100.1, unknown, 10.0.0.2"}, "10.0.0.2"},
+		{"malformed last entry", "10.0.0.1:1234", []string{"198.51.100.1, unknown"}, "10.0.0.1"},
+		{"multiple XFF header lines", "10.0.0.1:1234", []string{"198.51.100.1", "10.0.0.3, 10.0.0.2"}, "198.51.100.1"},
+		{"multiple XFF header lines with untrusted last", "10.0.0.1:1234", []string{"192.0.2.9", "198.51.100.1"}, "198.51.100.1"},
+		{"all trusted chain", "10.0.0.1:1234", []string{"10.0.0.4, 10.0.0.3, 10.0.0.2"}, "10.0.0.4"},
+		{"IPv6 remote addr", "[2001:db8::1]:1234", []string{"2001:db9::5, 2001:db8::2"}, "2001:db9::5"},
+		{"IPv6 untrusted remote addr", "[2001:db9::1]:1234", []string{"198.51.100.1"}, "2001:db9::1"},
+		{"remote addr without port", "10.0.0.1", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"malformed remote addr", "unknown", []string{"198.51.100.1"}, ""},
+	}
+	for _, item := range items {
+		r := httptest.NewRequest("GET", "/api/v3/ui-content", nil)
+		r.RemoteAddr = item.remoteAddr
+		for _, value := range item.forwardedFor {
+			r.Header.Add("X-Forwarded-For", value)
+		}
+
+		clientIP := h.getClientIP(r)
+		if item.clientIP == "" {
+			if clientIP != nil {
+				t.Errorf("%s - no client ip expected but got %s", item.name, clientIP)
+			}
+			continue
+		}
+		if !clientIP.Equal(net.ParseIP(item.clientIP)) {
+			t.Errorf("%s - wrong client ip %s, expected %s", item.name, clientIP, item.clientIP)
+		}
+	}
+}
+
+func TestGetRuleHeaders(t *testing.T) {
+	h := ApisHandler{ruleHeaders: []string{"x-campus", "X-Client-Version", "accept-language"}}
+
+	r := httptest.NewRequest("GET", "/api/v3/ui-content", nil)
+	r.Header.Add("X-Campus", "urbana")
+	r.Header.Add("x-client-version", "3.1.0")
+	r.Header.Add("X-Client-Version", "3.1.1")
+	r.Header.Add("Authorization", "Bearer token")
+	r.Header.Add("X-Other", "value")
+
+	headers := h.getRuleHeaders(r)
+	if len(headers) != 2 {
+		t.Errorf("Only the configured and present headers must be given %v", headers)
+	}
+	if headers["X-Campus"] != "urbana" {
+		t.Errorf("Wrong X-Campus header %s", headers["X-Campus"])
+	}
+	if headers["X-Client-Version"] != "3.1.0, 3.1.1" {
+		t.Errorf("Repeated header must be joined %s", headers["X-Client-Version"])
+	}
+	for _, name := range []string{"Authorization", "X-Other", "x-campus", "Accept-Language"} {
+		if _, exist := headers[name]; exist {
+			t.Errorf("%s must not be given", name)
+		}
+	}
+}
+
+func TestReadUIContentParamsV3Locales(t *testing.T) {
+	h := ApisHandler{ruleHeaders: []string{"User-Agent"}}
+
+	r := httptest.NewRequest("GET", "/api/v5/ui-content", nil)
+	r.Header.Add("Accept-Language", "fr;q=0.5, es-MX")
+	r.Header.Add("Accept-Language", "en;q=0.8")
+	params, err := h.readUIContentParamsV3(r)
+	if err != nil {
+		t.Fatalf("Cannot read the params %s", err.Error())
+	}
+	if _, exist := params.headers.Get("Accept-Language"); exist {
+		t.Error("Accept-Language is not passed to the rules")
+	}
+	if strings.Join(params.locales, ",") != "es-mx,en,fr" {
+		t.Errorf("Wrong locales %v", params.locales)
+	}
+}
@@ -19,14 +19,24 @@ package main
 
 import (
 	"log"
+	"net"
 	"os"
+	"strconv"
 	"strings"
 
 	"talent-chooser/core"
+	"talent-chooser/core/model"
 	"talent-chooser/driven/storage/mongodb"
 	web "talent-chooser/driver/web"
 )
 
+const (
+	defaultRuleHeaders       = "Accept-Language,User-Agent"
+	defaultLocale            = "en"
+	defaultAdminGroup        = "urn:mace:uiuc.edu:urbana:authman:app-rokwire-service-policy-rokwire admin app"
+	defaultEventEditorsGroup = "urn:mace:uiuc.edu:urbana:authman:app-rokwire-service-policy-rokwire event approvers"
+)
+
 var (
 	// Version : version of this executable
 	Version string
@@ -49,8 +59,22 @@ func main() {
 		log.Fatal("Cannot start the mongoDB adapter - " + err.Error())
 	}
 
-	application := core.NewApplication(Version, Build, storageAdapter)
-	application.Start()
+	//groups
+	groupAliases := getGroupAliases()
+	adminGroup := getEnvKeyOrDefault("TCH_ADMIN_GROUP", defaultAdminGroup)
+
+	//privacy
+	privacyDataCategories := getPrivacyDataCategories()
+
+	//localization
+	localeFallbacks := getLocaleFallbacks()
+	locale := model.NormalizeLocale(getEnvKeyOrDefault("TCH_DEFAULT_LOCALE", defaultLocale))
+
+	application := core.NewApplication(Version, Build, storageAdapter, groupAliases, privacyDataCategories, localeFallbacks, locale)
+	err = application.Start()
+	if err != nil {
+		log.Fatal("Cannot start the application - " + err.Error())
+	}
 
 	//APIkeys
 	apiKeys := getAPIKeys()
@@ -60,7 +84,10 @@ func main() {
 	oidcClientID := getEnvKey("TCH_OIDC_CLIENT_ID", true)
 	oidcClientSecret := getEnvKey("TCH_OIDC_CLIENT_SECRET", true)
 	redirectURL := getEnvKey("TCH_OIDC_REDIRECT_URL", true)
-	webAdapter := web.NewWebAdapter(apiKeys, jwtKey, application, host, oidcProvider, oidcClientID, oidcClientSecret, redirectURL)
+	trustedProxies := getTrustedProxies()
+	ruleHeaders := getRuleHeaders()
+	webAdapter := web.NewWebAdapter(apiKeys, jwtKey, application, host, oidcProvider, oidcClientID, oidcClientSecret, redirectURL,
+		adminGroup, trustedProxies, ruleHeaders)
 	webAdapter.Start()
 }
 
@@ -77,6 +104,93 @@ func getAPIKeys() []string {
 	return rokwireAPIKeysList
 }
 
+//getTrustedProxies gives the proxies which are trusted to set X-Forwarded-For in "10.0.0.0/8,2001:db8::1" format
+func getTrustedProxies() []*net.IPNet {
+	var trustedProxies []*net.IPNet
+	value := getEnvKey("TCH_TRUSTED_PROXIES", false)
+	if len(value) == 0 {
+		return trustedProxies
+	}
+	for _, item := range strings.Split(value, ",") {
+		ipNet, err := model.ParseIPNet(item)
+		if err != nil {
+			log.Fatalf("Invalid TCH_TRUSTED_PROXIES item %s - %s", item, err.Error())
+		}
+		trustedProxies = append(trustedProxies, ipNet)
+	}
+	return trustedProxies
+}
+
+//getRuleHeaders gives the request headers which are passed to the rules in "Accept-Language,User-Agent" format
+func getRuleHeaders() []string {
+	var ruleHeaders []string
+	for _, item := range strings.Split(getEnvKeyOrDefault("TCH_RULE_HEADERS", defaultRuleHeaders), ",") {
+		name := strings.TrimSpace(item)
+		if len(name) > 0 {
+			ruleHeaders = append(ruleHeaders, name)
+		}
+	}
+	return ruleHeaders
+}
+
+//getGroupAliases gives the event editors group and the group aliases in "alias1=group1;alias2=group2" format
+func getGroupAliases() model.GroupAliases {
+	groupAliases := model.GroupAliases{}
+	groupAliases[model.EventEditorsGroupAlias] = getEnvKeyOrDefault("TCH_EVENT_EDITORS_GROUP", defaultEventEditorsGroup)
+
+	for alias, group := range getEnvKeyPairs("TCH_GROUP_ALIASES") {
+		groupAliases[alias] = group
+	}
+	return groupAliases
+}
+
+//getPrivacyDataCategories gives the data categories with their minimum privacy levels in "category1=3;category2=4" format
+func getPrivacyDataCategories() model.PrivacyDataCategories {
+	privacyDataCategories := model.PrivacyDataCategories{}
+	for category, value := range getEnvKeyPairs("TCH_PRIVACY_DATA_CATEGORIES") {
+		level, err := strconv.Atoi(value)
+		if err != nil || level < 0 {
+			log.Fatal("Invalid privacy level for data category " + category)
+		}
+		privacyDataCategories[category] = level
+	}
+	return privacyDataCategories
+}
+
+//getLocaleFallbacks gives the locales fallbacks in "zh-hk=zh-hant;zh-mo=zh-hant" format
+func getLocaleFallbacks() model.LocaleFallbacks {
+	localeFallbacks := model.LocaleFallbacks{}
+	for locale, fallback := range getEnvKeyPairs("TCH_LOCALE_FALLBACKS") {
+		localeFallbacks[model.NormalizeLocale(locale)] = model.NormalizeLocale(fallback)
+	}
+	return localeFallbacks
+}
+
+//getEnvKeyPairs gives not required environment variable in "key1=value1;key2=value2" format
+func getEnvKeyPairs(key string) map[string]string {
+	result := map[string]string{}
+	value := getEnvKey(key, false)
+	if len(value) == 0 {
+		return result
+	}
+	for _, item := range strings.Split(value, ";") {
+		pair := strings.SplitN(item, "=", 2)
+		if len(pair) != 2 || len(strings.TrimSpace(pair[0])) == 0 || len(strings.TrimSpace(pair[1])) == 0 {
+			log.Fatalf("Invalid %s item %s", key, item)
+		}
+		result[strings.TrimSpace(pair[0])] = strings.TrimSpace(pair[1])
+	}
+	return result
+}
+
+func getEnvKeyOrDefault(key string, defaultValue string) string {
+	value := getEnvKey(key, false)
+	if len(value) == 0 {
+		return defaultValue
+	}
+	return value
+}
+
 func getEnvKey(key string, required bool) string {
 	//get from the environment
 	value, exist := os.LookupEnv(key)
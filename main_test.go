@@ -18,12 +18,11 @@
 package main
 
 import (
-	"os"
 	"testing"
 )
 
 func TestGetApiKeys(t *testing.T) {
-	os.Setenv("ROKWIRE_API_KEYS", "1234,567,8910;67")
+	t.Setenv("ROKWIRE_API_KEYS", "1234,567,8910;67")
 
 	keys := getAPIKeys()
 	if len(keys) != 3 {
@@ -43,3 +42,49 @@ func TestGetApiKeys(t *testing.T) {
 		t.Errorf("Key3 is wrong %s", key3)
 	}
 }
+
+func TestGetGroupAliases(t *testing.T) {
+	t.Setenv("TCH_EVENT_EDITORS_GROUP", "urn:editors")
+	t.Setenv("TCH_GROUP_ALIASES", "staff=urn:staff group; students = urn:students")
+
+	groupAliases := getGroupAliases()
+	if len(groupAliases) != 3 {
+		t.Errorf("Wrong group aliases size %d", len(groupAliases))
+	}
+	if groupAliases["eventEditors"] != "urn:editors" {
+		t.Errorf("Event editors group is wrong %s", groupAliases["eventEditors"])
+	}
+	if groupAliases["staff"] != "urn:staff group" {
+		t.Errorf("Staff group is wrong %s", groupAliases["staff"])
+	}
+	if groupAliases["students"] != "urn:students" {
+		t.Errorf("Students group is wrong %s", groupAliases["students"])
+	}
+}
+
+func TestGetPrivacyDataCategories(t *testing.T) {
+	t.Setenv("TCH_PRIVACY_DATA_CATEGORIES", "location=3; personal_info = 4")
+
+	privacyDataCategories := getPrivacyDataCategories()
+	if len(privacyDataCategories) != 2 || privacyDataCategories["location"] != 3 || privacyDataCategories["personal_info"] != 4 {
+		t.Errorf("Wrong privacy data categories %v", privacyDataCategories)
+	}
+}
+
+func TestGetTrustedProxies(t *testing.T) {
+	t.Setenv("TCH_TRUSTED_PROXIES", "10.0.0.0/8, 2001:db8::1")
+
+	trustedProxies := getTrustedProxies()
+	if len(trustedProxies) != 2 || trustedProxies[0].String() != "10.0.0.0/8" || trustedProxies[1].String() != "2001:db8::1/128" {
+		t.Errorf("Wrong trusted proxies %v", trustedProxies)
+	}
+}
+
+func TestGetLocaleFallbacks(t *testing.T) {
+	t.Setenv("TCH_LOCALE_FALLBACKS", "zh_HK=zh-Hant; zh-mo = zh-hant")
+
+	localeFallbacks := getLocaleFallbacks()
+	if len(localeFallbacks) != 2 || localeFallbacks["zh-hk"] != "zh-hant" || localeFallbacks["zh-mo"] != "zh-hant" {
+		t.Errorf("Wrong locale fallbacks %v", localeFallbacks)
+	}
+}
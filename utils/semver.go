@@ -0,0 +1,239 @@
+/*
+ *   Copyright (c) 2020 Board of Trustees of the University of Illinois.
+ *   All rights reserved.
+
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+
+ *   http://www.apache.org/licenses/LICENSE-2.0
+
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//SemVersion represents a semantic version
+type SemVersion struct {
+	Major      int
+	Minor      int
+	Patch      int
+	PreRelease []string
+}
+
+//ParseSemVersion parses a semantic version. A leading "v" is allowed, missing minor and patch parts are zeros
+//and the build metadata is ignored.
+func ParseSemVersion(value string) (*SemVersion, error) {
+	text := strings.TrimPrefix(strings.TrimSpace(value), "v")
+	if index := strings.Index(text, "+"); index >= 0 {
+		text = text[:index]
+	}
+	var preRelease []string
+	if index := strings.Index(text, "-"); index >= 0 {
+		preRelease = strings.Split(text[index+1:], ".")
+		for _, item := range preRelease {
+			if len(item) == 0 {
+				return nil, fmt.Errorf("%s is not a valid version", value)
+			}
+		}
+		text = text[:index]
+	}
+
+	parts := strings.Split(text, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return nil, fmt.Errorf("%s is not a valid version", value)
+	}
+	numbers := make([]int, 3)
+	for index, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil || number < 0 {
+			return nil, fmt.Errorf("%s is not a valid version", value)
+		}
+		numbers[index] = number
+	}
+	return &SemVersion{Major: numbers[0], Minor: numbers[1], Patch: numbers[2], PreRelease: preRelease}, nil
+}
+
+//Compare gives -1, 0 or 1 if the version is less than, equal to or greater than the other one
+func (v SemVersion) Compare(other SemVersion) int {
+	if result := compareInts(v.Major, other.Major); result != 0 {
+		return result
+	}
+	if result := compareInts(v.Minor, other.Minor); result != 0 {
+		return result
+	}
+	if result := compareInts(v.Patch, other.Patch); result != 0 {
+		return result
+	}
+
+	//a pre-release version has lower precedence than the normal version
+	if len(v.PreRelease) == 0 || len(other.PreRelease) == 0 {
+		return compareInts(len(other.PreRelease), len(v.PreRelease))
+	}
+	for index := 0; index < len(v.PreRelease) && index < len(other.PreRelease); index++ {
+		current := v.PreRelease[index]
+		otherCurrent := other.PreRelease[index]
+		if current == otherCurrent {
+			continue
+		}
+		number, err := strconv.Atoi(current)
+		otherNumber, otherErr := strconv.Atoi(otherCurrent)
+		switch {
+		case err == nil && otherErr == nil:
+			return compareInts(number, otherNumber)
+		case err == nil:
+			return -1 //numeric identifiers have lower precedence
+		case otherErr == nil:
+			return 1
+		default:
+			return strings.Compare(current, otherCurrent)
+		}
+	}
+	return compareInts(len(v.PreRelease), len(other.PreRelease))
+}
+
+func (v SemVersion) String() string {
+	result := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.PreRelease) > 0 {
+		result = fmt.Sprintf("%s-%s", result, strings.Join(v.PreRelease, "."))
+	}
+	return result
+}
+
+//SemConstraint represents a semantic version constraint like ">=3.1.0 <4 || ^5.0.0"
+type SemConstraint struct {
+	alternatives [][]semComparator
+}
+
+type semComparator struct {
+	operator string
+	version  SemVersion
+}
+
+//ParseSemConstraint parses a semantic version constraint. The comparators separated by spaces must all match,
+//the groups separated by "||" are alternatives. The supported operators are =, !=, >, >=, <, <=, ~ and ^.
+func ParseSemConstraint(value string) (*SemConstraint, error) {
+	if len(strings.TrimSpace(value)) == 0 {
+		return nil, errors.New("the constraint cannot be empty")
+	}
+
+	var alternatives [][]semComparator
+	for _, group := range strings.Split(value, "||") {
+		tokens := strings.Fields(group)
+		if len(tokens) == 0 {
+			return nil, fmt.Errorf("%s has an empty alternative", value)
+		}
+
+		var comparators []semComparator
+		for index := 0; index < len(tokens); index++ {
+			token := tokens[index]
+			//allow a space between the operator and the version - ">= 3.1.0"
+			if isSemOperator(token) && index+1 < len(tokens) {
+				index++
+				token = token + tokens[index]
+			}
+			items, err := parseSemComparator(token)
+			if err != nil {
+				return nil, err
+			}
+			comparators = append(comparators, items...)
+		}
+		alternatives = append(alternatives, comparators)
+	}
+	return &SemConstraint{alternatives: alternatives}, nil
+}
+
+//Check checks if the version satisfies the constraint
+func (c SemConstraint) Check(version SemVersion) bool {
+	for _, comparators := range c.alternatives {
+		matches := true
+		for _, comparator := range comparators {
+			if !comparator.check(version) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+func (c semComparator) check(version SemVersion) bool {
+	result := version.Compare(c.version)
+	switch c.operator {
+	case "!=":
+		return result != 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	default:
+		return result == 0
+	}
+}
+
+func parseSemComparator(token string) ([]semComparator, error) {
+	operator := ""
+	for _, item := range []string{">=", "<=", "!=", "==", ">", "<", "=", "~", "^"} {
+		if strings.HasPrefix(token, item) {
+			operator = item
+			break
+		}
+	}
+	version, err := ParseSemVersion(token[len(operator):])
+	if err != nil {
+		return nil, err
+	}
+
+	switch operator {
+	case "~":
+		//~3.1.2 is >=3.1.2 <3.2.0
+		upper := SemVersion{Major: version.Major, Minor: version.Minor + 1}
+		return []semComparator{{operator: ">=", version: *version}, {operator: "<", version: upper}}, nil
+	case "^":
+		//^3.1.2 is >=3.1.2 <4.0.0, ^0.3.1 is >=0.3.1 <0.4.0
+		upper := SemVersion{Major: version.Major + 1}
+		if version.Major == 0 {
+			upper = SemVersion{Minor: version.Minor + 1}
+		}
+		return []semComparator{{operator: ">=", version: *version}, {operator: "<", version: upper}}, nil
+	case "", "==":
+		operator = "="
+	}
+	return []semComparator{{operator: operator, version: *version}}, nil
+}
+
+func isSemOperator(value string) bool {
+	switch value {
+	case ">=", "<=", "!=", "==", ">", "<", "=", "~", "^":
+		return true
+	}
+	return false
+}
+
+func compareInts(a int, b int) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}